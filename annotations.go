@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// symbolAnnotations returns the Java annotations, Python decorators and TS decorators
+// attached to a declaration, written as they appear in source without arguments,
+// e.g. "@RestController" or "@app.route"
+func symbolAnnotations(node *sitter.Node, content []byte) []string {
+	var markers []*sitter.Node
+
+	// Java keeps annotations in the declaration's modifiers
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		switch child.Type() {
+		case "modifiers":
+			for j := 0; j < int(child.ChildCount()); j++ {
+				switch child.Child(j).Type() {
+				case "annotation", "marker_annotation":
+					markers = append(markers, child.Child(j))
+				}
+			}
+		case "decorator":
+			// TS decorators on classes and fields, Python decorators on a decorated_definition
+			markers = append(markers, child)
+		}
+	}
+
+	if parent := node.Parent(); parent != nil {
+		switch parent.Type() {
+		case "decorated_definition", "export_statement":
+			// Python decorators and TS decorators before "export class"
+			for i := 0; i < int(parent.ChildCount()); i++ {
+				if child := parent.Child(i); child.Type() == "decorator" {
+					markers = append(markers, child)
+				}
+			}
+		case "class_body":
+			// TS method decorators are siblings preceding the method
+			for s := node.PrevSibling(); s != nil && s.Type() == "decorator"; s = s.PrevSibling() {
+				markers = append([]*sitter.Node{s}, markers...)
+			}
+		}
+	}
+
+	var annotations []string
+	for _, marker := range markers {
+		if name := annotationName(marker, content); name != "" {
+			annotations = append(annotations, "@"+name)
+		}
+	}
+	return annotations
+}
+
+// annotationName returns the name of an annotation or decorator without its arguments
+func annotationName(node *sitter.Node, content []byte) string {
+	if name := node.ChildByFieldName("name"); name != nil {
+		return string(content[name.StartByte():name.EndByte()])
+	}
+
+	// Decorators wrap an expression, which is a call when arguments are given
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		expr := node.NamedChild(i)
+		if expr.Type() == "call" || expr.Type() == "call_expression" {
+			if fn := expr.ChildByFieldName("function"); fn != nil {
+				expr = fn
+			}
+		}
+		if expr.Type() == "comment" {
+			continue
+		}
+		return string(content[expr.StartByte():expr.EndByte()])
+	}
+	return ""
+}
+
+// hasAnnotation reports whether a symbol carries the given annotation or decorator.
+// The leading "@" is optional, and a simple name also matches a qualified one,
+// so "Test" matches "@org.junit.Test".
+func hasAnnotation(sym Symbol, annotation string) bool {
+	annotation = strings.TrimPrefix(annotation, "@")
+	for _, a := range sym.Annotations {
+		a = strings.TrimPrefix(a, "@")
+		if a == annotation || strings.HasSuffix(a, "."+annotation) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSymbolAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		source   string
+		expected map[string][]string
+	}{
+		{
+			name:     "java",
+			fileName: "Controller.java",
+			source: `@RestController
+@RequestMapping("/api")
+public class Controller {
+    @GetMapping("/users")
+    public String users() { return ""; }
+
+    @org.junit.Test
+    void check() {}
+
+    void plain() {}
+}
+`,
+			expected: map[string][]string{
+				"Controller": {"@RestController", "@RequestMapping"},
+				"users":      {"@GetMapping"},
+				"check":      {"@org.junit.Test"},
+				"plain":      nil,
+			},
+		},
+		{
+			name:     "python",
+			fileName: "views.py",
+			source: `@app.route("/users")
+@login_required
+def users():
+    pass
+
+class Model:
+    @property
+    def name(self):
+        pass
+
+def plain():
+    pass
+`,
+			expected: map[string][]string{
+				"users": {"@app.route", "@login_required"},
+				"name":  {"@property"},
+				"plain": nil,
+			},
+		},
+		{
+			name:     "typescript",
+			fileName: "component.ts",
+			source: `@Component({ selector: 'app' })
+export class AppComponent {
+    @HostListener('click')
+    onClick() {}
+
+    render() {}
+}
+
+@Injectable()
+class Service {}
+`,
+			expected: map[string][]string{
+				"AppComponent": {"@Component"},
+				"onClick":      {"@HostListener"},
+				"render":       nil,
+				"Service":      {"@Injectable"},
+			},
+		},
+	}
+
+	extractor := NewSymbolExtractor()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(testFile, []byte(tt.source), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			symbols, err := extractor.ExtractFromFile(testFile, Minimal)
+			if err != nil {
+				t.Fatalf("ExtractFromFile error = %v", err)
+			}
+
+			found := make(map[string]bool)
+			for _, sym := range symbols {
+				expected, ok := tt.expected[sym.Name]
+				if !ok {
+					continue
+				}
+				found[sym.Name] = true
+				if !reflect.DeepEqual(sym.Annotations, expected) {
+					t.Errorf("%s %s: Annotations = %v, want %v", sym.Kind, sym.Name, sym.Annotations, expected)
+				}
+			}
+
+			for name := range tt.expected {
+				if !found[name] {
+					t.Errorf("symbol %s not extracted", name)
+				}
+			}
+		})
+	}
+}
+
+func TestHasAnnotation(t *testing.T) {
+	sym := Symbol{Name: "check", Annotations: []string{"@org.junit.Test", "@app.route"}}
+
+	tests := []struct {
+		annotation string
+		expected   bool
+	}{
+		{"@org.junit.Test", true},
+		{"Test", true},
+		{"@Test", true},
+		{"app.route", true},
+		{"route", true},
+		{"@junit", false},
+		{"@Override", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasAnnotation(sym, tt.annotation); got != tt.expected {
+			t.Errorf("hasAnnotation(%q) = %v, want %v", tt.annotation, got, tt.expected)
+		}
+	}
+}
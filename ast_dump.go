@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// maxASTLeafText is how many characters of a leaf node's text an AST dump shows
+const maxASTLeafText = 40
+
+// DumpAST parses a file and writes its syntax tree as an S-expression of named
+// nodes, the form Tree-sitter queries match, for writing custom queries and
+// finding out why a symbol is not extracted. Each node shows its field name and
+// its 1-based start and end line:column, and leaves their text. Nodes more than
+// depth levels deep, counting the root as 1, are elided with … when depth is
+// positive, and only nodes intersecting lines are shown when it is set.
+func (e *SymbolExtractor) DumpAST(filePath string, depth int, lines LineRange) (string, error) {
+	if IsNotebookFile(filePath) {
+		return "", fmt.Errorf("notebooks have no single syntax tree: %s", filePath)
+	}
+	tree, content, langQueries, err := e.parseFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer tree.Close()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Syntax tree of %s (%s)\n\n```\n", filePath, langQueries.Name))
+	writeASTNode(&sb, tree.RootNode(), content, "", 0, 1, depth, lines)
+	sb.WriteString("\n```\n")
+	return sb.String(), nil
+}
+
+// writeASTNode writes a node and its named children, indented by level
+func writeASTNode(sb *strings.Builder, node *sitter.Node, content []byte, field string, level, nodeDepth, maxDepth int, lines LineRange) {
+	sb.WriteString(strings.Repeat("  ", level))
+	if field != "" {
+		sb.WriteString(field + ": ")
+	}
+	sb.WriteString("(")
+	if node.IsMissing() {
+		sb.WriteString("MISSING ")
+	}
+	start, end := node.StartPoint(), node.EndPoint()
+	sb.WriteString(fmt.Sprintf("%s [%d:%d-%d:%d]", node.Type(),
+		start.Row+1, runeColumn(content, node.StartByte(), start.Column),
+		end.Row+1, runeColumn(content, node.EndByte(), end.Column)))
+
+	type namedChild struct {
+		node  *sitter.Node
+		field string
+	}
+	var children []namedChild
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil || !child.IsNamed() {
+			continue
+		}
+		if !lines.Intersects(Symbol{StartLine: child.StartPoint().Row + 1, EndLine: child.EndPoint().Row + 1}) {
+			continue
+		}
+		children = append(children, namedChild{child, node.FieldNameForChild(i)})
+	}
+
+	switch {
+	case node.NamedChildCount() == 0:
+		if text := node.Content(content); text != "" {
+			sb.WriteString(" " + strconv.Quote(truncateRunes(text, maxASTLeafText)))
+		}
+	case maxDepth > 0 && nodeDepth >= maxDepth:
+		sb.WriteString(" …")
+	default:
+		for _, child := range children {
+			sb.WriteString("\n")
+			writeASTNode(sb, child.node, content, child.field, level+1, nodeDepth+1, maxDepth, lines)
+		}
+	}
+	sb.WriteString(")")
+}
+
+// truncateRunes shortens text to at most n runes, marking a cut with …
+func truncateRunes(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// writeASTFile writes a small Go file to dump
+func writeASTFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "run.go")
+	code := "package main\n\n// Run starts\nfunc Run(name string) error {\n\treturn nil\n}\n"
+	if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDumpAST(t *testing.T) {
+	path := writeASTFile(t)
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	tests := []struct {
+		name    string
+		depth   int
+		lines   LineRange
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "whole tree",
+			want:    []string{"(source_file [1:1-7:1]", "    name: (identifier [4:6-4:9] \"Run\")", "type: (type_identifier [4:15-4:21] \"string\")))", "(nil [5:9-5:12] \"nil\")"},
+			notWant: []string{"…"},
+		},
+		{
+			name:    "depth limited",
+			depth:   2,
+			want:    []string{"  (function_declaration [4:1-6:2] …)", "(package_clause [1:1-1:13] …)"},
+			notWant: []string{"identifier"},
+		},
+		{
+			name:    "line range",
+			lines:   LineRange{Start: 5, End: 5},
+			want:    []string{"(function_declaration [4:1-6:2]", "(return_statement [5:2-5:12]"},
+			notWant: []string{"package_clause", "parameters:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := extractor.DumpAST(path, tt.depth, tt.lines)
+			if err != nil {
+				t.Fatalf("DumpAST error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected %q in:\n%s", want, output)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(output, notWant) {
+					t.Errorf("Expected no %q in:\n%s", notWant, output)
+				}
+			}
+		})
+	}
+
+	if _, err := extractor.DumpAST(filepath.Join(filepath.Dir(path), "data.bin"), 0, LineRange{}); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestToolHandlers_ASTDump(t *testing.T) {
+	path := writeASTFile(t)
+
+	handlers := &toolHandlers{}
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		var request mcp.CallToolRequest
+		request.Params.Arguments = args
+		result, err := handlers.astDump(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{"path": path, "depth": 3})
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.HasPrefix(text, "# Syntax tree of "+path+" (go)") || !strings.Contains(text, "body: (block [4:29-6:2] …)") {
+		t.Errorf("expected a depth-limited syntax tree, got %q", text)
+	}
+
+	for _, args := range []map[string]any{
+		{"path": "relative.go"},
+		{"path": filepath.Dir(path)},
+		{"path": path, "depth": -1},
+		{"path": path, "start_line": 5, "end_line": 4},
+	} {
+		if result := call(args); !result.IsError {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+
+	handlers.limits = ExtractLimits{MaxBytes: 10}
+	if result := call(map[string]any{"path": path}); !result.IsError || result.Meta["status"] != StatusLimitExceeded {
+		t.Errorf("expected a limit error for a file over max_bytes, got %+v", result)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBashSymbolExtraction(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/sh_basic.sh.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	expected := map[string]string{
+		"func build":     "function build",
+		"func deploy":    "deploy()",
+		"func cleanup":   "cleanup()",
+		"var DEPLOY_ENV": "DEPLOY_ENV",
+		"var PATH":       "PATH",
+		"var REGION":     "REGION",
+	}
+
+	found := make(map[string]string)
+	for _, sym := range symbols {
+		found[sym.Kind+" "+sym.Name] = sym.Signature
+	}
+	for key, signature := range expected {
+		if got, ok := found[key]; !ok {
+			t.Errorf("symbol %s not extracted", key)
+		} else if got != signature {
+			t.Errorf("%s: Signature = %q, want %q", key, got, signature)
+		}
+	}
+
+	// Variables that are not exported, and locals, are not part of the outline
+	for _, key := range []string{"var LOCAL_ONLY", "var VERSION", "var target"} {
+		if _, ok := found[key]; ok {
+			t.Errorf("unexpected symbol %s", key)
+		}
+	}
+}
+
+func TestShebangLanguageDetection(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *LanguageQueries
+	}{
+		{"bash", "#!/bin/bash\necho hi\n", bashLanguageQueries},
+		{"env bash", "#!/usr/bin/env bash\n", bashLanguageQueries},
+		{"env with options", "#!/usr/bin/env -S bash -e\n", bashLanguageQueries},
+		{"sh", "#!/bin/sh\n", bashLanguageQueries},
+		{"python", "#!/usr/bin/env python3\n", nil},
+		{"no shebang", "echo hi\n", nil},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "script")
+			if err := os.WriteFile(path, []byte(tt.content), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if got := GetLanguageQueriesForFile(path); got != tt.expected {
+				t.Errorf("GetLanguageQueriesForFile(%q) = %v, want %v", tt.content, got, tt.expected)
+			}
+		})
+	}
+}
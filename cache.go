@@ -0,0 +1,220 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultCacheFiles is how many files' symbols the MCP server caches by default
+const defaultCacheFiles = 5000
+
+// SymbolCache holds the symbols last extracted from files, so extractions that
+// run at once or one after another, such as the tool calls of parallel agents in
+// the same repository, parse each unchanged file once. A file's symbols are used
+// only while its content hashes the same as when they were stored; the least
+// recently used files are evicted beyond the cache's size. It is safe for
+// concurrent use.
+//
+// Hashing every matched file of a large repository on each call takes longer than
+// the lookups it saves, so a file whose size and modification time are still those
+// it was read with is taken as unchanged without hashing it. Like git's index, a
+// modification time too close to when the file was read can't tell a later write
+// of the same size apart, and the hash decides for such files.
+type SymbolCache struct {
+	maxFiles int
+
+	mu      sync.Mutex
+	entries map[symbolCacheKey]*list.Element
+	// lru orders the entries from most to least recently used
+	lru *list.List
+}
+
+// symbolCacheKey identifies the symbols of a file at one detail level
+type symbolCacheKey struct {
+	path   string
+	detail DetailLevel
+}
+
+// racyWindow is how much older than the read a file's symbols were cached from its
+// modification time must be for the file to be taken as unchanged while it keeps
+// it, allowing for file systems that record modification times to the second
+const racyWindow = time.Second
+
+// fileStamp is the size and modification time of a file as of a read started at readAt
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+	readAt  time.Time
+}
+
+// stampOf returns the stamp of a file's info from a read started at readAt
+func stampOf(info os.FileInfo, readAt time.Time) fileStamp {
+	return fileStamp{size: info.Size(), modTime: info.ModTime(), readAt: readAt}
+}
+
+// symbolCacheEntry is the symbols of a file with the hash and stamp of the content
+// they were extracted from
+type symbolCacheEntry struct {
+	key         symbolCacheKey
+	hash        string
+	stamp       fileStamp
+	symbols     []Symbol
+	parseErrors []LineRange
+}
+
+// unchanged reports whether a file read with stamp is certainly the one the entry
+// was stored for without comparing their content
+func (e *symbolCacheEntry) unchanged(stamp fileStamp) bool {
+	return !stamp.modTime.IsZero() && stamp.size == e.stamp.size && stamp.modTime.Equal(e.stamp.modTime) &&
+		e.stamp.modTime.Before(e.stamp.readAt.Add(-racyWindow))
+}
+
+// NewSymbolCache creates a cache of the symbols of at most maxFiles files
+func NewSymbolCache(maxFiles int) *SymbolCache {
+	return &SymbolCache{
+		maxFiles: maxFiles,
+		entries:  make(map[symbolCacheKey]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Lookup returns the symbols and syntax error lines stored for a file at a detail
+// level if they were extracted from the same content. The file's content is only
+// hashed when its stamp doesn't show it unchanged.
+func (c *SymbolCache) Lookup(filePath string, detail DetailLevel, content []byte, stamp fileStamp) ([]Symbol, []LineRange, bool) {
+	key := symbolCacheKey{filePath, detail}
+	c.mu.Lock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, nil, false
+	}
+	entry := elem.Value.(*symbolCacheEntry)
+	if !entry.unchanged(stamp) {
+		// Hash outside the lock so other calls aren't held up by large files
+		c.mu.Unlock()
+		hash := hashContent(content)
+		c.mu.Lock()
+		if elem, ok = c.entries[key]; !ok {
+			c.mu.Unlock()
+			return nil, nil, false
+		}
+		entry = elem.Value.(*symbolCacheEntry)
+		if entry.hash != hash {
+			c.mu.Unlock()
+			return nil, nil, false
+		}
+		// The file was only touched, so its new stamp can be relied on next time
+		entry.stamp = stamp
+	}
+	defer c.mu.Unlock()
+	c.lru.MoveToFront(elem)
+
+	// Callers filter and annotate the symbols they get, so each gets its own copy
+	symbols := make([]Symbol, len(entry.symbols))
+	copy(symbols, entry.symbols)
+	return symbols, entry.parseErrors, true
+}
+
+// Store records the symbols and syntax error lines extracted from a file's content,
+// read with stamp
+func (c *SymbolCache) Store(filePath string, detail DetailLevel, content []byte, stamp fileStamp, symbols []Symbol, parseErrors []LineRange) {
+	key := symbolCacheKey{filePath, detail}
+	entry := &symbolCacheEntry{
+		key:         key,
+		hash:        hashContent(content),
+		stamp:       stamp,
+		symbols:     make([]Symbol, len(symbols)),
+		parseErrors: parseErrors,
+	}
+	copy(entry.symbols, symbols)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxFiles {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*symbolCacheEntry).key)
+	}
+}
+
+// Len returns the number of files whose symbols are cached
+func (c *SymbolCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// extractFile extracts the symbols of a file like extractor.ExtractFromFile, taking
+// them from opts.Cache instead when the file is unchanged since they were stored
+// there. Either way the extractor's content and syntax errors are those of the
+// file, and the syntax errors and cache hits are logged to opts.Log.
+func extractFile(extractor *SymbolExtractor, opts ExtractOptions, filePath string, detail DetailLevel) ([]Symbol, error) {
+	extractor.parseErrors = nil
+	readAt := time.Now()
+	content, info, err := readStableFile(filePath, os.ReadFile)
+	if err != nil {
+		return nil, err
+	}
+	return extractFileContent(extractor, opts, filePath, content, stampOf(info, readAt), detail)
+}
+
+// extractFileContent is extractFile for content already read from filePath with stamp
+func extractFileContent(extractor *SymbolExtractor, opts ExtractOptions, filePath string, content []byte, stamp fileStamp, detail DetailLevel) ([]Symbol, error) {
+	extractor.parseErrors = nil
+	// A notebook's symbols are those of its cells rather than of what is read from
+	// it, so they are not cached, and neither are the matches of a custom query
+	if IsNotebookFile(filePath) {
+		symbols, err := extractor.extractNotebookContent(filePath, content, detail)
+		if err == nil {
+			logParseErrors(opts.Log, filePath, extractor.parseErrors)
+		}
+		return symbols, err
+	}
+	langQueries := GetLanguageQueriesForFile(filePath)
+	if langQueries == nil {
+		extractor.content = content
+		return nil, fmt.Errorf("unsupported file type: %s", filePath)
+	}
+	if extractor.query != "" {
+		opts.Cache = nil
+	}
+	return extractCached(extractor, opts, filePath, content, stamp, detail, func() ([]Symbol, error) {
+		return extractor.ExtractFromContent(filePath, content, langQueries, detail)
+	})
+}
+
+// extractCached returns the symbols of content, read from filePath with stamp, from
+// opts.Cache when it holds them, or else extracts them with extract and stores them
+// there. The syntax errors and cache hits are logged to opts.Log.
+func extractCached(extractor *SymbolExtractor, opts ExtractOptions, filePath string, content []byte, stamp fileStamp, detail DetailLevel, extract func() ([]Symbol, error)) ([]Symbol, error) {
+	if opts.Cache != nil {
+		if symbols, parseErrors, ok := opts.Cache.Lookup(filePath, detail, content, stamp); ok {
+			extractor.content, extractor.parseErrors = content, parseErrors
+			if opts.Log != nil {
+				opts.Log(LogEvent{Level: LogDebug, Event: LogCacheHit, File: filePath, Message: "unchanged since its symbols were cached"})
+			}
+			logParseErrors(opts.Log, filePath, parseErrors)
+			return symbols, nil
+		}
+	}
+
+	symbols, err := extract()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Cache != nil {
+		opts.Cache.Store(filePath, detail, content, stamp, symbols, extractor.parseErrors)
+	}
+	logParseErrors(opts.Log, filePath, extractor.parseErrors)
+	return symbols, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSymbolCache(t *testing.T) {
+	cache := NewSymbolCache(2)
+	content := []byte("package app\n")
+	symbols := []Symbol{{Name: "app", Kind: "package"}}
+
+	cache.Store("a.go", Standard, content, fileStamp{}, symbols, nil)
+	got, _, ok := cache.Lookup("a.go", Standard, content, fileStamp{})
+	if !ok || len(got) != 1 || got[0].Name != "app" {
+		t.Fatalf("Lookup = %v, %v, want the stored symbols", got, ok)
+	}
+	got[0].Name = "changed"
+	if again, _, _ := cache.Lookup("a.go", Standard, content, fileStamp{}); again[0].Name != "app" {
+		t.Error("Expected callers' changes not to reach the cache")
+	}
+
+	if _, _, ok := cache.Lookup("a.go", Standard, []byte("package other\n"), fileStamp{}); ok {
+		t.Error("Expected a miss for changed content")
+	}
+	if _, _, ok := cache.Lookup("a.go", Full, content, fileStamp{}); ok {
+		t.Error("Expected a miss for another detail level")
+	}
+
+	// The least recently used file is evicted
+	cache.Store("b.go", Standard, content, fileStamp{}, symbols, nil)
+	cache.Lookup("a.go", Standard, content, fileStamp{})
+	cache.Store("c.go", Standard, content, fileStamp{}, symbols, nil)
+	if _, _, ok := cache.Lookup("b.go", Standard, content, fileStamp{}); ok {
+		t.Error("Expected b.go to be evicted")
+	}
+	if _, _, ok := cache.Lookup("a.go", Standard, content, fileStamp{}); !ok || cache.Len() != 2 {
+		t.Errorf("Expected a.go and c.go to stay cached, got %d files", cache.Len())
+	}
+}
+
+func TestSymbolCache_Stamp(t *testing.T) {
+	readAt := time.Now()
+	old := fileStamp{size: 12, modTime: readAt.Add(-time.Hour), readAt: readAt}
+	content := []byte("package app\n")
+	changed := []byte("package bpp\n")
+
+	tests := []struct {
+		name    string
+		stored  fileStamp
+		lookup  fileStamp
+		content []byte
+		want    bool
+	}{
+		// The stamp shows the file unchanged, so its content isn't compared
+		{"unchanged stamp", old, old, changed, true},
+		{"changed size", old, fileStamp{size: 13, modTime: old.modTime, readAt: readAt}, content, true},
+		{"changed size and content", old, fileStamp{size: 13, modTime: old.modTime, readAt: readAt}, changed, false},
+		{"touched", old, fileStamp{size: 12, modTime: readAt, readAt: readAt}, content, true},
+		{"touched and changed", old, fileStamp{size: 12, modTime: readAt, readAt: readAt}, changed, false},
+		// Written just before the read, a same-size write after it could keep the stamp
+		{"racy stamp", fileStamp{size: 12, modTime: readAt, readAt: readAt}, fileStamp{size: 12, modTime: readAt, readAt: readAt}, changed, false},
+		{"racy stamp, same content", fileStamp{size: 12, modTime: readAt, readAt: readAt}, fileStamp{size: 12, modTime: readAt, readAt: readAt}, content, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewSymbolCache(1)
+			cache.Store("a.go", Standard, content, tt.stored, []Symbol{{Name: "app", Kind: "package"}}, nil)
+			if _, _, ok := cache.Lookup("a.go", Standard, tt.content, tt.lookup); ok != tt.want {
+				t.Errorf("Lookup hit = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+
+	// Once a touched file's content is found unchanged, its new stamp is relied on
+	cache := NewSymbolCache(1)
+	cache.Store("a.go", Standard, content, old, nil, nil)
+	touched := fileStamp{size: 12, modTime: readAt.Add(-time.Minute), readAt: readAt}
+	cache.Lookup("a.go", Standard, content, touched)
+	if _, _, ok := cache.Lookup("a.go", Standard, changed, touched); !ok {
+		t.Error("Expected the refreshed stamp to show the file unchanged")
+	}
+}
+
+func TestExtractFile_Cache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc Run() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewSymbolCache(10)
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	if _, err := extractFile(extractor, ExtractOptions{Cache: cache}, path, Standard); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	cached, _, ok := cache.Lookup(path, Standard, content, fileStamp{})
+	if !ok {
+		t.Fatal("Expected the extracted symbols to be cached")
+	}
+
+	// An unchanged file is not parsed again
+	cache.Store(path, Standard, content, fileStamp{}, append(cached, Symbol{Name: "FromCache", Kind: "function"}), nil)
+	symbols, err := extractFile(extractor, ExtractOptions{Cache: cache}, path, Standard)
+	if err != nil || !hasSymbolNamed(symbols, "FromCache") || string(extractor.content) != string(content) {
+		t.Errorf("extractFile = %v, %v, want the cached symbols", symbols, err)
+	}
+
+	if err := os.WriteFile(path, []byte("package main\n\nfunc Stop() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	symbols, err = extractFile(extractor, ExtractOptions{Cache: cache}, path, Standard)
+	if err != nil || !hasSymbolNamed(symbols, "Stop") || hasSymbolNamed(symbols, "Run") {
+		t.Errorf("extractFile after a change = %v, %v, want the new symbols", symbols, err)
+	}
+}
+
+// hasSymbolNamed reports whether symbols include one with the given name
+func hasSymbolNamed(symbols []Symbol, name string) bool {
+	for _, sym := range symbols {
+		if sym.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestToolHandlers_ConcurrentCalls(t *testing.T) {
+	testDir := t.TempDir()
+	for i := 0; i < 8; i++ {
+		code := fmt.Sprintf("package app\n\nfunc Handler%d() {}\n", i)
+		if err := os.WriteFile(filepath.Join(testDir, fmt.Sprintf("h%d.go", i)), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handlers := &toolHandlers{cache: NewSymbolCache(100)}
+	calls := []func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		handlers.extractSymbols, handlers.repoMap,
+	}
+	var wg sync.WaitGroup
+	errs := make(chan string, 32)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(call func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)) {
+			defer wg.Done()
+			var request mcp.CallToolRequest
+			request.Params.Arguments = map[string]any{"pattern": filepath.Join(testDir, "*.go")}
+			result, err := call(context.Background(), request)
+			if err != nil {
+				errs <- err.Error()
+				return
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; result.IsError || !strings.Contains(text, "Handler7") {
+				errs <- text
+			}
+		}(calls[i%len(calls)])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent call failed: %s", err)
+	}
+	if handlers.cache.Len() != 8 {
+		t.Errorf("Expected the 8 files to be cached once each, got %d", handlers.cache.Len())
+	}
+}
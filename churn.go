@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// churnKinds are the symbol kinds reported as hotspots; variables, constants
+// and fields are left out since most of them are locals or tiny declarations
+var churnKinds = map[string]bool{
+	"func":        true,
+	"method":      true,
+	"constructor": true,
+	"class":       true,
+	"struct":      true,
+	"interface":   true,
+	"type":        true,
+	"enum":        true,
+	"record":      true,
+	"annotation":  true,
+}
+
+// SymbolChurn holds the change history of a symbol
+type SymbolChurn struct {
+	Symbol       Symbol
+	Commits      int
+	LastModified time.Time
+	LastAuthor   string
+}
+
+// symbolChurn counts the commits that touched the lines of a symbol using git log -L
+func symbolChurn(sym Symbol, since string) (SymbolChurn, error) {
+	args := []string{
+		"-C", filepath.Dir(sym.FilePath),
+		"log", "--no-color", "-s", "--format=%H%x09%at%x09%an",
+		fmt.Sprintf("-L%d,%d:%s", sym.StartLine, sym.EndLine, filepath.Base(sym.FilePath)),
+	}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return SymbolChurn{}, fmt.Errorf("git log failed for %s: %s", sym.FilePath, strings.TrimSpace(stderr.String()))
+	}
+
+	churn := SymbolChurn{Symbol: sym}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || len(fields[0]) < 40 {
+			continue
+		}
+		churn.Commits++
+
+		// Commits are listed newest first
+		if churn.Commits == 1 {
+			if ts, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				churn.LastModified = time.Unix(ts, 0).UTC()
+			}
+			churn.LastAuthor = fields[2]
+		}
+	}
+
+	return churn, nil
+}
+
+// AnalyzeChurn reports the most frequently changed symbols in files matching a pattern,
+// found as opts.Discovery selects and parsed by opts.Jobs workers
+func AnalyzeChurn(pattern string, top int, since string, opts ExtractOptions) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("git is required for churn analysis: %w", err)
+	}
+
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
+	if err != nil {
+		return "", fmt.Errorf("failed to find files: %w", err)
+	}
+
+	if len(files) == 0 {
+		return "No files found matching pattern: " + pattern, nil
+	}
+
+	// Churn is measured for the functions, methods and types of the built-in queries
+	opts.Query = ""
+	outcomes, stop := extractConcurrently(context.Background(), files, opts, func(extractor *SymbolExtractor, file string) fileOutcome {
+		symbols, err := extractor.ExtractFromFile(file, Minimal)
+		return fileOutcome{symbols: symbols, err: err}
+	})
+	defer stop()
+
+	var churns []SymbolChurn
+	for i := range files {
+		outcome := <-outcomes[i]
+		if outcome.err != nil {
+			continue // Skip files that can't be parsed
+		}
+		symbols := outcome.symbols
+
+		seen := make(map[[2]uint32]bool)
+		for _, sym := range symbols {
+			// Go types are matched both as "type" and as "struct"/"interface"
+			span := [2]uint32{sym.StartLine, sym.EndLine}
+			if !churnKinds[sym.Kind] || seen[span] {
+				continue
+			}
+			seen[span] = true
+
+			churn, err := symbolChurn(sym, since)
+			if err != nil {
+				break // Skip files that aren't tracked by git
+			}
+			if churn.Commits > 0 {
+				churns = append(churns, churn)
+			}
+		}
+	}
+
+	if len(churns) == 0 {
+		return "No symbol history found", nil
+	}
+
+	return FormatChurn(churns, top), nil
+}
+
+// FormatChurn formats symbols ordered by change frequency, most changed first
+func FormatChurn(churns []SymbolChurn, top int) string {
+	sort.SliceStable(churns, func(i, j int) bool {
+		if churns[i].Commits != churns[j].Commits {
+			return churns[i].Commits > churns[j].Commits
+		}
+		return churns[i].LastModified.After(churns[j].LastModified)
+	})
+
+	var sb strings.Builder
+	sb.WriteString("# Symbol Hotspots\n\n")
+
+	shown := churns
+	if top > 0 && len(shown) > top {
+		shown = shown[:top]
+	}
+
+	for _, c := range shown {
+		commits := "commits"
+		if c.Commits == 1 {
+			commits = "commit"
+		}
+		sb.WriteString(fmt.Sprintf("- %d %s, last changed %s by %s: %s: %s (%s:%d)\n",
+			c.Commits, commits, c.LastModified.Format("2006-01-02"), c.LastAuthor,
+			c.Symbol.Kind, c.Symbol.Name, c.Symbol.FilePath, c.Symbol.StartLine))
+	}
+
+	if len(shown) < len(churns) {
+		sb.WriteString(fmt.Sprintf("\n%d more symbols not shown\n", len(churns)-len(shown)))
+	}
+
+	return sb.String()
+}
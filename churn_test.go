@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeChurn(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Ada", "GIT_AUTHOR_EMAIL=ada@example.com",
+			"GIT_COMMITTER_NAME=Ada", "GIT_COMMITTER_EMAIL=ada@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(code, message string) {
+		if err := os.WriteFile(filepath.Join(repo, "app.go"), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "app.go")
+		git("commit", "-q", "-m", message)
+	}
+
+	git("init", "-q")
+	commit("package app\n\nfunc Stable() {}\n\nfunc Hot() int {\n\treturn 1\n}\n", "initial")
+	commit("package app\n\nfunc Stable() {}\n\nfunc Hot() int {\n\treturn 2\n}\n", "tweak hot")
+	commit("package app\n\nfunc Stable() {}\n\nfunc Hot() int {\n\treturn 3\n}\n", "tweak hot again")
+
+	result, err := AnalyzeChurn(filepath.Join(repo, "*.go"), 0, "", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("AnalyzeChurn error = %v", err)
+	}
+
+	hot := strings.Index(result, "- 3 commits, last changed")
+	stable := strings.Index(result, "- 1 commit, last changed")
+	if hot < 0 || stable < 0 || hot > stable {
+		t.Errorf("Expected Hot (3 commits) to be listed before Stable (1 commit).\nResult:\n%s", result)
+	}
+	if !strings.Contains(result, "by Ada: func: Hot") {
+		t.Errorf("Expected last author of Hot.\nResult:\n%s", result)
+	}
+
+	result, err = AnalyzeChurn(filepath.Join(repo, "*.go"), 1, "", ExtractOptions{Jobs: 4})
+	if err != nil {
+		t.Fatalf("AnalyzeChurn error = %v", err)
+	}
+	if !strings.Contains(result, "1 more symbols not shown") {
+		t.Errorf("Expected top limit to hide one symbol.\nResult:\n%s", result)
+	}
+
+	// Files are found with the discovery options
+	result, err = AnalyzeChurn(filepath.Join(repo, "*.go"), 0, "", ExtractOptions{Discovery: DiscoveryOptions{Exclude: []string{"!app.go"}}})
+	if err != nil {
+		t.Fatalf("AnalyzeChurn error = %v", err)
+	}
+	if !strings.Contains(result, "No files found") {
+		t.Errorf("Expected app.go to be excluded.\nResult:\n%s", result)
+	}
+}
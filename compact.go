@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// formatCompact writes one dense line per symbol, "path:line kind name(params)",
+// with no Markdown scaffolding, to spend as few tokens as possible when the
+// outline is put in a model's context. Paths are relative to root and nested
+// symbols are qualified by the dotted names of those enclosing them.
+func formatCompact(symbols []Symbol, root string) string {
+	if len(symbols) == 0 {
+		return "No symbols found\n"
+	}
+
+	var files []string
+	fileSymbols := make(map[string][]Symbol)
+	for _, sym := range symbols {
+		if _, ok := fileSymbols[sym.FilePath]; !ok {
+			files = append(files, sym.FilePath)
+		}
+		fileSymbols[sym.FilePath] = append(fileSymbols[sym.FilePath], sym)
+	}
+
+	var sb strings.Builder
+	for _, file := range files {
+		path := file
+		if root != "" {
+			if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+		writeCompactNodes(&sb, BuildHierarchy(fileSymbols[file]), path, "")
+	}
+	return sb.String()
+}
+
+// writeCompactNodes writes the lines of a symbol hierarchy
+func writeCompactNodes(sb *strings.Builder, nodes []*SymbolNode, path, scope string) {
+	for _, node := range nodes {
+		sym := node.Symbol
+		qualified := sym.Name
+		if scope != "" {
+			qualified = scope + "." + sym.Name
+		} else if sym.Receiver != "" {
+			qualified = sym.Receiver + "." + sym.Name
+		}
+
+		line := fmt.Sprintf("%s:%d", path, sym.StartLine)
+		if sym.Cell != nil {
+			line = fmt.Sprintf("%s#%d:%d", path, sym.Cell.Index, sym.StartLine)
+		}
+		fmt.Fprintf(sb, "%s %s %s%s", line, sym.Kind, qualified, compactSignature(sym))
+		if sym.Deprecated {
+			sb.WriteString(" [deprecated]")
+		}
+		sb.WriteString("\n")
+
+		writeCompactNodes(sb, node.Children, path, qualified)
+	}
+}
+
+// compactSpacing drops the spaces left where a parameter list was split over lines
+var compactSpacing = strings.NewReplacer("( ", "(", ", )", ")", " )", ")")
+
+// compactSignature returns what a symbol's signature adds after its name, such
+// as the parameters and results of a function, on a single line. Keywords and
+// modifiers before the name are dropped, since the kind already conveys them.
+func compactSignature(sym Symbol) string {
+	signature := compactSpacing.Replace(strings.Join(strings.Fields(sym.Signature), " "))
+	i := strings.Index(signature, sym.Name)
+	if i < 0 {
+		return ""
+	}
+	rest := signature[i+len(sym.Name):]
+	if rest != "" && !strings.HasPrefix(rest, "(") && !strings.HasPrefix(rest, "[") && !strings.HasPrefix(rest, "<") {
+		rest = " " + strings.TrimSpace(rest)
+	}
+	return strings.TrimRight(rest, " ;")
+}
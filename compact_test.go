@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestFormatCompact(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Widget", Kind: "class", Signature: "export class Widget", StartLine: 3, EndLine: 20, StartColumn: 1, EndColumn: 2, FilePath: "/proj/src/widget.ts"},
+		{Name: "render", Kind: "method", Signature: "render(\n    props: Props,\n): void", StartLine: 4, EndLine: 10, StartColumn: 3, EndColumn: 4, FilePath: "/proj/src/widget.ts"},
+		{Name: "size", Kind: "field", StartLine: 12, EndLine: 12, StartColumn: 3, EndColumn: 10, FilePath: "/proj/src/widget.ts", Deprecated: true},
+		{Name: "Start", Kind: "method", Receiver: "Server", Signature: "func (s *Server) Start() error", StartLine: 7, EndLine: 9, StartColumn: 1, EndColumn: 2, FilePath: "/proj/server.go"},
+		{Name: "Port", Kind: "var", Signature: "Port int;", StartLine: 11, EndLine: 11, StartColumn: 1, EndColumn: 9, FilePath: "/proj/server.go"},
+		{Name: "Map", Kind: "type", Signature: "type Map[K comparable, V any] struct", StartLine: 13, EndLine: 15, StartColumn: 1, EndColumn: 2, FilePath: "/proj/server.go"},
+		{Name: "fit", Kind: "func", Signature: "def fit(model)", StartLine: 1, EndLine: 2, FilePath: "/proj/a.ipynb", Cell: &NotebookCell{Index: 2}},
+		{Name: "main", Kind: "func", Signature: "func main()", StartLine: 1, EndLine: 2, StartColumn: 1, EndColumn: 2, FilePath: "/elsewhere/main.go"},
+	}
+
+	want := "src/widget.ts:3 class Widget\n" +
+		"src/widget.ts:4 method Widget.render(props: Props): void\n" +
+		"src/widget.ts:12 field Widget.size [deprecated]\n" +
+		"server.go:7 method Server.Start() error\n" +
+		"server.go:11 var Port int\n" +
+		"server.go:13 type Map[K comparable, V any] struct\n" +
+		"a.ipynb#2:1 func fit(model)\n" +
+		"/elsewhere/main.go:1 func main()\n"
+	if got := formatCompact(symbols, "/proj"); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
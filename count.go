@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SymbolCounts totals the files and symbols matching a pattern
+type SymbolCounts struct {
+	Files int `json:"files"`
+	// Unsupported counts the matched files in languages glyph doesn't parse
+	Unsupported int              `json:"unsupported"`
+	Symbols     int              `json:"symbols"`
+	Languages   []*LanguageCount `json:"languages"`
+	Kinds       []*KindCount     `json:"kinds"`
+}
+
+// LanguageCount totals the files and symbols of one language
+type LanguageCount struct {
+	Language string       `json:"language"`
+	Files    int          `json:"files"`
+	Bytes    int64        `json:"bytes"`
+	Symbols  int          `json:"symbols"`
+	Kinds    []*KindCount `json:"kinds"`
+	kinds    map[string]int
+}
+
+// KindCount is the number of symbols of one kind
+type KindCount struct {
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+}
+
+// CountSymbolsContext counts the files matching a pattern and the symbols they
+// declare, nested ones included, by language and kind. Languages and kinds are
+// listed most symbols first, so totals can be compared between builds.
+func CountSymbolsContext(ctx context.Context, pattern string, opts ExtractOptions) (*SymbolCounts, []string, error) {
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find files: %w", err)
+	}
+
+	counts := &SymbolCounts{Files: len(files), Languages: []*LanguageCount{}, Kinds: []*KindCount{}}
+	if len(files) == 0 {
+		return counts, nil, nil
+	}
+
+	languages := make(map[string]*LanguageCount)
+	fileLanguages := make(map[string]*LanguageCount, len(files))
+	var supported, warnings []string
+	for _, file := range files {
+		language := fileLanguage(file)
+		if language == "" {
+			counts.Unsupported++
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipped %s: %v", file, err))
+			continue
+		}
+		if languages[language] == nil {
+			languages[language] = &LanguageCount{Language: language, kinds: make(map[string]int)}
+		}
+		languages[language].Files++
+		languages[language].Bytes += info.Size()
+		fileLanguages[file] = languages[language]
+		supported = append(supported, file)
+	}
+
+	kinds := make(map[string]int)
+	var outline []jsonFile
+	if len(supported) > 0 {
+		// The files just found are extracted, rather than matching the pattern again
+		opts.Discovery.Files = supported
+		opts.Structured = true
+		result, err := ExtractSymbolsContext(ctx, pattern, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, result.Warnings...)
+		if result.Structured != nil {
+			outline = result.Structured.Outline
+		}
+	}
+	for _, file := range outline {
+		lang := fileLanguages[file.Path]
+		if lang == nil {
+			continue
+		}
+		countSymbols(file.Symbols, func(kind string) {
+			counts.Symbols++
+			lang.Symbols++
+			lang.kinds[kind]++
+			kinds[kind]++
+		})
+	}
+
+	for _, lang := range languages {
+		lang.Kinds = sortedKindCounts(lang.kinds)
+		counts.Languages = append(counts.Languages, lang)
+	}
+	sort.Slice(counts.Languages, func(i, j int) bool {
+		if counts.Languages[i].Symbols != counts.Languages[j].Symbols {
+			return counts.Languages[i].Symbols > counts.Languages[j].Symbols
+		}
+		return counts.Languages[i].Language < counts.Languages[j].Language
+	})
+	counts.Kinds = sortedKindCounts(kinds)
+	return counts, warnings, nil
+}
+
+// countSymbols calls count with the kind of every symbol of a hierarchy
+func countSymbols(symbols []*jsonSymbol, count func(kind string)) {
+	for _, sym := range symbols {
+		count(sym.Kind)
+		countSymbols(sym.Children, count)
+	}
+}
+
+// sortedKindCounts lists the counts of a kind map, most symbols first
+func sortedKindCounts(kinds map[string]int) []*KindCount {
+	sorted := make([]*KindCount, 0, len(kinds))
+	for kind, count := range kinds {
+		sorted = append(sorted, &KindCount{Kind: kind, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Kind < sorted[j].Kind
+	})
+	return sorted
+}
+
+// FormatCounts formats symbol counts as Markdown
+func FormatCounts(counts *SymbolCounts, pattern string) string {
+	if counts.Files == 0 {
+		return "No files found matching pattern: " + pattern + "\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Symbols in %s\n\n", pattern))
+	sb.WriteString(fmt.Sprintf("%d files: %d supported with %d symbols, %d unsupported\n", counts.Files, counts.Files-counts.Unsupported, counts.Symbols, counts.Unsupported))
+
+	if len(counts.Languages) > 0 {
+		sb.WriteString("\n## Languages\n\n")
+		for _, lang := range counts.Languages {
+			sb.WriteString(fmt.Sprintf("- %s: %d files, %d bytes, %d symbols", lang.Language, lang.Files, lang.Bytes, lang.Symbols))
+			if len(lang.Kinds) > 0 {
+				parts := make([]string, len(lang.Kinds))
+				for i, kind := range lang.Kinds {
+					parts[i] = fmt.Sprintf("%d %s", kind.Count, kind.Kind)
+				}
+				sb.WriteString(" (" + strings.Join(parts, ", ") + ")")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(counts.Kinds) > 0 {
+		sb.WriteString("\n## Kinds\n\n")
+		for _, kind := range counts.Kinds {
+			sb.WriteString(fmt.Sprintf("- %s: %d\n", kind.Kind, kind.Count))
+		}
+	}
+	return sb.String()
+}
+
+// FormatCountsJSON formats symbol counts as a JSON document
+func FormatCountsJSON(counts *SymbolCounts) (string, error) {
+	output, err := json.MarshalIndent(counts, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output) + "\n", nil
+}
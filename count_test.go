@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountSymbolsContext(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"server.go": "package app\n\ntype Server struct{}\n\nfunc (s *Server) Start() {}\n\nfunc (s *Server) Stop() {}\n",
+		"main.go":   "package app\n\nfunc main() {}\n",
+		"tool.py":   "class Tool:\n    def run(self):\n        pass\n",
+		"notes.txt": "not source\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, warnings, err := CountSymbolsContext(context.Background(), filepath.Join(dir, "*"), ExtractOptions{Detail: Minimal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+	if counts.Files != 4 || counts.Unsupported != 1 {
+		t.Errorf("files = %d, unsupported = %d, want 4 and 1", counts.Files, counts.Unsupported)
+	}
+
+	byLanguage := make(map[string]*LanguageCount)
+	for _, lang := range counts.Languages {
+		byLanguage[lang.Language] = lang
+	}
+	goCount, pyCount := byLanguage["go"], byLanguage["python"]
+	if goCount == nil || goCount.Files != 2 || counts.Languages[0] != goCount {
+		t.Fatalf("languages = %+v, want go first with 2 files", counts.Languages)
+	}
+	// The module, the class and the method nested in it are all counted
+	if pyCount == nil || pyCount.Files != 1 || pyCount.Symbols != 3 {
+		t.Errorf("python = %+v, want 1 file and 3 symbols", pyCount)
+	}
+	if goCount.Kinds[0].Kind != "method" || goCount.Kinds[0].Count != 2 {
+		t.Errorf("go kinds = %+v, want the 2 methods first", goCount.Kinds)
+	}
+	total := 0
+	for _, lang := range counts.Languages {
+		total += lang.Symbols
+	}
+	if total != counts.Symbols {
+		t.Errorf("symbols = %d, want the languages' total %d", counts.Symbols, total)
+	}
+
+	text := FormatCounts(counts, filepath.Join(dir, "*"))
+	if !strings.Contains(text, "4 files: 3 supported with") || !strings.Contains(text, "- python: 1 files, ") {
+		t.Errorf("unexpected text:\n%s", text)
+	}
+	output, err := FormatCountsJSON(counts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc SymbolCounts
+	if err := json.Unmarshal([]byte(output), &doc); err != nil || doc.Symbols != counts.Symbols || len(doc.Kinds) != len(counts.Kinds) {
+		t.Errorf("invalid JSON (%v):\n%s", err, output)
+	}
+
+	if counts, _, err := CountSymbolsContext(context.Background(), filepath.Join(dir, "*.rs"), ExtractOptions{}); err != nil || counts.Files != 0 {
+		t.Errorf("CountSymbolsContext of no files = %+v, %v", counts, err)
+	}
+}
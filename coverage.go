@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CoverageProfile holds per-line hit counts loaded from a coverage file
+type CoverageProfile struct {
+	// files maps the file paths recorded in the profile to line hit counts
+	files map[string]map[uint32]int
+}
+
+// LoadCoverageProfile reads a Go coverprofile or an lcov tracefile
+func LoadCoverageProfile(path string) (*CoverageProfile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profile := &CoverageProfile{files: make(map[string]map[uint32]int)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return profile, nil
+	}
+
+	first := strings.TrimSpace(scanner.Text())
+	if strings.HasPrefix(first, "mode:") {
+		err = profile.parseGoProfile(scanner)
+	} else {
+		err = profile.parseLcov(first, scanner)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return profile, nil
+}
+
+// parseGoProfile parses the blocks of a Go coverprofile after its mode line.
+// Each block has the form "file:startLine.startCol,endLine.endCol numStmts count".
+func (p *CoverageProfile) parseGoProfile(scanner *bufio.Scanner) error {
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		colon := strings.LastIndex(line, ":")
+		if colon < 0 {
+			return fmt.Errorf("invalid coverprofile line: %s", line)
+		}
+		file := line[:colon]
+
+		fields := strings.Fields(line[colon+1:])
+		if len(fields) != 3 {
+			return fmt.Errorf("invalid coverprofile line: %s", line)
+		}
+
+		start, end, ok := strings.Cut(fields[0], ",")
+		if !ok {
+			return fmt.Errorf("invalid coverprofile block: %s", fields[0])
+		}
+		startLine, err := parseCoverageLine(start)
+		if err != nil {
+			return err
+		}
+		endLine, err := parseCoverageLine(end)
+		if err != nil {
+			return err
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return fmt.Errorf("invalid coverprofile count: %s", fields[2])
+		}
+
+		for l := startLine; l <= endLine; l++ {
+			p.record(file, l, count)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseLcov parses an lcov tracefile, using its SF and DA records
+func (p *CoverageProfile) parseLcov(first string, scanner *bufio.Scanner) error {
+	var file string
+	line := first
+
+	for {
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file = strings.TrimPrefix(line, "SF:")
+		case strings.HasPrefix(line, "DA:"):
+			if file == "" {
+				return fmt.Errorf("DA record outside of a source file: %s", line)
+			}
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return fmt.Errorf("invalid lcov line: %s", line)
+			}
+			lineNo, err := strconv.ParseUint(fields[0], 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid lcov line number: %s", line)
+			}
+			count, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return fmt.Errorf("invalid lcov hit count: %s", line)
+			}
+			p.record(file, uint32(lineNo), count)
+		case line == "end_of_record":
+			file = ""
+		}
+
+		if !scanner.Scan() {
+			break
+		}
+		line = strings.TrimSpace(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// parseCoverageLine parses the line part of a "line.column" position
+func parseCoverageLine(pos string) (uint32, error) {
+	lineStr, _, _ := strings.Cut(pos, ".")
+	line, err := strconv.ParseUint(lineStr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid coverprofile position: %s", pos)
+	}
+	return uint32(line), nil
+}
+
+// record stores the hit count of a line, keeping the highest count seen
+func (p *CoverageProfile) record(file string, line uint32, count int) {
+	lines, ok := p.files[file]
+	if !ok {
+		lines = make(map[uint32]int)
+		p.files[file] = lines
+	}
+	if prev, ok := lines[line]; !ok || count > prev {
+		lines[line] = count
+	}
+}
+
+// linesFor returns the line hit counts recorded for a file, or nil when the profile
+// has none or can't tell which of its files it is. Go profiles record import paths,
+// which are matched exactly when the file is in a module. Otherwise, as with the
+// project-relative paths of lcov, files are matched by the longest common path
+// suffix, which must be longer than the file's name and shared by no other file.
+func (p *CoverageProfile) linesFor(filePath string, modules *goModuleResolver) map[uint32]int {
+	target := filepath.ToSlash(filepath.Clean(filePath))
+	if importPath := modules.ImportPath(filePath); importPath != "" {
+		if lines, ok := p.files[importPath+"/"+filepath.Base(filePath)]; ok {
+			return lines
+		}
+	}
+
+	var best map[uint32]int
+	bestLen, ties := 0, 0
+	for file, lines := range p.files {
+		candidate := filepath.ToSlash(filepath.Clean(file))
+		if candidate == target {
+			return lines
+		}
+		switch n := pathSuffixLength(target, candidate); {
+		case n > bestLen:
+			best, bestLen, ties = lines, n, 0
+		case n == bestLen:
+			ties++
+		}
+	}
+	if bestLen < 2 || ties > 0 {
+		return nil
+	}
+	return best
+}
+
+// pathSuffixLength returns the number of trailing path elements shared by a and b
+func pathSuffixLength(a, b string) int {
+	aParts := strings.Split(strings.Trim(a, "/"), "/")
+	bParts := strings.Split(strings.Trim(b, "/"), "/")
+
+	n := 0
+	for n < len(aParts) && n < len(bParts) {
+		if aParts[len(aParts)-1-n] != bParts[len(bParts)-1-n] {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// Annotate sets the line coverage of every symbol that contains instrumented lines
+func (p *CoverageProfile) Annotate(symbols []Symbol) {
+	modules := newGoModuleResolver()
+	files := make(map[string]map[uint32]int)
+	for i := range symbols {
+		lines, ok := files[symbols[i].FilePath]
+		if !ok {
+			lines = p.linesFor(symbols[i].FilePath, modules)
+			files[symbols[i].FilePath] = lines
+		}
+		if lines == nil {
+			continue
+		}
+
+		var cov Coverage
+		for l := symbols[i].StartLine; l <= symbols[i].EndLine; l++ {
+			count, ok := lines[l]
+			if !ok {
+				continue
+			}
+			cov.Total++
+			if count > 0 {
+				cov.Covered++
+			}
+		}
+
+		if cov.Total > 0 {
+			symbols[i].Coverage = &cov
+		}
+	}
+}
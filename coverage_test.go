@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCoverageProfile_Annotate(t *testing.T) {
+	testDir := t.TempDir()
+	sourceFile := filepath.Join(testDir, "pkg", "server.go")
+
+	tests := []struct {
+		name    string
+		profile string
+	}{
+		{
+			name: "GoCoverprofile",
+			profile: `mode: set
+github.com/example/project/pkg/server.go:3.20,5.2 2 1
+github.com/example/project/pkg/server.go:7.20,8.12 1 1
+github.com/example/project/pkg/server.go:8.12,10.3 1 0
+github.com/example/project/other/server.go:3.20,5.2 2 0
+`,
+		},
+		{
+			name: "Lcov",
+			profile: `TN:
+SF:pkg/server.go
+DA:3,1
+DA:4,1
+DA:5,1
+DA:7,1
+DA:8,1
+DA:9,0
+DA:10,0
+end_of_record
+SF:other/server.go
+DA:3,0
+end_of_record
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profilePath := filepath.Join(testDir, tt.name+".out")
+			if err := os.WriteFile(profilePath, []byte(tt.profile), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			profile, err := LoadCoverageProfile(profilePath)
+			if err != nil {
+				t.Fatalf("LoadCoverageProfile error = %v", err)
+			}
+
+			symbols := []Symbol{
+				{Name: "Start", Kind: "func", StartLine: 3, EndLine: 5, FilePath: sourceFile},
+				{Name: "Stop", Kind: "func", StartLine: 7, EndLine: 11, FilePath: sourceFile},
+				{Name: "Server", Kind: "struct", StartLine: 12, EndLine: 14, FilePath: sourceFile},
+			}
+			profile.Annotate(symbols)
+
+			if cov := symbols[0].Coverage; cov == nil || cov.Covered != 3 || cov.Total != 3 {
+				t.Errorf("Expected Start to be fully covered, got %+v", cov)
+			}
+			if cov := symbols[1].Coverage; cov == nil || cov.Covered != 2 || cov.Total != 4 {
+				t.Errorf("Expected Stop to be half covered, got %+v", cov)
+			}
+			if symbols[2].Coverage != nil {
+				t.Errorf("Expected Server to have no coverage, got %+v", symbols[2].Coverage)
+			}
+
+			result := FormatSymbols(symbols, Minimal)
+			if !strings.Contains(result, "func: Stop (line 7) [coverage: 50% (2/4 lines)]") {
+				t.Errorf("Expected coverage annotation in output.\nResult:\n%s", result)
+			}
+		})
+	}
+}
+
+func TestCoverageProfile_SameNamedFiles(t *testing.T) {
+	testDir := t.TempDir()
+	module := filepath.Join(testDir, "app")
+	if err := os.MkdirAll(module, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(module, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		profile string
+		covered int
+	}{
+		{
+			name:    "import path",
+			file:    filepath.Join(module, "api", "util.go"),
+			profile: "mode: set\nexample.com/app/api/util.go:1.1,2.2 1 1\nexample.com/app/db/util.go:1.1,2.2 1 0\n",
+			covered: 2,
+		},
+		{
+			name:    "other package only",
+			file:    filepath.Join(module, "api", "util.go"),
+			profile: "mode: set\nexample.com/app/db/util.go:1.1,2.2 1 1\n",
+			covered: -1,
+		},
+		{
+			name:    "file name only",
+			file:    filepath.Join(testDir, "main.go"),
+			profile: "TN:\nSF:cmd/main.go\nDA:1,1\nend_of_record\n",
+			covered: -1,
+		},
+		{
+			name:    "ambiguous suffix",
+			file:    filepath.Join(testDir, "pkg", "util.go"),
+			profile: "TN:\nSF:one/pkg/util.go\nDA:1,1\nend_of_record\nSF:two/pkg/util.go\nDA:1,0\nend_of_record\n",
+			covered: -1,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profilePath := filepath.Join(testDir, fmt.Sprintf("cover%d.out", i))
+			if err := os.WriteFile(profilePath, []byte(tt.profile), 0644); err != nil {
+				t.Fatal(err)
+			}
+			profile, err := LoadCoverageProfile(profilePath)
+			if err != nil {
+				t.Fatalf("LoadCoverageProfile error = %v", err)
+			}
+
+			symbols := []Symbol{{Name: "Run", Kind: "func", StartLine: 1, EndLine: 2, FilePath: tt.file}}
+			profile.Annotate(symbols)
+			cov := symbols[0].Coverage
+			switch {
+			case tt.covered < 0 && cov != nil:
+				t.Errorf("expected no coverage, got %+v", cov)
+			case tt.covered >= 0 && (cov == nil || cov.Covered != tt.covered):
+				t.Errorf("expected %d covered lines, got %+v", tt.covered, cov)
+			}
+		})
+	}
+}
+
+func TestLoadCoverageProfile_Invalid(t *testing.T) {
+	profilePath := filepath.Join(t.TempDir(), "cover.out")
+	if err := os.WriteFile(profilePath, []byte("mode: set\nnot a block\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadCoverageProfile(profilePath); err == nil {
+		t.Errorf("Expected error for invalid coverprofile")
+	}
+}
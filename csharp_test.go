@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSharpSymbolExtraction(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/cs_basic.cs.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	symbolsByKind := make(map[string][]string)
+	for _, symbol := range symbols {
+		symbolsByKind[symbol.Kind] = append(symbolsByKind[symbol.Kind], symbol.Name)
+	}
+
+	expected := map[string][]string{
+		"namespace":   {"Shop.Orders", "Shop.File"},
+		"class":       {"OrderService", "Nested", "Internal"},
+		"record":      {"Order"},
+		"struct":      {"Point"},
+		"interface":   {"IRepository"},
+		"enum":        {"Status"},
+		"type":        {"Handler"},
+		"method":      {"Find", "FindAsync", "Log"},
+		"constructor": {"OrderService"},
+		"property":    {"Name"},
+		"field":       {"Changed", "Typed", "X", "_count", "Max"},
+	}
+	for kind, names := range expected {
+		for _, name := range names {
+			if !contains(symbolsByKind[kind], name) {
+				t.Errorf("Expected %s symbol '%s' not found. Found: %v", kind, name, symbolsByKind[kind])
+			}
+		}
+	}
+}
+
+func TestCSharpSignaturesAndVisibility(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/cs_basic.cs.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	tests := []struct {
+		kind      string
+		name      string
+		signature string
+		public    bool
+	}{
+		{"class", "OrderService", "public class OrderService : IRepository<Order>", true},
+		{"class", "Nested", "protected class Nested", false},
+		{"record", "Order", "public record Order(int Id, string Name)", true},
+		{"method", "Find", "public Order Find(int id)", true},
+		{"method", "Find", "T Find(int id)", true},
+		{"method", "FindAsync", "internal static async Task<Order> FindAsync<T>(int id) where T : class", false},
+		{"method", "Log", "private void Log()", false},
+		{"property", "Name", "public string Name", true},
+		{"field", "_count", "private readonly int _count", false},
+		{"field", "Changed", "public event EventHandler Changed", true},
+		{"namespace", "Shop.Orders", "namespace Shop.Orders", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind+" "+tt.signature, func(t *testing.T) {
+			for _, sym := range symbols {
+				if sym.Kind != tt.kind || sym.Name != tt.name || sym.Signature != tt.signature {
+					continue
+				}
+				if sym.Public != tt.public {
+					t.Errorf("Public = %v, want %v", sym.Public, tt.public)
+				}
+				return
+			}
+			t.Errorf("no %s %s with signature %q", tt.kind, tt.name, tt.signature)
+		})
+	}
+}
+
+func TestCSharpHierarchy(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/cs_basic.cs.txt", Minimal)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	result := FormatSymbols(symbols, Minimal)
+	if !strings.Contains(result, "- namespace: Shop.Orders (line 3)\n  - record: Order") {
+		t.Errorf("expected types nested under their namespace:\n%s", result)
+	}
+	if !strings.Contains(result, "  - class: OrderService (line 22)\n    - field: _count") {
+		t.Errorf("expected members nested under their class:\n%s", result)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// cssSignature returns a rule or at-rule up to its block, such as
+// ".button, a.link:hover" or "@media (max-width: 600px)", or a custom property
+// declaration without its semicolon
+func cssSignature(node *sitter.Node, content []byte) string {
+	end := node.EndByte()
+	if block := cssBlock(node); block != nil {
+		end = block.StartByte()
+	}
+	signature := strings.TrimSpace(string(content[node.StartByte():end]))
+	return strings.TrimSpace(strings.TrimSuffix(signature, ";"))
+}
+
+// cssMediaQuery returns the media query list of a @media rule, such as
+// "(max-width: 600px)"
+func cssMediaQuery(node *sitter.Node, content []byte) string {
+	if node == nil {
+		return ""
+	}
+	signature := strings.TrimPrefix(cssSignature(node, content), "@media")
+	return strings.TrimSpace(signature)
+}
+
+// cssBlock returns the block child of a rule or at-rule, or nil
+func cssBlock(node *sitter.Node) *sitter.Node {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "block" {
+			return child
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestCSSSymbolExtraction(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/scss_basic.scss.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	expected := map[string]string{
+		"selector :root":                 ":root",
+		"property --brand":               "--brand: #336699",
+		"selector .button, a.link:hover": ".button, a.link:hover",
+		"mixin rounded":                  "@mixin rounded($radius)",
+		"func double":                    "@function double($n)",
+		"media (max-width: 600px)":       "@media (max-width: 600px)",
+		"selector .title":                ".title",
+	}
+
+	found := make(map[string]string)
+	for _, sym := range symbols {
+		found[sym.Kind+" "+sym.Name] = sym.Signature
+	}
+	for key, signature := range expected {
+		if got, ok := found[key]; !ok {
+			t.Errorf("symbol %s not extracted", key)
+		} else if got != signature {
+			t.Errorf("%s: Signature = %q, want %q", key, got, signature)
+		}
+	}
+
+	// Ordinary declarations are not custom properties
+	if _, ok := found["property color"]; ok {
+		t.Error("unexpected symbol property color")
+	}
+}
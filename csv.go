@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+// csvHeader names the columns of the csv and tsv formats
+var csvHeader = []string{"file", "kind", "name", "start", "end", "signature"}
+
+// formatCSV writes symbols as a table with a header row and one row per symbol,
+// in the order they are given, separated by comma for csv or tab for tsv. Fields
+// containing the separator, quotes or newlines are quoted as RFC 4180 describes.
+func formatCSV(symbols []Symbol, comma rune) (string, error) {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Comma = comma
+
+	if err := w.Write(csvHeader); err != nil {
+		return "", err
+	}
+	for _, sym := range symbols {
+		record := []string{
+			sym.FilePath,
+			sym.Kind,
+			sym.Name,
+			strconv.FormatUint(uint64(sym.StartLine), 10),
+			strconv.FormatUint(uint64(sym.EndLine), 10),
+			sym.Signature,
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
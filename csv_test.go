@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestFormatCSV(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Widget", Kind: "class", StartLine: 3, EndLine: 9, Signature: "class Widget", FilePath: "/proj/widget.ts"},
+		{Name: "render", Kind: "method", StartLine: 4, EndLine: 6, Signature: "render(a, b): \"ok\"", FilePath: "/proj/widget.ts"},
+		{Name: "main", Kind: "func", StartLine: 1, EndLine: 2, FilePath: "/proj/main.go"},
+	}
+
+	tests := []struct {
+		name  string
+		comma rune
+		want  string
+	}{
+		{
+			name:  "csv",
+			comma: ',',
+			want: "file,kind,name,start,end,signature\n" +
+				"/proj/widget.ts,class,Widget,3,9,class Widget\n" +
+				"/proj/widget.ts,method,render,4,6,\"render(a, b): \"\"ok\"\"\"\n" +
+				"/proj/main.go,func,main,1,2,\n",
+		},
+		{
+			name:  "tsv",
+			comma: '\t',
+			want: "file\tkind\tname\tstart\tend\tsignature\n" +
+				"/proj/widget.ts\tclass\tWidget\t3\t9\tclass Widget\n" +
+				"/proj/widget.ts\tmethod\trender\t4\t6\t\"render(a, b): \"\"ok\"\"\"\n" +
+				"/proj/main.go\tfunc\tmain\t1\t2\t\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatCSV(symbols, tt.comma)
+			if err != nil {
+				t.Fatalf("formatCSV: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ctagsHeader is the pseudo-tag header of a sorted extended-format tags file
+const ctagsHeader = "!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/\n" +
+	"!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n" +
+	"!_TAG_PROGRAM_NAME\tglyph\t//\n" +
+	"!_TAG_PROGRAM_URL\thttps://github.com/benmyles/glyph\t//\n"
+
+// ctagsKinds maps symbol kinds to the kind names Universal Ctags uses for them
+var ctagsKinds = map[string]string{
+	"func":  "function",
+	"var":   "variable",
+	"const": "constant",
+}
+
+// ctagsTag is one line of a tags file
+type ctagsTag struct {
+	name, file string
+	line       uint32
+	fields     []string
+}
+
+// formatCtags writes symbols as a Universal Ctags compatible tags file, sorted by
+// name, with line number addresses and kind, line, scope and end fields. Files
+// are relative to root, so the tags file belongs in the project root. Notebook
+// symbols are left out, since their lines are relative to a cell.
+func formatCtags(symbols []Symbol, root string) string {
+	var files []string
+	fileSymbols := make(map[string][]Symbol)
+	for _, sym := range symbols {
+		if sym.Cell != nil || strings.ContainsAny(sym.Name, "\t\r\n") {
+			continue
+		}
+		if _, ok := fileSymbols[sym.FilePath]; !ok {
+			files = append(files, sym.FilePath)
+		}
+		fileSymbols[sym.FilePath] = append(fileSymbols[sym.FilePath], sym)
+	}
+
+	var tags []ctagsTag
+	for _, file := range files {
+		path := file
+		if root != "" {
+			if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+		tags = appendCtags(tags, BuildHierarchy(fileSymbols[file]), path, "", "")
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		if tags[i].name != tags[j].name {
+			return tags[i].name < tags[j].name
+		}
+		if tags[i].file != tags[j].file {
+			return tags[i].file < tags[j].file
+		}
+		return tags[i].line < tags[j].line
+	})
+
+	var sb strings.Builder
+	sb.WriteString(ctagsHeader)
+	previous := ""
+	for _, tag := range tags {
+		// A symbol matched by two queries, such as a decorated Python class, is tagged once
+		line := fmt.Sprintf("%s\t%s\t%d;\"\t%s\n", tag.name, tag.file, tag.line, strings.Join(tag.fields, "\t"))
+		if line != previous {
+			sb.WriteString(line)
+		}
+		previous = line
+	}
+	return sb.String()
+}
+
+// appendCtags appends the tags of a symbol hierarchy. Symbols nested in another
+// are scoped by its kind and dotted name, and Go methods by their receiver type.
+func appendCtags(tags []ctagsTag, nodes []*SymbolNode, file, scopeKind, scope string) []ctagsTag {
+	for _, node := range nodes {
+		sym := node.Symbol
+		fields := []string{"kind:" + ctagsKind(sym.Kind), fmt.Sprintf("line:%d", sym.StartLine)}
+		switch {
+		case scope != "":
+			fields = append(fields, scopeKind+":"+scope)
+		case sym.Receiver != "":
+			fields = append(fields, "type:"+sym.Receiver)
+		}
+		fields = append(fields, fmt.Sprintf("end:%d", sym.EndLine))
+		tags = append(tags, ctagsTag{name: sym.Name, file: file, line: sym.StartLine, fields: fields})
+
+		childScope := sym.Name
+		if scope != "" {
+			childScope = scope + "." + sym.Name
+		}
+		tags = appendCtags(tags, node.Children, file, ctagsKind(sym.Kind), childScope)
+	}
+	return tags
+}
+
+// ctagsKind returns the Universal Ctags name of a symbol kind
+func ctagsKind(kind string) string {
+	if name, ok := ctagsKinds[kind]; ok {
+		return name
+	}
+	return kind
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatCtags(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Widget", Kind: "class", StartLine: 3, EndLine: 9, StartColumn: 1, EndColumn: 2, FilePath: "/proj/src/widget.ts"},
+		{Name: "render", Kind: "method", StartLine: 4, EndLine: 6, StartColumn: 3, EndColumn: 4, FilePath: "/proj/src/widget.ts"},
+		{Name: "Widget", Kind: "class", StartLine: 3, EndLine: 9, StartColumn: 1, EndColumn: 2, FilePath: "/proj/src/widget.ts"},
+		{Name: "Area", Kind: "method", Receiver: "Circle", StartLine: 7, EndLine: 9, StartColumn: 1, EndColumn: 2, FilePath: "/proj/shapes.go"},
+		{Name: "main", Kind: "func", StartLine: 1, EndLine: 2, StartColumn: 1, EndColumn: 2, FilePath: "/elsewhere/main.go"},
+		{Name: "fit", Kind: "func", StartLine: 1, EndLine: 2, FilePath: "/proj/a.ipynb", Cell: &NotebookCell{Index: 2}},
+	}
+
+	got := formatCtags(symbols, "/proj")
+	if !strings.HasPrefix(got, ctagsHeader) {
+		t.Fatalf("missing pseudo-tag header:\n%s", got)
+	}
+
+	want := []string{
+		"Area\tshapes.go\t7;\"\tkind:method\tline:7\ttype:Circle\tend:9",
+		"Widget\tsrc/widget.ts\t3;\"\tkind:class\tline:3\tend:9",
+		"main\t/elsewhere/main.go\t1;\"\tkind:function\tline:1\tend:2",
+		"render\tsrc/widget.ts\t4;\"\tkind:method\tline:4\tclass:Widget\tend:6",
+	}
+	if tags := strings.TrimPrefix(got, ctagsHeader); tags != strings.Join(want, "\n")+"\n" {
+		t.Errorf("tags =\n%s\nwant\n%s", tags, strings.Join(want, "\n"))
+	}
+}
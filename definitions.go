@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// definition is a symbol named by a find_definition call, with the dotted names of
+// the symbols enclosing it
+type definition struct {
+	symbol    Symbol
+	qualified string
+}
+
+// FindDefinitionsContext finds the symbols named exactly name in the files matching
+// a pattern, without formatting the rest of their outlines. Files that never
+// mention the name are not parsed.
+func FindDefinitionsContext(ctx context.Context, name, pattern string, opts ExtractOptions) (*ExtractionResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name must not be empty")
+	}
+
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files: %w", err)
+	}
+	if len(files) == 0 {
+		return noFilesResult(pattern), nil
+	}
+	if err := opts.Limits.check(len(files), 0); err != nil {
+		return nil, err
+	}
+
+	result := &ExtractionResult{Status: StatusOK, Files: len(files), log: opts.Log}
+	// Definitions are the symbols of the built-in queries
+	opts.Query = ""
+	outcomes, stop := extractConcurrently(ctx, files, opts, func(extractor *SymbolExtractor, file string) fileOutcome {
+		return definitionsOutcome(extractor, opts, file, name)
+	})
+	defer stop()
+
+	var found []string
+	fileDefinitions := make(map[string][]definition)
+	var parsedBytes int64
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(i, len(files))
+		}
+
+		var outcome fileOutcome
+		select {
+		case outcome = <-outcomes[i]:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if opts.Log != nil {
+			for _, event := range outcome.events {
+				opts.Log(event)
+			}
+		}
+
+		parsedBytes += int64(len(outcome.content))
+		if err := opts.Limits.check(len(files), parsedBytes); err != nil {
+			return nil, err
+		}
+		if outcome.err != nil {
+			result.warnSkipped(file, outcome.err)
+			continue
+		}
+
+		definitions := appendDefinitions(nil, BuildHierarchy(outcome.symbols), name, "")
+		if len(definitions) == 0 {
+			continue
+		}
+		found = append(found, file)
+		fileDefinitions[file] = definitions
+		result.Symbols += len(definitions)
+	}
+
+	if opts.Progress != nil {
+		opts.Progress(len(files), len(files))
+	}
+
+	if result.Symbols == 0 {
+		result.Status = StatusNoSymbols
+		result.Output = fmt.Sprintf("No definitions of %s found\n", name)
+		return result, nil
+	}
+	result.Output = formatDefinitions(name, found, fileDefinitions, result.Symbols, opts.MaxSignatureLength)
+	return result, nil
+}
+
+// definitionsOutcome reads a file once and extracts its symbols when it mentions name
+func definitionsOutcome(extractor *SymbolExtractor, opts ExtractOptions, file, name string) fileOutcome {
+	var outcome fileOutcome
+	if GetLanguageQueriesForFile(file) == nil && !IsNotebookFile(file) {
+		return outcome
+	}
+	if opts.Log != nil {
+		opts.Log = func(event LogEvent) { outcome.events = append(outcome.events, event) }
+	}
+
+	readAt := time.Now()
+	content, info, err := readStableFile(file, os.ReadFile)
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+	outcome.content = content
+	if !bytes.Contains(content, []byte(name)) {
+		return outcome
+	}
+	outcome.symbols, outcome.err = extractFileContent(extractor, opts, file, content, stampOf(info, readAt), opts.Detail)
+	return outcome
+}
+
+// appendDefinitions appends the symbols of a hierarchy named name, qualifying each
+// by the symbols enclosing it, or a Go method by its receiver type
+func appendDefinitions(definitions []definition, nodes []*SymbolNode, name, scope string) []definition {
+	for _, node := range nodes {
+		sym := node.Symbol
+		qualified := sym.Name
+		if scope != "" {
+			qualified = scope + "." + sym.Name
+		} else if sym.Receiver != "" {
+			qualified = sym.Receiver + "." + sym.Name
+		}
+		if sym.Name == name {
+			definitions = append(definitions, definition{symbol: sym, qualified: qualified})
+		}
+		definitions = appendDefinitions(definitions, node.Children, name, qualified)
+	}
+	return definitions
+}
+
+// formatDefinitions formats definitions grouped by file, each with its line range
+// and its signature on one line
+func formatDefinitions(name string, files []string, fileDefinitions map[string][]definition, count, maxSignatureLength int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Definitions of %s\n\n", name))
+	sb.WriteString(fmt.Sprintf("%d definitions in %d files\n", count, len(files)))
+
+	for _, file := range files {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", file))
+		for _, def := range fileDefinitions[file] {
+			sym := def.symbol
+			location := fmt.Sprintf("lines %d-%d", sym.StartLine, sym.EndLine)
+			if sym.Cell != nil {
+				location = fmt.Sprintf("cell %d, %s", sym.Cell.Index, location)
+			}
+			sb.WriteString(fmt.Sprintf("- %s %s (%s)", sym.Kind, def.qualified, location))
+			if signature := strings.Join(strings.Fields(TruncateSignature(sym.Signature, maxSignatureLength)), " "); signature != "" {
+				sb.WriteString(": `" + signature + "`")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
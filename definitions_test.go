@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFindDefinitionsContext(t *testing.T) {
+	testDir := t.TempDir()
+
+	testFiles := map[string]string{
+		"server.go": `package app
+
+type Server struct{}
+
+// Start runs the server
+func (s *Server) Start(addr string) error {
+	return nil
+}
+`,
+		"Worker.java": `public class Worker {
+    public void Start() {
+    }
+}
+`,
+		"main.go": `package app
+
+func main() {
+	s := &Server{}
+	s.Start(":8080")
+}
+`,
+	}
+	for name, code := range testFiles {
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := FindDefinitionsContext(context.Background(), "Start", filepath.Join(testDir, "*"), ExtractOptions{Detail: Standard})
+	if err != nil {
+		t.Fatalf("FindDefinitionsContext error = %v", err)
+	}
+	if result.Status != StatusOK || result.Files != 3 || result.Symbols != 2 {
+		t.Errorf("result = %+v, want 2 definitions in 3 files", result)
+	}
+
+	// The call in main.go is not a definition
+	want := "# Definitions of Start\n\n" +
+		"2 definitions in 2 files\n" +
+		"\n## " + filepath.Join(testDir, "Worker.java") + "\n\n" +
+		"- method Worker.Start (lines 2-3): `public void Start()`\n" +
+		"\n## " + filepath.Join(testDir, "server.go") + "\n\n" +
+		"- method Server.Start (lines 6-8): `func (s *Server) Start(addr string) error`\n"
+	if result.Output != want {
+		t.Errorf("Output =\n%s\nwant\n%s", result.Output, want)
+	}
+
+	// Files are parsed by several workers, or their symbols taken from the cache,
+	// without changing the definitions
+	cache := NewSymbolCache(10)
+	for _, opts := range []ExtractOptions{
+		{Detail: Standard, Jobs: 1},
+		{Detail: Standard, Jobs: 4},
+		{Detail: Standard, Cache: cache},
+		{Detail: Standard, Cache: cache},
+	} {
+		result, err := FindDefinitionsContext(context.Background(), "Start", filepath.Join(testDir, "*"), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Output != want {
+			t.Errorf("Output with %+v =\n%s\nwant\n%s", opts, result.Output, want)
+		}
+	}
+	if cache.Len() != 3 {
+		t.Errorf("cache.Len() = %d, want the 3 files mentioning Start", cache.Len())
+	}
+
+	// Names are matched exactly
+	result, err = FindDefinitionsContext(context.Background(), "Star", filepath.Join(testDir, "*"), ExtractOptions{Detail: Standard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != StatusNoSymbols || !strings.Contains(result.Output, "No definitions of Star") {
+		t.Errorf("result = %+v, want no definitions", result)
+	}
+}
+
+func TestToolHandlers_FindDefinition(t *testing.T) {
+	tempDir := t.TempDir()
+	code := "package lib\n\nfunc Helper(a, b int) int {\n\treturn a + b\n}\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "lib.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"name": "Helper", "pattern": filepath.Join(tempDir, "*.go")}
+
+	result, err := handlers.findDefinition(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || result.Meta["symbols"] != 1 || !strings.Contains(text, "- func Helper (lines 3-5): `func Helper(a, b int) int`") {
+		t.Errorf("expected the definition of Helper, got %q", text)
+	}
+
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(tempDir, "*.go")}
+	result, err = handlers.findDefinition(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("expected an error without name")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Deprecated filter modes
+const (
+	DeprecatedInclude = "include"
+	DeprecatedExclude = "exclude"
+	DeprecatedOnly    = "only"
+)
+
+// declarationWrappers are nodes that carry the doc comment of the single
+// declaration they wrap, such as "export class" or a Go "type X struct"
+var declarationWrappers = map[string]bool{
+	"export_statement":     true,
+	"decorated_definition": true,
+	"lexical_declaration":  true,
+	"variable_declaration": true,
+	"type_declaration":     true,
+	"const_declaration":    true,
+	"var_declaration":      true,
+}
+
+// isDeprecated reports whether a declaration follows its language's deprecation
+// convention: a "Deprecated:" paragraph in a Go doc comment, an @deprecated tag in
+// Javadoc or JSDoc, or a @Deprecated annotation or deprecated decorator such as
+// Python's @warnings.deprecated
+func isDeprecated(node *sitter.Node, content []byte, annotations []string) bool {
+	for _, a := range annotations {
+		name := strings.TrimPrefix(a, "@")
+		name = name[strings.LastIndex(name, ".")+1:]
+		if name == "Deprecated" || name == "deprecated" {
+			return true
+		}
+	}
+
+	for node != nil {
+		if docCommentDeprecated(node, content) {
+			return true
+		}
+		parent := node.Parent()
+		if parent == nil || !declarationWrappers[parent.Type()] || declarationCount(parent) != 1 {
+			break
+		}
+		node = parent
+	}
+	return false
+}
+
+// docCommentDeprecated reports whether the comments directly above a node,
+// without a blank line in between, mark it as deprecated
+func docCommentDeprecated(node *sitter.Node, content []byte) bool {
+	line := node.StartPoint().Row
+	for s := node.PrevSibling(); s != nil && isComment(s); s = s.PrevSibling() {
+		if s.EndPoint().Row+1 < line {
+			break
+		}
+		if commentDeprecated(string(content[s.StartByte():s.EndByte()])) {
+			return true
+		}
+		line = s.StartPoint().Row
+	}
+	return false
+}
+
+// commentDeprecated reports whether a comment has a line starting with "Deprecated:"
+// or an @deprecated tag
+func commentDeprecated(comment string) bool {
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range []string{"///", "//", "/**", "/*", "*", "#"} {
+			if strings.HasPrefix(line, prefix) {
+				line = strings.TrimSpace(strings.TrimPrefix(line, prefix))
+				break
+			}
+		}
+		if strings.HasPrefix(line, "Deprecated:") || strings.HasPrefix(line, "@deprecated") {
+			return true
+		}
+	}
+	return false
+}
+
+func isComment(node *sitter.Node) bool {
+	switch node.Type() {
+	case "comment", "line_comment", "block_comment":
+		return true
+	}
+	return false
+}
+
+// declarationCount returns the number of named children of a node that are
+// neither comments nor decorators
+func declarationCount(node *sitter.Node) int {
+	count := 0
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if !isComment(child) && child.Type() != "decorator" {
+			count++
+		}
+	}
+	return count
+}
+
+// filterDeprecated keeps or drops deprecated symbols as selected by mode
+func filterDeprecated(symbols []Symbol, mode string) ([]Symbol, error) {
+	switch mode {
+	case "", DeprecatedInclude:
+		return symbols, nil
+	case DeprecatedExclude, DeprecatedOnly:
+	default:
+		return nil, fmt.Errorf("unknown deprecated value: %s", mode)
+	}
+
+	keep := mode == DeprecatedOnly
+	var kept []Symbol
+	for _, sym := range symbols {
+		if sym.Deprecated == keep {
+			kept = append(kept, sym)
+		}
+	}
+	return kept, nil
+}
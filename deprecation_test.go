@@ -0,0 +1,193 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSymbolDeprecation(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		source   string
+		expected map[string]bool
+	}{
+		{
+			name:     "go",
+			fileName: "legacy.go",
+			source: `package legacy
+
+// OldClient talks to the v1 API.
+//
+// Deprecated: use Client instead.
+type OldClient struct{}
+
+// Client talks to the v2 API.
+type Client struct{}
+
+// Deprecated: use Client.Fetch.
+func (c *OldClient) Get() {}
+
+// Deprecated: unused.
+
+func Detached() {}
+
+const (
+	// Deprecated: use Limit.
+	MaxItems = 10
+	Limit    = 20
+)
+`,
+			expected: map[string]bool{
+				"OldClient": true,
+				"Client":    false,
+				"Get":       true,
+				"Detached":  false,
+				"MaxItems":  true,
+				"Limit":     false,
+			},
+		},
+		{
+			name:     "java",
+			fileName: "Api.java",
+			source: `public class Api {
+    @Deprecated
+    public void oldCall() {}
+
+    /**
+     * Fetches a page.
+     * @deprecated use fetch(int, int)
+     */
+    public void page() {}
+
+    public void fetch() {}
+}
+`,
+			expected: map[string]bool{
+				"Api":     false,
+				"oldCall": true,
+				"page":    true,
+				"fetch":   false,
+			},
+		},
+		{
+			name:     "typescript",
+			fileName: "api.ts",
+			source: `/** @deprecated use fetchUsers */
+export function getUsers(): void {}
+
+export function fetchUsers(): void {}
+
+class Store {
+  /**
+   * @deprecated
+   */
+  load(): void {}
+}
+`,
+			expected: map[string]bool{
+				"getUsers":   true,
+				"fetchUsers": false,
+				"load":       true,
+			},
+		},
+		{
+			name:     "python",
+			fileName: "api.py",
+			source: `from warnings import deprecated
+import warnings
+
+@warnings.deprecated("use fetch")
+def get():
+    pass
+
+@deprecated("use fetch")
+def load():
+    pass
+
+def fetch():
+    pass
+`,
+			expected: map[string]bool{
+				"get":   true,
+				"load":  true,
+				"fetch": false,
+			},
+		},
+	}
+
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(testFile, []byte(tt.source), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			symbols, err := extractor.ExtractFromFile(testFile, Minimal)
+			if err != nil {
+				t.Fatalf("ExtractFromFile error = %v", err)
+			}
+
+			found := make(map[string]bool)
+			for _, sym := range symbols {
+				expected, ok := tt.expected[sym.Name]
+				if !ok {
+					continue
+				}
+				found[sym.Name] = true
+				if sym.Deprecated != expected {
+					t.Errorf("%s %s: Deprecated = %v, want %v", sym.Kind, sym.Name, sym.Deprecated, expected)
+				}
+			}
+
+			for name := range tt.expected {
+				if !found[name] {
+					t.Errorf("symbol %s not extracted", name)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterDeprecated(t *testing.T) {
+	symbols := []Symbol{{Name: "Old", Deprecated: true}, {Name: "New"}}
+
+	tests := []struct {
+		mode     string
+		expected []string
+	}{
+		{"", []string{"Old", "New"}},
+		{DeprecatedInclude, []string{"Old", "New"}},
+		{DeprecatedExclude, []string{"New"}},
+		{DeprecatedOnly, []string{"Old"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			kept, err := filterDeprecated(symbols, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, sym := range kept {
+				names = append(names, sym.Name)
+			}
+			if len(names) != len(tt.expected) {
+				t.Fatalf("filterDeprecated(%q) = %v, want %v", tt.mode, names, tt.expected)
+			}
+			for i := range names {
+				if names[i] != tt.expected[i] {
+					t.Errorf("filterDeprecated(%q) = %v, want %v", tt.mode, names, tt.expected)
+				}
+			}
+		})
+	}
+
+	if _, err := filterDeprecated(symbols, "never"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of SymbolChange
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// SymbolChange is a symbol added, removed or changed between two outlines
+type SymbolChange struct {
+	Change string `json:"change"`
+	// File is the symbol's path relative to the base directory of each side
+	File string `json:"file"`
+	Kind string `json:"kind"`
+	// Name is qualified by the names of the enclosing symbols, or a Go method's receiver
+	Name         string `json:"name"`
+	OldSignature string `json:"old_signature,omitempty"`
+	NewSignature string `json:"new_signature,omitempty"`
+	OldLine      uint32 `json:"old_line,omitempty"`
+	NewLine      uint32 `json:"new_line,omitempty"`
+}
+
+// diffEntry is a symbol of one side of a diff
+type diffEntry struct {
+	file      string
+	kind      string
+	name      string
+	signature string
+	line      uint32
+}
+
+// DiffOutlines compares the symbols of the files matching two patterns, such as
+// two checkouts of a project, and returns the symbols only one of them declares
+// and those whose signature changed. Files are matched by their path relative to
+// each pattern's base directory, and symbols by their qualified name and kind, so
+// a symbol that only moved within its file is unchanged. Two single files are
+// compared with each other whatever their names.
+func DiffOutlines(ctx context.Context, oldPattern, newPattern string, opts ExtractOptions) ([]SymbolChange, []string, error) {
+	single := isSingleFile(oldPattern) && isSingleFile(newPattern)
+	oldEntries, oldWarnings, err := diffEntries(ctx, oldPattern, single, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	newEntries, newWarnings, err := diffEntries(ctx, newPattern, single, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes []SymbolChange
+	for key, entry := range newEntries {
+		old, ok := oldEntries[key]
+		switch {
+		case !ok:
+			changes = append(changes, SymbolChange{Change: ChangeAdded, File: entry.file, Kind: entry.kind, Name: entry.name, NewSignature: entry.signature, NewLine: entry.line})
+		case old.signature != entry.signature:
+			changes = append(changes, SymbolChange{Change: ChangeChanged, File: entry.file, Kind: entry.kind, Name: entry.name, OldSignature: old.signature, NewSignature: entry.signature, OldLine: old.line, NewLine: entry.line})
+		}
+	}
+	for key, entry := range oldEntries {
+		if _, ok := newEntries[key]; !ok {
+			changes = append(changes, SymbolChange{Change: ChangeRemoved, File: entry.file, Kind: entry.kind, Name: entry.name, OldSignature: entry.signature, OldLine: entry.line})
+		}
+	}
+
+	if single {
+		for i := range changes {
+			changes[i].File = filepath.Base(newPattern)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if lineA, lineB := max(a.NewLine, a.OldLine), max(b.NewLine, b.OldLine); lineA != lineB {
+			return lineA < lineB
+		}
+		return a.Name+a.Kind < b.Name+b.Kind
+	})
+	return changes, append(oldWarnings, newWarnings...), nil
+}
+
+// isSingleFile reports whether a pattern names one existing file
+func isSingleFile(pattern string) bool {
+	if strings.ContainsAny(pattern, "*?[,") {
+		return false
+	}
+	info, err := os.Stat(pattern)
+	return err == nil && !info.IsDir()
+}
+
+// diffEntries extracts the symbols of a pattern keyed by their relative path,
+// qualified name and kind. The path is left out when single files are compared.
+func diffEntries(ctx context.Context, pattern string, single bool, opts ExtractOptions) (map[string]diffEntry, []string, error) {
+	opts.Structured = true
+	result, err := ExtractSymbolsContext(ctx, pattern, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make(map[string]diffEntry)
+	if result.Structured == nil {
+		return entries, result.Warnings, nil
+	}
+	baseDir := PatternBaseDir(pattern)
+	for _, file := range result.Structured.Outline {
+		rel := ""
+		if !single {
+			rel = file.Path
+			if r, err := filepath.Rel(baseDir, file.Path); err == nil {
+				rel = filepath.ToSlash(r)
+			}
+		}
+		// Declarations matched by several queries, such as a Go struct that is also
+		// a type, are compared once
+		spans := make(map[string]bool)
+		var add func(symbols []*jsonSymbol, scope string)
+		add = func(symbols []*jsonSymbol, scope string) {
+			for _, sym := range symbols {
+				name := sym.Name
+				switch {
+				case sym.Receiver != "":
+					name = sym.Receiver + "." + name
+				case scope != "":
+					name = scope + "." + name
+				}
+				span := fmt.Sprintf("%s\x00%d\x00%d", name, sym.StartLine, sym.EndLine)
+				if !spans[span] {
+					spans[span] = true
+					// Overloads and redeclarations are told apart by their order
+					base := rel + "\x00" + name + "\x00" + sym.Kind
+					key := base
+					for n := 2; ; n++ {
+						if _, ok := entries[key]; !ok {
+							break
+						}
+						key = base + "\x00" + strconv.Itoa(n)
+					}
+					entries[key] = diffEntry{
+						file:      rel,
+						kind:      sym.Kind,
+						name:      name,
+						signature: strings.Join(strings.Fields(sym.Signature), " "),
+						line:      sym.StartLine,
+					}
+				}
+				add(sym.Children, name)
+			}
+		}
+		add(file.Symbols, "")
+	}
+	return entries, result.Warnings, nil
+}
+
+// FormatDiff formats symbol changes as Markdown, grouped by file
+func FormatDiff(changes []SymbolChange) string {
+	if len(changes) == 0 {
+		return "No symbols changed\n"
+	}
+
+	totals := make(map[string]int)
+	for _, change := range changes {
+		totals[change.Change]++
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Symbol changes: %d added, %d removed, %d changed\n", totals[ChangeAdded], totals[ChangeRemoved], totals[ChangeChanged]))
+
+	file := ""
+	for i, change := range changes {
+		if i == 0 || change.File != file {
+			file = change.File
+			sb.WriteString(fmt.Sprintf("\n## %s\n\n", file))
+		}
+		switch change.Change {
+		case ChangeAdded:
+			sb.WriteString(fmt.Sprintf("- added %s %s (line %d)%s\n", change.Kind, change.Name, change.NewLine, diffSignature(change.NewSignature)))
+		case ChangeRemoved:
+			sb.WriteString(fmt.Sprintf("- removed %s %s (was line %d)%s\n", change.Kind, change.Name, change.OldLine, diffSignature(change.OldSignature)))
+		case ChangeChanged:
+			sb.WriteString(fmt.Sprintf("- changed %s %s (line %d)\n", change.Kind, change.Name, change.NewLine))
+			sb.WriteString(fmt.Sprintf("  - old: %s\n", change.OldSignature))
+			sb.WriteString(fmt.Sprintf("  - new: %s\n", change.NewSignature))
+		}
+	}
+	return sb.String()
+}
+
+// diffSignature formats a signature following a change, if the symbol has one
+func diffSignature(signature string) string {
+	if signature == "" {
+		return ""
+	}
+	return ": " + signature
+}
+
+// FormatDiffJSON formats symbol changes as a JSON document
+func FormatDiffJSON(changes []SymbolChange) (string, error) {
+	if changes == nil {
+		changes = []SymbolChange{}
+	}
+	output, err := json.MarshalIndent(map[string]any{"changes": changes}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output) + "\n", nil
+}
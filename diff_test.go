@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffOutlines(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	write := func(dir, name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(oldDir, "pkg/server.go", "package pkg\n\ntype Server struct{}\n\nfunc (s *Server) Start() error { return nil }\n\nfunc legacy() {}\n\nfunc Moved() {}\n")
+	// Moved only changes position, and the struct's fields aren't part of its signature
+	write(newDir, "pkg/server.go", "package pkg\n\nimport \"context\"\n\nfunc Moved() {}\n\ntype Server struct{ addr string }\n\nfunc (s *Server) Start(ctx context.Context) error { return nil }\n\nfunc (s *Server) Stop() {}\n")
+	write(oldDir, "tool.py", "class Tool:\n    def run(self):\n        pass\n")
+	write(newDir, "tool.py", "class Tool:\n    def run(self, fast):\n        pass\n")
+
+	changes, _, err := DiffOutlines(context.Background(), filepath.Join(oldDir, "**"), filepath.Join(newDir, "**"), ExtractOptions{Detail: Standard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.Change+" "+change.File+" "+change.Kind+" "+change.Name)
+	}
+	want := []string{
+		"removed pkg/server.go func legacy",
+		"changed pkg/server.go method Server.Start",
+		"added pkg/server.go method Server.Stop",
+		"changed tool.py func Tool.run",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if changes[1].OldSignature != "func (s *Server) Start() error" || changes[1].NewSignature != "func (s *Server) Start(ctx context.Context) error" {
+		t.Errorf("changed signatures = %q, %q", changes[1].OldSignature, changes[1].NewSignature)
+	}
+
+	// Single files are compared whatever their names
+	write(oldDir, "a.go", "package pkg\n\nfunc A() {}\n")
+	write(newDir, "b.go", "package pkg\n\nfunc A(n int) {}\n")
+	changes, _, err = DiffOutlines(context.Background(), filepath.Join(oldDir, "a.go"), filepath.Join(newDir, "b.go"), ExtractOptions{Detail: Standard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Change != ChangeChanged || changes[0].File != "b.go" {
+		t.Errorf("changes between single files = %+v", changes)
+	}
+
+	text := FormatDiff(changes)
+	if !strings.Contains(text, "# Symbol changes: 0 added, 0 removed, 1 changed") || !strings.Contains(text, "  - new: func A(n int)") {
+		t.Errorf("unexpected Markdown:\n%s", text)
+	}
+	if text := FormatDiff(nil); text != "No symbols changed\n" {
+		t.Errorf("FormatDiff(nil) = %q", text)
+	}
+
+	output, err := FormatDiffJSON(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Changes []SymbolChange `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(output), &doc); err != nil || doc.Changes == nil {
+		t.Errorf("FormatDiffJSON(nil) = %s, want an empty list", output)
+	}
+}
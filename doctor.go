@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// DoctorCheck is the outcome of one self-check, with a suggested fix when it failed
+type DoctorCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+	Fix    string
+}
+
+// doctorSamples are tiny programs that every supported language must parse
+// without errors, each declaring one symbol the extractor must find
+var doctorSamples = []struct {
+	langQueries *LanguageQueries
+	code        string
+	symbol      string
+}{
+	{goLanguageQueries, "package main\n\nfunc main() {}\n", "main"},
+	{javaLanguageQueries, "class App {\n  void run() {}\n}\n", "App"},
+	{javascriptLanguageQueries, "function start() {}\n", "start"},
+	{typescriptLanguageQueries, "interface Options {\n  name: string;\n}\n", "Options"},
+	{pythonLanguageQueries, "def main():\n    pass\n", "main"},
+	{rustLanguageQueries, "fn main() {}\n", "main"},
+	{phpLanguageQueries, "<?php\nfunction main() {}\n", "main"},
+	{elixirLanguageQueries, "defmodule App do\nend\n", "App"},
+	{bashLanguageQueries, "deploy() {\n  echo ok\n}\n", "deploy"},
+	{hclLanguageQueries, "variable \"region\" {}\n", "region"},
+	{markdownLanguageQueries, "# Title\n", "Title"},
+	{yamlLanguageQueries, "apiVersion: v1\nkind: Service\n", "Service"},
+	{cssLanguageQueries, ".button {\n  color: red;\n}\n", ".button"},
+	{htmlLanguageQueries, "<div id=\"app\"></div>\n", "app"},
+	{csharpLanguageQueries, "class App {\n  void Run() {}\n}\n", "App"},
+}
+
+// RunDoctor checks that every grammar and its queries load, that a sample of each
+// language parses, and that the cache directory and imported indexes are usable.
+// With a non-empty mcpCommand it also starts "<mcpCommand> mcp" and performs an MCP
+// handshake over stdio.
+func RunDoctor(mcpCommand string) []DoctorCheck {
+	var checks []DoctorCheck
+	for _, sample := range doctorSamples {
+		checks = append(checks, checkLanguage(sample.langQueries, sample.code, sample.symbol))
+	}
+	checks = append(checks, checkCache()...)
+	if mcpCommand != "" {
+		checks = append(checks, checkMCPHandshake(mcpCommand))
+	}
+	return checks
+}
+
+// checkLanguage compiles every query of a language and extracts symbols from a sample
+func checkLanguage(langQueries *LanguageQueries, code, symbol string) DoctorCheck {
+	check := DoctorCheck{Name: "language " + langQueries.Name}
+	if langQueries.Language == nil {
+		check.Detail = "grammar is not available"
+		check.Fix = "rebuild glyph with cgo enabled (CGO_ENABLED=1)"
+		return check
+	}
+
+	var broken []string
+	for symbolType, queryStr := range langQueries.Queries {
+		query, err := sitter.NewQuery([]byte(queryStr), langQueries.Language)
+		if err != nil {
+			broken = append(broken, fmt.Sprintf("%s (%v)", symbolType, err))
+			continue
+		}
+		query.Close()
+	}
+	if len(broken) > 0 {
+		check.Detail = "queries fail to compile: " + strings.Join(broken, ", ")
+		check.Fix = "symbols of these kinds are silently missing; report this as a bug"
+		return check
+	}
+
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	content := []byte(code)
+	tree, err := extractor.parse(content, langQueries)
+	if err != nil {
+		check.Detail = "sample failed to parse: " + err.Error()
+		check.Fix = "rebuild glyph; the grammar may be corrupt"
+		return check
+	}
+	if tree.RootNode().HasError() {
+		check.Detail = "sample parsed with syntax errors"
+		check.Fix = "rebuild glyph; the grammar does not match its queries"
+		return check
+	}
+
+	symbols, err := extractor.extractSymbolsFromTree(tree, content, "doctor", langQueries, Minimal)
+	if err != nil {
+		check.Detail = "extraction failed: " + err.Error()
+		return check
+	}
+	for _, sym := range symbols {
+		if sym.Name == symbol {
+			check.OK = true
+			check.Detail = fmt.Sprintf("%d queries, sample parsed in %s", len(langQueries.Queries), roundDuration(extractor.parseTime))
+			return check
+		}
+	}
+
+	check.Detail = fmt.Sprintf("symbol %q not found in sample", symbol)
+	check.Fix = "report this as a bug"
+	return check
+}
+
+// checkCache checks that the cache directory is writable and that imported indexes can be read
+func checkCache() []DoctorCheck {
+	check := DoctorCheck{Name: "cache directory"}
+	dir, err := cacheDir()
+	if err != nil {
+		check.Detail = err.Error()
+		check.Fix = "set GLYPH_CACHE_DIR to a writable directory"
+		return []DoctorCheck{check}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		check.Detail = err.Error()
+		check.Fix = "set GLYPH_CACHE_DIR to a writable directory"
+		return []DoctorCheck{check}
+	}
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		check.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+		check.Fix = "fix the permissions of " + dir + " or set GLYPH_CACHE_DIR"
+		return []DoctorCheck{check}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	check.OK = true
+	check.Detail = dir
+	checks := []DoctorCheck{check}
+
+	indexes, _ := filepath.Glob(filepath.Join(dir, "indexes", "*.glyphidx"))
+	for _, path := range indexes {
+		index := DoctorCheck{Name: "index " + filepath.Base(path)}
+		if idx, err := ReadIndex(path); err != nil {
+			index.Detail = err.Error()
+			index.Fix = "re-import the snapshot with 'glyph index import', or delete " + path
+		} else {
+			index.OK = true
+			index.Detail = fmt.Sprintf("%d files under %s", len(idx.Files), idx.Root)
+		}
+		checks = append(checks, index)
+	}
+
+	return checks
+}
+
+// checkMCPHandshake starts an MCP server over stdio, initializes a session and lists its tools
+func checkMCPHandshake(command string) DoctorCheck {
+	check := DoctorCheck{Name: "mcp handshake", Fix: "run '" + command + " mcp' by hand and check its stderr"}
+
+	c, err := client.NewStdioMCPClient(command, nil, "mcp")
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var initRequest mcp.InitializeRequest
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "glyph-doctor", Version: "1.0.0"}
+	initResult, err := c.Initialize(ctx, initRequest)
+	if err != nil {
+		check.Detail = "initialize failed: " + err.Error()
+		return check
+	}
+
+	tools, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		check.Detail = "tools/list failed: " + err.Error()
+		return check
+	}
+	for _, tool := range tools.Tools {
+		if tool.Name == "extract_symbols" {
+			check.OK = true
+			check.Fix = ""
+			check.Detail = fmt.Sprintf("%s %s, protocol %s, %d tools", initResult.ServerInfo.Name,
+				initResult.ServerInfo.Version, initResult.ProtocolVersion, len(tools.Tools))
+			return check
+		}
+	}
+
+	check.Detail = "extract_symbols tool is not registered"
+	return check
+}
+
+// FormatDoctor formats self-check results, one line per check with fixes for failures
+func FormatDoctor(checks []DoctorCheck) string {
+	var sb strings.Builder
+	failed := 0
+	for _, check := range checks {
+		mark := "ok"
+		if !check.OK {
+			mark = "FAIL"
+			failed++
+		}
+		sb.WriteString(fmt.Sprintf("[%s] %s: %s\n", mark, check.Name, check.Detail))
+		if !check.OK && check.Fix != "" {
+			sb.WriteString(fmt.Sprintf("       fix: %s\n", check.Fix))
+		}
+	}
+
+	if failed == 0 {
+		sb.WriteString(fmt.Sprintf("\nAll %d checks passed\n", len(checks)))
+	} else {
+		sb.WriteString(fmt.Sprintf("\n%d of %d checks failed\n", failed, len(checks)))
+	}
+	return sb.String()
+}
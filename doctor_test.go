@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDoctor(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("GLYPH_CACHE_DIR", cache)
+
+	checks := RunDoctor("")
+	for _, check := range checks {
+		if !check.OK {
+			t.Errorf("check %s failed: %s", check.Name, check.Detail)
+		}
+	}
+	if len(checks) != len(doctorSamples)+1 {
+		t.Errorf("expected a check per language and one for the cache, got %+v", checks)
+	}
+
+	// A corrupt imported index is reported with a fix
+	if err := os.MkdirAll(filepath.Join(cache, "indexes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cache, "indexes", "0123.glyphidx"), []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := FormatDoctor(RunDoctor(""))
+	if !strings.Contains(output, "[FAIL] index 0123.glyphidx: invalid index") || !strings.Contains(output, "fix: re-import the snapshot") {
+		t.Errorf("expected the corrupt index to be reported:\n%s", output)
+	}
+	if !strings.Contains(output, fmt.Sprintf("1 of %d checks failed", len(doctorSamples)+2)) {
+		t.Errorf("expected a summary of failed checks:\n%s", output)
+	}
+}
+
+func TestCheckLanguage_BrokenQuery(t *testing.T) {
+	broken := *goLanguageQueries
+	broken.Queries = map[string]string{"functions": "(no_such_node) @function"}
+
+	check := checkLanguage(&broken, "package main\n", "main")
+	if check.OK || !strings.Contains(check.Detail, "functions") {
+		t.Errorf("expected the broken query to be reported, got %+v", check)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// formatDOT writes symbols as a Graphviz digraph in which edges run from each file
+// to its top-level symbols and from each symbol to those declared inside it. Go
+// methods hang off their receiver type when it is declared in the same file. Files
+// are labeled relative to root.
+func formatDOT(symbols []Symbol, root string) string {
+	var files []string
+	fileSymbols := make(map[string][]Symbol)
+	for _, sym := range symbols {
+		if _, ok := fileSymbols[sym.FilePath]; !ok {
+			files = append(files, sym.FilePath)
+		}
+		fileSymbols[sym.FilePath] = append(fileSymbols[sym.FilePath], sym)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph glyph {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+
+	next := 0
+	for i, file := range files {
+		label := file
+		if root != "" {
+			if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+				label = rel
+			}
+		}
+		fileID := fmt.Sprintf("f%d", i)
+		fmt.Fprintf(&sb, "  %s [label=%s, shape=folder];\n", fileID, dotQuote(label))
+
+		nodes := BuildHierarchy(fileSymbols[file])
+		// Receiver types are looked up among the top-level symbols of the file
+		types := make(map[string]string)
+		ids := make([]string, len(nodes))
+		for j, node := range nodes {
+			ids[j] = fmt.Sprintf("s%d", next)
+			next++
+			if node.Symbol.Receiver == "" {
+				types[node.Symbol.Name] = ids[j]
+			}
+		}
+		for j, node := range nodes {
+			parent := fileID
+			if typeID, ok := types[node.Symbol.Receiver]; ok && node.Symbol.Receiver != "" {
+				parent = typeID
+			}
+			next = writeDOTNode(&sb, node, ids[j], parent, next)
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// writeDOTNode writes a symbol node, its edge from parent and its children,
+// numbering new nodes from next; it returns the next unused number
+func writeDOTNode(sb *strings.Builder, node *SymbolNode, id, parent string, next int) int {
+	fmt.Fprintf(sb, "  %s [label=%s];\n", id, dotQuote(node.Symbol.Kind+" "+node.Symbol.Name))
+	fmt.Fprintf(sb, "  %s -> %s;\n", parent, id)
+	for _, child := range node.Children {
+		childID := fmt.Sprintf("s%d", next)
+		next = writeDOTNode(sb, child, childID, id, next+1)
+	}
+	return next
+}
+
+// dotQuote returns s as a quoted DOT string
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestFormatDOT(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Widget", Kind: "class", StartLine: 3, EndLine: 9, StartColumn: 1, EndColumn: 2, FilePath: "/proj/src/widget.ts"},
+		{Name: "render", Kind: "method", StartLine: 4, EndLine: 6, StartColumn: 3, EndColumn: 4, FilePath: "/proj/src/widget.ts"},
+		{Name: "Circle", Kind: "struct", StartLine: 3, EndLine: 5, StartColumn: 1, EndColumn: 2, FilePath: "/proj/shapes.go"},
+		{Name: "Area", Kind: "method", Receiver: "Circle", StartLine: 7, EndLine: 9, StartColumn: 1, EndColumn: 2, FilePath: "/proj/shapes.go"},
+		{Name: "Perimeter", Kind: "method", Receiver: "Square", StartLine: 11, EndLine: 13, StartColumn: 1, EndColumn: 2, FilePath: "/proj/shapes.go"},
+		{Name: `say "hi"`, Kind: "func", StartLine: 1, EndLine: 2, StartColumn: 1, EndColumn: 2, FilePath: "/elsewhere/main.go"},
+	}
+
+	want := `digraph glyph {
+  rankdir=LR;
+  node [shape=box, fontname="Helvetica"];
+  f0 [label="src/widget.ts", shape=folder];
+  s0 [label="class Widget"];
+  f0 -> s0;
+  s1 [label="method render"];
+  s0 -> s1;
+  f1 [label="shapes.go", shape=folder];
+  s2 [label="struct Circle"];
+  f1 -> s2;
+  s3 [label="method Area"];
+  s2 -> s3;
+  s4 [label="method Perimeter"];
+  f1 -> s4;
+  f2 [label="/elsewhere/main.go", shape=folder];
+  s5 [label="func say \"hi\""];
+  f2 -> s5;
+}
+`
+	if got := formatDOT(symbols, "/proj"); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestElixirSymbolExtraction(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/ex_basic.ex.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	tests := []struct {
+		kind      string
+		name      string
+		signature string
+		public    bool
+	}{
+		{"module", "MyApp.Accounts", "defmodule MyApp.Accounts", true},
+		{"module", "Nested", "defmodule Nested", true},
+		{"struct", "MyApp.Accounts", "defstruct [:name, :email]", true},
+		{"callback", "fetch", "@callback fetch(id :: integer) :: {:ok, term} | :error", true},
+		{"callback", "store", "@callback store(term) :: :ok", true},
+		{"func", "get", "def get(id)", true},
+		{"func", "list", `def list(opts \\ []) when is_list(opts)`, true},
+		{"func", "secret", "defp secret(a, b)", false},
+		{"func", "ping", "def ping", true},
+		{"macro", "debug", "defmacro debug(expr)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind+" "+tt.name, func(t *testing.T) {
+			for _, sym := range symbols {
+				if sym.Kind != tt.kind || sym.Name != tt.name {
+					continue
+				}
+				if sym.Signature != tt.signature {
+					t.Errorf("Signature = %q, want %q", sym.Signature, tt.signature)
+				}
+				if sym.Public != tt.public {
+					t.Errorf("Public = %v, want %v", sym.Public, tt.public)
+				}
+				return
+			}
+			t.Errorf("no %s %s extracted", tt.kind, tt.name)
+		})
+	}
+
+	if len(symbols) != len(tests) {
+		t.Errorf("expected %d symbols, got %d: %+v", len(tests), len(symbols), symbols)
+	}
+
+	result := FormatSymbols(symbols, Minimal)
+	if !strings.Contains(result, "  - module: Nested (line 23)\n    - func: ping") {
+		t.Errorf("expected nested modules and their functions to be nested:\n%s", result)
+	}
+}
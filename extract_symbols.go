@@ -1,37 +1,645 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// lineRangeSuffix matches a trailing ":start-end" or ":line" on a file path
+var lineRangeSuffix = regexp.MustCompile(`:(\d+)(?:-(\d+))?$`)
+
+// SplitLineRange separates a "path:120-340" or "path:120" suffix from a pattern
+func SplitLineRange(pattern string) (string, LineRange, bool) {
+	match := lineRangeSuffix.FindStringSubmatchIndex(pattern)
+	if match == nil {
+		return pattern, LineRange{}, false
+	}
+
+	start, _ := strconv.ParseUint(pattern[match[2]:match[3]], 10, 32)
+	end := start
+	if match[4] >= 0 {
+		end, _ = strconv.ParseUint(pattern[match[4]:match[5]], 10, 32)
+	}
+
+	return pattern[:match[0]], LineRange{Start: uint32(start), End: uint32(end)}, true
+}
+
 // ExtractSymbols extracts symbols from files matching a pattern
-func ExtractSymbols(pattern string, detail string) (string, error) {
-	detailLevel := ParseDetailLevel(detail)
+func ExtractSymbols(pattern string, opts ExtractOptions) (string, error) {
+	result, err := ExtractSymbolsResult(pattern, opts)
+	if err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// ExtractSymbolsResult extracts symbols from files matching a pattern, reporting
+// the outcome and any files that were skipped alongside the formatted output
+func ExtractSymbolsResult(pattern string, opts ExtractOptions) (*ExtractionResult, error) {
+	return ExtractSymbolsContext(context.Background(), pattern, opts)
+}
+
+// ExtractSymbolsContext is ExtractSymbolsResult, stopping with the context's error
+// once it is cancelled, or with a LimitError once it exceeds opts.Limits
+func ExtractSymbolsContext(ctx context.Context, pattern string, opts ExtractOptions) (*ExtractionResult, error) {
+	detailLevel := opts.Detail
+
+	if path, lines, ok := SplitLineRange(pattern); ok && !opts.Lines.IsSet() {
+		pattern, opts.Lines = path, lines
+	}
+	if opts.Lines.Start > 0 && opts.Lines.End > 0 && opts.Lines.End < opts.Lines.Start {
+		return nil, fmt.Errorf("invalid line range %d-%d", opts.Lines.Start, opts.Lines.End)
+	}
 
 	// Find files matching the pattern
-	files, err := FindFiles(pattern)
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
 	if err != nil {
-		return "", fmt.Errorf("failed to find files: %w", err)
+		return nil, fmt.Errorf("failed to find files: %w", err)
 	}
 
 	if len(files) == 0 {
-		return "No files found matching pattern: " + pattern, nil
+		result := noFilesResult(pattern)
+		if isStructuredFormat(opts.Format) {
+			if err := result.setFormatOutput(opts.Format, "", nil, nil, nil); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	// Only the files of the requested page are extracted, and count towards the limits
+	result := &ExtractionResult{Status: StatusOK, Files: len(files), log: opts.Log}
+	files, result.NextCursor, err = paginate(files, opts.Page)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Limits.check(len(files), 0); err != nil {
+		return nil, err
 	}
 
 	var allSymbols []Symbol
-	extractor := NewSymbolExtractor()
+	var parsedBytes int64
 
-	for _, file := range files {
-		symbols, err := extractor.ExtractFromFile(file, detailLevel)
-		if err != nil {
+	// An imported index snapshot lets unchanged files skip parsing
+	index := FindLocalIndex(PatternBaseDir(pattern))
+	if index != nil && index.Detail != detailLevel.String() {
+		index = nil
+	}
+
+	if opts.Query != "" {
+		index = nil
+	}
+	outcomes, stop := extractConcurrently(ctx, files, opts, func(extractor *SymbolExtractor, file string) fileOutcome {
+		return extractOutcome(extractor, index, opts, file, detailLevel)
+	})
+	defer stop()
+
+	// queryErr is reported when a custom query fails for every file
+	var queryErr error
+	queried := false
+
+	metadata := make(map[string]*FileMetadata)
+	// The source maps of generated files are found from the content already read
+	sourceMaps := make(map[string]*SourceMap)
+
+	// Files are extracted by several workers at once, but their outcomes are taken
+	// in order, so the output, progress, logs and limits don't depend on the workers
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(i, len(files))
+		}
+
+		var outcome fileOutcome
+		select {
+		case outcome = <-outcomes[i]:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if opts.Log != nil {
+			for _, event := range outcome.events {
+				opts.Log(event)
+			}
+		}
+
+		parsedBytes += int64(len(outcome.content))
+		if err := opts.Limits.check(len(files), parsedBytes); err != nil {
+			return nil, err
+		}
+		if opts.SourceMaps && outcome.err == nil && isGeneratedJavaScript(file) {
+			if m := sourceMapFor(file, outcome.content, opts.Discovery.Roots); m != nil {
+				sourceMaps[file] = m
+			}
+		}
+		if outcome.indexed {
+			metadata[file] = newFileMetadata(file, outcome.content, outcome.symbols)
+			allSymbols = append(allSymbols, outcome.symbols...)
+			continue
+		}
+		if outcome.err != nil {
+			if queryErr == nil {
+				queryErr = outcome.err
+			}
+			result.warnSkipped(file, outcome.err)
 			continue // Skip files that can't be parsed
 		}
-		allSymbols = append(allSymbols, symbols...)
+		if opts.DebugTimings {
+			result.Timings = append(result.Timings, FileTiming{
+				FilePath: file,
+				Parse:    outcome.parseTime,
+				Query:    outcome.queryTime,
+				Symbols:  len(outcome.symbols),
+			})
+		}
+		queried = true
+		metadata[file] = newFileMetadata(file, outcome.content, outcome.symbols)
+		metadata[file].ParseErrors = outcome.parseErrors
+		allSymbols = append(allSymbols, outcome.symbols...)
+	}
+
+	if opts.Progress != nil {
+		opts.Progress(len(files), len(files))
+	}
+
+	if opts.Query != "" && !queried && queryErr != nil {
+		return nil, queryErr
+	}
+
+	return formatSymbolsResult(result, ProjectRoot(PatternBaseDir(pattern)), allSymbols, metadata, sourceMaps, opts)
+}
+
+// fileOutcome is what extracting one file produced
+type fileOutcome struct {
+	symbols     []Symbol
+	content     []byte
+	parseErrors []LineRange
+	parseTime   time.Duration
+	queryTime   time.Duration
+	// indexed is set when the symbols came from the index snapshot
+	indexed bool
+	// refs are the references to a name in the file, when they are searched for
+	refs []Reference
+	// literals are the string literals of the file, in strings mode
+	literals []StringLiteral
+	// events are logged once the file's outcome is taken
+	events []LogEvent
+	err    error
+}
+
+// extractConcurrently starts opts.Jobs workers, each with its own extractor, that
+// run extract on the files in order and deliver each file's outcome on its channel.
+// Stop ends the workers and waits for them once the outcomes are no longer needed.
+func extractConcurrently(ctx context.Context, files []string, opts ExtractOptions, extract func(extractor *SymbolExtractor, file string) fileOutcome) ([]chan fileOutcome, func()) {
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	jobs = min(jobs, len(files))
+
+	ctx, cancel := context.WithCancel(ctx)
+	outcomes := make([]chan fileOutcome, len(files))
+	for i := range outcomes {
+		outcomes[i] = make(chan fileOutcome, 1)
+	}
+	next := make(chan int)
+	go func() {
+		defer close(next)
+		for i := range files {
+			select {
+			case next <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var extractor *SymbolExtractor
+			if opts.Query != "" {
+				extractor = NewQuerySymbolExtractor(opts.Query)
+			} else {
+				extractor = NewSymbolExtractor()
+			}
+			defer extractor.Close()
+			for i := range next {
+				outcomes[i] <- extract(extractor, files[i])
+			}
+		}()
+	}
+	return outcomes, func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// extractOutcome extracts the symbols of one file, from the index snapshot when it
+// has the file's content, collecting the events to log rather than logging them
+func extractOutcome(extractor *SymbolExtractor, index *Index, opts ExtractOptions, file string, detail DetailLevel) fileOutcome {
+	if index != nil {
+		if content, err := ReadFile(file); err == nil {
+			if symbols, ok := index.Lookup(file, content); ok {
+				return fileOutcome{symbols: symbols, content: content, indexed: true}
+			}
+		}
+	}
+
+	var outcome fileOutcome
+	if opts.Log != nil {
+		opts.Log = func(event LogEvent) { outcome.events = append(outcome.events, event) }
+	}
+	extractor.parseTime, extractor.queryTime, extractor.content, extractor.parseErrors = 0, 0, nil, nil
+	outcome.symbols, outcome.err = extractFile(extractor, opts, file, detail)
+	outcome.content, outcome.parseErrors = extractor.content, extractor.parseErrors
+	outcome.parseTime, outcome.queryTime = extractor.parseTime, extractor.queryTime
+	return outcome
+}
+
+// ExtractContentContext extracts symbols from source code that is not read from disk,
+// such as an unsaved editor buffer. The code is reported as the file path, which
+// only names it, and is parsed as language, or as the language of path's extension
+// when language is empty. An empty path is reported as "<content>".
+func ExtractContentContext(ctx context.Context, path string, content []byte, language string, opts ExtractOptions) (*ExtractionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		path = "<content>"
+	}
+	if opts.Lines.Start > 0 && opts.Lines.End > 0 && opts.Lines.End < opts.Lines.Start {
+		return nil, fmt.Errorf("invalid line range %d-%d", opts.Lines.Start, opts.Lines.End)
+	}
+	if err := opts.Limits.check(1, int64(len(content))); err != nil {
+		return nil, err
+	}
+
+	langQueries := GetLanguageQueriesForName(language)
+	if language == "" {
+		langQueries = GetLanguageQueriesForFile(path)
+	}
+	if langQueries == nil {
+		if language == "" {
+			return nil, fmt.Errorf("language is required when the path has no supported extension: %s", path)
+		}
+		return nil, fmt.Errorf("unsupported language: %s", language)
+	}
+
+	var extractor *SymbolExtractor
+	if opts.Query != "" {
+		extractor = NewQuerySymbolExtractor(opts.Query)
+	} else {
+		extractor = NewSymbolExtractor()
+	}
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromContent(path, content, langQueries, opts.Detail)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExtractionResult{Status: StatusOK, Files: 1}
+	if opts.DebugTimings {
+		result.Timings = []FileTiming{{
+			FilePath: path,
+			Parse:    extractor.parseTime,
+			Query:    extractor.queryTime,
+			Symbols:  len(symbols),
+		}}
+	}
+
+	meta := newFileMetadata(path, content, symbols)
+	meta.Language = langQueries.Name
+	meta.ParseErrors = extractor.parseErrors
+	metadata := map[string]*FileMetadata{path: meta}
+	logParseErrors(opts.Log, path, extractor.parseErrors)
+
+	root := ""
+	if filepath.IsAbs(path) {
+		root = ProjectRoot(filepath.Dir(path))
+	}
+	var sourceMaps map[string]*SourceMap
+	if opts.SourceMaps && isGeneratedJavaScript(path) {
+		if m := sourceMapFor(path, content, opts.Discovery.Roots); m != nil {
+			sourceMaps = map[string]*SourceMap{path: m}
+		}
+	}
+	return formatSymbolsResult(result, root, symbols, metadata, sourceMaps, opts)
+}
+
+// formatSymbolsResult filters the extracted symbols as selected by opts and formats
+// them into the result, mapping those of generated files with sourceMaps
+func formatSymbolsResult(result *ExtractionResult, root string, allSymbols []Symbol, metadata map[string]*FileMetadata, sourceMaps map[string]*SourceMap, opts ExtractOptions) (*ExtractionResult, error) {
+	detailLevel := opts.Detail
+
+	// IDs are assigned before filtering so they don't depend on which symbols are kept
+	AssignSymbolIDs(allSymbols, root)
+
+	var err error
+	if opts.Lines.IsSet() {
+		allSymbols = filterLineRange(allSymbols, opts.Lines)
+	}
+
+	if opts.PublicOnly {
+		allSymbols = filterPublicSymbols(allSymbols)
+	}
+
+	if opts.MinLines > 1 {
+		allSymbols = filterMinLines(allSymbols, opts.MinLines)
+	}
+
+	if opts.AnnotatedWith != "" {
+		allSymbols = filterAnnotated(allSymbols, opts.AnnotatedWith)
+	}
+
+	allSymbols, err = filterDeprecated(allSymbols, opts.Deprecated)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Receiver != "" {
+		allSymbols = filterReceiver(allSymbols, opts.Receiver)
+	}
+
+	var ranked []RankedSymbol
+	if opts.Name != "" {
+		if opts.NameMatch == NameMatchFuzzy {
+			ranked = RankByFuzzyName(allSymbols, opts.Name)
+			allSymbols = allSymbols[:0]
+			for _, r := range ranked {
+				allSymbols = append(allSymbols, r.Symbol)
+			}
+		} else {
+			allSymbols, err = filterByName(allSymbols, opts.Name, opts.NameMatch)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if len(allSymbols) == 0 {
-		return "No symbols found", nil
+		result.Status = StatusNoSymbols
+		result.Output = "No symbols found"
+		if opts.Structured {
+			result.Structured = result.newJSONOutline(nil, nil, nil)
+		}
+		if isStructuredFormat(opts.Format) {
+			if err := result.setFormatOutput(opts.Format, root, nil, nil, nil); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	result.Symbols = len(allSymbols)
+
+	if opts.SourceMaps {
+		ApplySourceMaps(allSymbols, sourceMaps, metadata, opts.Discovery.Roots)
 	}
 
-	return FormatSymbols(allSymbols, detailLevel), nil
+	if opts.Coverage != "" {
+		profile, err := LoadCoverageProfile(opts.Coverage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load coverage: %w", err)
+		}
+		profile.Annotate(allSymbols)
+	}
+
+	if ranked != nil {
+		for i := range ranked {
+			ranked[i].Symbol = allSymbols[i]
+		}
+	}
+
+	scores := make(map[string]int)
+	for _, r := range ranked {
+		scores[r.Symbol.ID] = r.Score
+	}
+	// The document lists every selected symbol, as the json format does, before
+	// the outline display options apply
+	if opts.Structured {
+		result.Structured = result.newJSONOutline(allSymbols, metadata, scores)
+	}
+
+	if isStructuredFormat(opts.Format) {
+		if err := result.setFormatOutput(opts.Format, root, allSymbols, metadata, scores); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if ranked != nil {
+		result.Output = FormatRankedSymbols(ranked, opts.Name)
+		return result, nil
+	}
+
+	result.Output = FormatSymbolsWithOptions(allSymbols, FormatOptions{
+		Detail:             detailLevel,
+		GroupByPackage:     opts.GroupByPackage,
+		Depth:              opts.Depth,
+		MaxSymbolsPerFile:  opts.MaxSymbolsPerFile,
+		MaxSignatureLength: opts.MaxSignatureLength,
+		Files:              metadata,
+		Summarize:          opts.Summarize,
+	})
+	return result, nil
+}
+
+// ExtractStrings extracts notable string literals from files matching a pattern
+func ExtractStrings(pattern string) (string, error) {
+	result, err := ExtractStringsResult(pattern)
+	if err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// ExtractStringsResult extracts notable string literals from files matching a pattern,
+// reporting the outcome alongside the formatted output
+func ExtractStringsResult(pattern string) (*ExtractionResult, error) {
+	return ExtractStringsContext(context.Background(), pattern, ExtractOptions{})
+}
+
+// ExtractStringsContext is ExtractStringsResult, finding files as opts.Discovery
+// selects and stopping with the context's error once it is cancelled, or with a
+// LimitError once it exceeds opts.Limits
+func ExtractStringsContext(ctx context.Context, pattern string, opts ExtractOptions) (*ExtractionResult, error) {
+	limits := opts.Limits
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files: %w", err)
+	}
+
+	if len(files) == 0 {
+		return noFilesResult(pattern), nil
+	}
+	if err := limits.check(len(files), 0); err != nil {
+		return nil, err
+	}
+
+	result := &ExtractionResult{Status: StatusOK, Files: len(files)}
+
+	opts.Query = ""
+	outcomes, stop := extractConcurrently(ctx, files, opts, func(extractor *SymbolExtractor, file string) fileOutcome {
+		extractor.content = nil
+		literals, err := extractor.ExtractStringsFromFile(file)
+		return fileOutcome{literals: literals, content: extractor.content, err: err}
+	})
+	defer stop()
+
+	var allLiterals []StringLiteral
+	var parsedBytes int64
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var outcome fileOutcome
+		select {
+		case outcome = <-outcomes[i]:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		parsedBytes += int64(len(outcome.content))
+		if err := limits.check(len(files), parsedBytes); err != nil {
+			return nil, err
+		}
+		if outcome.err != nil {
+			result.warnSkipped(file, outcome.err)
+			continue // Skip files that can't be parsed
+		}
+		allLiterals = append(allLiterals, outcome.literals...)
+	}
+
+	if len(allLiterals) == 0 {
+		result.Status = StatusNoSymbols
+		result.Output = "No string literals found"
+		return result, nil
+	}
+
+	result.Symbols = len(allLiterals)
+	result.Output = FormatStringLiterals(allLiterals)
+	return result, nil
+}
+
+// newFileMetadata describes a file from its content and the symbols extracted from it
+func newFileMetadata(file string, content []byte, symbols []Symbol) *FileMetadata {
+	meta := &FileMetadata{
+		Lines: bytes.Count(content, []byte("\n")),
+		Hash:  hashContent(content),
+	}
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		meta.Lines++
+	}
+
+	if IsNotebookFile(file) {
+		meta.Language = "jupyter"
+	} else if langQueries := GetLanguageQueriesForFile(file); langQueries != nil {
+		meta.Language = langQueries.Name
+	}
+
+	for _, sym := range symbols {
+		if sym.Kind == "package" || (sym.Kind == "module" && meta.Language == "python") {
+			meta.Package = sym.Name
+			break
+		}
+	}
+
+	return meta
+}
+
+// noFilesResult is the result of a pattern that matched no files
+func noFilesResult(pattern string) *ExtractionResult {
+	return &ExtractionResult{
+		Status: StatusNoFiles,
+		Output: "No files found matching pattern: " + pattern,
+	}
+}
+
+// warnSkipped records a file of a supported language that could not be extracted.
+// Files of unsupported languages are skipped silently, since broad patterns match many,
+// and files that kept changing while being read are flagged as unstable.
+func (r *ExtractionResult) warnSkipped(file string, err error) {
+	if GetLanguageQueriesForFile(file) == nil && !IsNotebookFile(file) {
+		return
+	}
+	event := LogEvent{Level: LogWarning, Event: LogFileSkipped, File: file, Message: err.Error()}
+	if errors.Is(err, ErrFileUnstable) {
+		event.Event, event.Message = LogFileUnstable, ErrFileUnstable.Error()
+		r.Warnings = append(r.Warnings, fmt.Sprintf("unstable %s: %v", file, ErrFileUnstable))
+	} else {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("skipped %s: %v", file, err))
+	}
+	if r.log != nil {
+		r.log(event)
+	}
+}
+
+// filterLineRange keeps only symbols intersecting a line range
+func filterLineRange(symbols []Symbol, lines LineRange) []Symbol {
+	var inRange []Symbol
+	for _, sym := range symbols {
+		if lines.Intersects(sym) {
+			inRange = append(inRange, sym)
+		}
+	}
+	return inRange
+}
+
+// filterPublicSymbols keeps only symbols that are exported or public
+func filterPublicSymbols(symbols []Symbol) []Symbol {
+	var public []Symbol
+	for _, sym := range symbols {
+		if sym.Public {
+			public = append(public, sym)
+		}
+	}
+	return public
+}
+
+// filterMinLines keeps only symbols spanning at least minLines lines
+func filterMinLines(symbols []Symbol, minLines int) []Symbol {
+	var large []Symbol
+	for _, sym := range symbols {
+		if int(sym.EndLine)-int(sym.StartLine)+1 >= minLines {
+			large = append(large, sym)
+		}
+	}
+	return large
+}
+
+// filterAnnotated keeps only symbols carrying an annotation or decorator
+func filterAnnotated(symbols []Symbol, annotation string) []Symbol {
+	var annotated []Symbol
+	for _, sym := range symbols {
+		if hasAnnotation(sym, annotation) {
+			annotated = append(annotated, sym)
+		}
+	}
+	return annotated
+}
+
+// filterReceiver keeps only methods whose receiver is the given type
+func filterReceiver(symbols []Symbol, receiver string) []Symbol {
+	receiver = strings.TrimPrefix(receiver, "*")
+
+	var methods []Symbol
+	for _, sym := range symbols {
+		if sym.Receiver == receiver {
+			methods = append(methods, sym)
+		}
+	}
+	return methods
 }
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSplitLineRange(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		wantPattern string
+		wantLines   LineRange
+		wantOK      bool
+	}{
+		{"/src/server.go:120-340", "/src/server.go", LineRange{Start: 120, End: 340}, true},
+		{"/src/server.go:42", "/src/server.go", LineRange{Start: 42, End: 42}, true},
+		{"/src/**/*.go", "/src/**/*.go", LineRange{}, false},
+		{"/src/server.go:abc", "/src/server.go:abc", LineRange{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			pattern, lines, ok := SplitLineRange(tt.pattern)
+			if pattern != tt.wantPattern || lines != tt.wantLines || ok != tt.wantOK {
+				t.Errorf("SplitLineRange(%q) = %q, %+v, %v; want %q, %+v, %v",
+					tt.pattern, pattern, lines, ok, tt.wantPattern, tt.wantLines, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestExtractSymbols_LineRange(t *testing.T) {
+	testFile, err := filepath.Abs("testdata/go_basic.go.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		pattern     string
+		lines       LineRange
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "path suffix",
+			pattern:     testFile + ":54-70",
+			contains:    []string{"func: main", "func: NewServer", "func: processRequest"},
+			notContains: []string{"const: Version", "method: Start"},
+		},
+		{
+			name:        "options",
+			pattern:     testFile,
+			lines:       LineRange{Start: 82},
+			contains:    []string{"method: Start", "struct: Response"},
+			notContains: []string{"func: main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExtractSymbols(tt.pattern, ExtractOptions{Detail: Minimal, Lines: tt.lines})
+			if err != nil {
+				t.Fatalf("ExtractSymbols error = %v", err)
+			}
+
+			for _, expected := range tt.contains {
+				if !strings.Contains(result, expected) {
+					t.Errorf("expected %q in output:\n%s", expected, result)
+				}
+			}
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(result, unexpected) {
+					t.Errorf("did not expect %q in output:\n%s", unexpected, result)
+				}
+			}
+		})
+	}
+
+	if _, err := ExtractSymbols(testFile+":20-10", ExtractOptions{Detail: Minimal}); err == nil {
+		t.Errorf("expected an error for a reversed line range")
+	}
+}
+
+func TestExtractSymbols_MinLines(t *testing.T) {
+	testFile, err := filepath.Abs("testdata/java_basic_class.java.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExtractSymbols(testFile, ExtractOptions{Detail: Minimal, MinLines: 3})
+	if err != nil {
+		t.Fatalf("ExtractSymbols error = %v", err)
+	}
+
+	if !strings.Contains(result, "class: BasicExample") || !strings.Contains(result, "method: addItem") {
+		t.Errorf("expected multi-line symbols in output:\n%s", result)
+	}
+	if strings.Contains(result, "field:") {
+		t.Errorf("expected one-line fields to be omitted:\n%s", result)
+	}
+}
+
+func TestExtractSymbolsResult_Status(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "lib.go"), []byte("package lib\n\nfunc Run() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "broken.ipynb"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "empty.js"), []byte("// nothing here\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pattern  string
+		status   string
+		symbols  int
+		warnings int
+	}{
+		{filepath.Join(tempDir, "*.rs"), StatusNoFiles, 0, 0},
+		{filepath.Join(tempDir, "*.js"), StatusNoSymbols, 0, 0},
+		{filepath.Join(tempDir, "*.go"), StatusOK, 2, 0},
+		// Unsupported files are skipped silently, broken supported ones with a warning
+		{filepath.Join(tempDir, "*"), StatusOK, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.pattern), func(t *testing.T) {
+			result, err := ExtractSymbolsResult(tt.pattern, ExtractOptions{Detail: Minimal})
+			if err != nil {
+				t.Fatalf("ExtractSymbolsResult error = %v", err)
+			}
+			if result.Status != tt.status || result.Symbols != tt.symbols || len(result.Warnings) != tt.warnings {
+				t.Errorf("ExtractSymbolsResult(%q) = status %s, %d symbols, warnings %v; want %s, %d symbols, %d warnings",
+					tt.pattern, result.Status, result.Symbols, result.Warnings, tt.status, tt.symbols, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestExtractSymbolsResult_DebugTimings(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "a.go"), []byte("package a\n\nfunc A() {}\n\nfunc B() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "b.py"), []byte("def c():\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pattern := filepath.Join(tempDir, "*")
+	result, err := ExtractSymbolsResult(pattern, ExtractOptions{Detail: Minimal})
+	if err != nil {
+		t.Fatalf("ExtractSymbolsResult error = %v", err)
+	}
+	if len(result.Timings) != 0 {
+		t.Errorf("expected no timings unless requested, got %v", result.Timings)
+	}
+
+	result, err = ExtractSymbolsResult(pattern, ExtractOptions{Detail: Minimal, DebugTimings: true})
+	if err != nil {
+		t.Fatalf("ExtractSymbolsResult error = %v", err)
+	}
+	if len(result.Timings) != 2 {
+		t.Fatalf("expected a timing per file, got %v", result.Timings)
+	}
+	symbols := map[string]int{}
+	for _, timing := range result.Timings {
+		if timing.Parse <= 0 || timing.Query <= 0 {
+			t.Errorf("expected parse and query times for %s, got %v", timing.FilePath, timing)
+		}
+		symbols[filepath.Base(timing.FilePath)] = timing.Symbols
+	}
+	if symbols["a.go"] != 3 || symbols["b.py"] != 2 {
+		t.Errorf("expected per-file symbol counts, got %v", symbols)
+	}
+}
+
+func TestExtractSymbolsResult_Concurrent(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"a.go":   "package a\n\nfunc A() {}\n\ntype Server struct{}\n",
+		"b.py":   "class B:\n    def run(self):\n        pass\n",
+		"c.ts":   "export interface C {\n  id: string;\n}\n",
+		"D.java": "public class D {\n  public void d() {}\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{}
+	for name := range files {
+		result, err := ExtractSymbolsResult(filepath.Join(tempDir, name), ExtractOptions{Detail: Standard})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[name] = result.Output
+	}
+
+	// Extractions of different languages running at once must not share a parser
+	var wg sync.WaitGroup
+	errs := make(chan string, 100)
+	for i := 0; i < 25; i++ {
+		for name := range files {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				result, err := ExtractSymbolsResult(filepath.Join(tempDir, name), ExtractOptions{Detail: Standard})
+				if err != nil {
+					errs <- err.Error()
+				} else if result.Output != want[name] {
+					errs <- fmt.Sprintf("%s: got\n%s\nwant\n%s", name, result.Output, want[name])
+				}
+			}(name)
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestExtractSymbolsResult_Jobs(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < 40; i++ {
+		code := fmt.Sprintf("package lib\n\nfunc Run%d() {}\n\ntype Server%d struct{}\n", i, i)
+		if i%7 == 0 {
+			code = fmt.Sprintf("package lib\n\nfunc Broken%d( {\n}\n", i)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("f%02d.go", i)), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pattern := filepath.Join(tempDir, "*.go")
+
+	extract := func(jobs int, cache *SymbolCache) (string, []LogEvent, []int) {
+		t.Helper()
+		var events []LogEvent
+		var progress []int
+		result, err := ExtractSymbolsResult(pattern, ExtractOptions{
+			Detail:   Standard,
+			Jobs:     jobs,
+			Cache:    cache,
+			Log:      func(event LogEvent) { events = append(events, event) },
+			Progress: func(done, total int) { progress = append(progress, done) },
+		})
+		if err != nil {
+			t.Fatalf("jobs %d: %v", jobs, err)
+		}
+		return result.Output, events, progress
+	}
+
+	// Files extracted by several workers are reported as they are by one
+	want, wantEvents, wantProgress := extract(1, nil)
+	for _, jobs := range []int{4, 64} {
+		got, events, progress := extract(jobs, nil)
+		if got != want {
+			t.Errorf("jobs %d: got\n%s\nwant\n%s", jobs, got, want)
+		}
+		if fmt.Sprint(events) != fmt.Sprint(wantEvents) || fmt.Sprint(progress) != fmt.Sprint(wantProgress) {
+			t.Errorf("jobs %d: events %v, progress %v; want %v, %v", jobs, events, progress, wantEvents, wantProgress)
+		}
+	}
+
+	cache := NewSymbolCache(100)
+	extract(4, cache)
+	if got, _, _ := extract(4, cache); got != want {
+		t.Errorf("cached: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExtractStringsContext_Jobs(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < 40; i++ {
+		code := fmt.Sprintf("package lib\n\nconst home%d = \"https://example.com/%d\"\n\nfunc Query%d() string {\n\treturn \"SELECT id FROM t%d\"\n}\n", i, i, i, i)
+		if err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("f%02d.go", i)), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pattern := filepath.Join(tempDir, "*.go")
+
+	want, err := ExtractStringsContext(context.Background(), pattern, ExtractOptions{Jobs: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.Symbols != 80 {
+		t.Fatalf("Symbols = %d, want 80", want.Symbols)
+	}
+
+	// Literals extracted by several workers come back in the order of their files
+	for _, jobs := range []int{4, 64} {
+		got, err := ExtractStringsContext(context.Background(), pattern, ExtractOptions{Jobs: jobs})
+		if err != nil {
+			t.Fatalf("jobs %d: %v", jobs, err)
+		}
+		if got.Output != want.Output || got.Symbols != want.Symbols {
+			t.Errorf("jobs %d: got\n%s\nwant\n%s", jobs, got.Output, want.Output)
+		}
+	}
+	first := strings.Index(want.Output, "https://example.com/0")
+	last := strings.Index(want.Output, "https://example.com/39")
+	if first < 0 || last < 0 || first > last {
+		t.Errorf("Expected the literals of f00.go before those of f39.go:\n%s", want.Output)
+	}
+}
+
+func TestExtractSymbolsResult_FileMetadata(t *testing.T) {
+	tempDir := t.TempDir()
+	code := "package server\n\nfunc Run() {}\n\nfunc stop() {}"
+	if err := os.WriteFile(filepath.Join(tempDir, "server.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExtractSymbolsResult(filepath.Join(tempDir, "*.go"), ExtractOptions{Detail: Minimal, PublicOnly: true})
+	if err != nil {
+		t.Fatalf("ExtractSymbolsResult error = %v", err)
+	}
+
+	// The symbol count describes the symbols listed, after filtering
+	want := "go · package server · 5 lines · 2 symbols · sha256:" + hashContent([]byte(code)) + "\n"
+	if !strings.Contains(result.Output, "server.go\n\n"+want) {
+		t.Errorf("expected a metadata line under the file heading:\n%s", result.Output)
+	}
+}
+
+func TestExtractSymbolsResult_ParseErrors(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"broken.go": "package main\n\nfunc ok() {}\n\nfunc broken( {\n\tx := \n}\n\nfunc after() {}\n",
+		"clean.go":  "package main\n\nfunc fine() {}\n",
+	}
+	for name, code := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := ExtractSymbolsResult(filepath.Join(tempDir, "*.go"), ExtractOptions{Detail: Minimal})
+	if err != nil {
+		t.Fatalf("ExtractSymbolsResult error = %v", err)
+	}
+
+	if strings.Count(result.Output, "Syntax errors") != 1 || !strings.Contains(result.Output, "Syntax errors at lines 5-6;") {
+		t.Errorf("expected the error lines of broken.go only:\n%s", result.Output)
+	}
+	if !strings.Contains(result.Output, "func: after (line 9)") {
+		t.Errorf("expected symbols after the error to be kept:\n%s", result.Output)
+	}
+}
+
+func TestExtractSymbolsResult_Limits(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("package lib\n\nfunc Run() {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pattern := filepath.Join(tempDir, "*.go")
+
+	tests := []struct {
+		limits ExtractLimits
+		limit  string
+	}{
+		{ExtractLimits{MaxFiles: 3, MaxBytes: 1000}, ""},
+		{ExtractLimits{MaxFiles: 2}, "max_files"},
+		{ExtractLimits{MaxBytes: 60}, "max_bytes"},
+	}
+
+	for _, tt := range tests {
+		_, err := ExtractSymbolsResult(pattern, ExtractOptions{Detail: Minimal, Limits: tt.limits})
+		var limitErr *LimitError
+		if tt.limit == "" {
+			if err != nil {
+				t.Errorf("limits %+v: unexpected error %v", tt.limits, err)
+			}
+			continue
+		}
+		if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+			t.Errorf("limits %+v: expected a %s LimitError, got %v", tt.limits, tt.limit, err)
+		}
+	}
+
+	if _, err := ExtractStringsContext(context.Background(), pattern, ExtractOptions{Limits: ExtractLimits{MaxFiles: 1}}); err == nil {
+		t.Errorf("expected strings mode to enforce limits")
+	}
+}
+
+func TestExtractContentContext(t *testing.T) {
+	content := []byte("export class Buffer {\n  save(): void {}\n}\n")
+
+	tests := []struct {
+		name     string
+		path     string
+		language string
+		heading  string
+	}{
+		{"language", "", "ts", "## <content>"},
+		{"path extension", "/work/draft.ts", "", "## /work/draft.ts"},
+		{"language overrides extension", "/work/draft.txt", "typescript", "## /work/draft.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExtractContentContext(context.Background(), tt.path, content, tt.language, ExtractOptions{Detail: Minimal})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != StatusOK || result.Files != 1 || result.Symbols != 2 {
+				t.Errorf("unexpected result %+v", result)
+			}
+			for _, want := range []string{tt.heading, "typescript ·", "class: Buffer", "method: save"} {
+				if !strings.Contains(result.Output, want) {
+					t.Errorf("output missing %q:\n%s", want, result.Output)
+				}
+			}
+		})
+	}
+
+	if _, err := ExtractContentContext(context.Background(), "", content, "cobol", ExtractOptions{}); err == nil {
+		t.Error("expected an error for an unsupported language")
+	}
+	if _, err := ExtractContentContext(context.Background(), "/work/draft", content, "", ExtractOptions{}); err == nil {
+		t.Error("expected an error without a language or a known extension")
+	}
+}
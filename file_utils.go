@@ -1,22 +1,115 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/user"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/bash"
+	"github.com/smacker/go-tree-sitter/csharp"
+	"github.com/smacker/go-tree-sitter/css"
+	"github.com/smacker/go-tree-sitter/elixir"
 	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/hcl"
+	"github.com/smacker/go-tree-sitter/html"
 	"github.com/smacker/go-tree-sitter/java"
 	"github.com/smacker/go-tree-sitter/javascript"
+	markdown "github.com/smacker/go-tree-sitter/markdown/tree-sitter-markdown"
+	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/rust"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
+	"github.com/smacker/go-tree-sitter/yaml"
 )
 
-// ReadFile reads the content of a file
+// ErrFileUnstable is returned for files that keep changing while they are read
+var ErrFileUnstable = errors.New("file changed while being read")
+
+// ReadFile reads the content of a file. A file whose size or modification time
+// changes during the read is re-read once, and reported as ErrFileUnstable if it
+// changes again, since its symbols would have the wrong line numbers.
 func ReadFile(filePath string) ([]byte, error) {
-	return os.ReadFile(filePath)
+	content, _, err := readStableFile(filePath, os.ReadFile)
+	return content, err
+}
+
+// readStableFile reads a file with read, checking its size and modification time
+// around each attempt, and returns the file's info as of the read
+func readStableFile(filePath string, read func(string) ([]byte, error)) ([]byte, os.FileInfo, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		before, err := os.Stat(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		content, err := read(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		after, err := os.Stat(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if after.Size() == before.Size() && after.ModTime().Equal(before.ModTime()) && int64(len(content)) == after.Size() {
+			return content, after, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%s: %w", filePath, ErrFileUnstable)
+}
+
+// envVarPattern matches $VAR and ${VAR} references in a path
+var envVarPattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// ExpandPath expands a leading ~ or ~user to a home directory and $VAR or ${VAR}
+// to the value of an environment variable. Undefined variables are an error rather
+// than silently expanding to an empty string.
+func ExpandPath(path string) (string, error) {
+	var undefined string
+	path = envVarPattern.ReplaceAllStringFunc(path, func(ref string) string {
+		parts := envVarPattern.FindStringSubmatch(ref)
+		name := parts[1] + parts[2]
+		value, ok := os.LookupEnv(name)
+		if !ok && undefined == "" {
+			undefined = name
+		}
+		return value
+	})
+	if undefined != "" {
+		return "", fmt.Errorf("environment variable %s is not set", undefined)
+	}
+
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+
+	name, rest, _ := strings.Cut(path[1:], "/")
+	var home string
+	if name == "" {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand ~: %w", err)
+		}
+		home = dir
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to expand ~%s: %w", name, err)
+		}
+		home = u.HomeDir
+	}
+
+	return filepath.Join(home, rest), nil
 }
 
 // GetLanguageForFile determines the Tree-sitter language for a file
@@ -40,6 +133,26 @@ func GetLanguageForFile(filePath string) (*sitter.Language, error) {
 					return typescript.GetLanguage(), nil
 				case "py", "python":
 					return python.GetLanguage(), nil
+				case "rs", "rust":
+					return rust.GetLanguage(), nil
+				case "cs", "csharp":
+					return csharp.GetLanguage(), nil
+				case "php":
+					return php.GetLanguage(), nil
+				case "ex", "elixir":
+					return elixir.GetLanguage(), nil
+				case "sh", "bash":
+					return bash.GetLanguage(), nil
+				case "tf", "hcl":
+					return hcl.GetLanguage(), nil
+				case "md", "mdx":
+					return markdown.GetLanguage(), nil
+				case "yaml", "yml":
+					return yaml.GetLanguage(), nil
+				case "css", "scss", "less":
+					return css.GetLanguage(), nil
+				case "html", "htm":
+					return html.GetLanguage(), nil
 				}
 			}
 		}
@@ -59,6 +172,36 @@ func GetLanguageForFile(filePath string) (*sitter.Language, error) {
 		if strings.Contains(filename, ".py.txt") {
 			return python.GetLanguage(), nil
 		}
+		if strings.Contains(filename, ".rs.txt") {
+			return rust.GetLanguage(), nil
+		}
+		if strings.Contains(filename, ".cs.txt") {
+			return csharp.GetLanguage(), nil
+		}
+		if strings.Contains(filename, ".php.txt") {
+			return php.GetLanguage(), nil
+		}
+		if strings.Contains(filename, ".ex.txt") || strings.Contains(filename, ".exs.txt") {
+			return elixir.GetLanguage(), nil
+		}
+		if strings.Contains(filename, ".sh.txt") || strings.Contains(filename, ".bash.txt") {
+			return bash.GetLanguage(), nil
+		}
+		if strings.Contains(filename, ".tf.txt") || strings.Contains(filename, ".hcl.txt") {
+			return hcl.GetLanguage(), nil
+		}
+		if strings.Contains(filename, ".md.txt") || strings.Contains(filename, ".mdx.txt") {
+			return markdown.GetLanguage(), nil
+		}
+		if strings.Contains(filename, ".yaml.txt") || strings.Contains(filename, ".yml.txt") {
+			return yaml.GetLanguage(), nil
+		}
+		if strings.Contains(filename, ".css.txt") || strings.Contains(filename, ".scss.txt") || strings.Contains(filename, ".less.txt") {
+			return css.GetLanguage(), nil
+		}
+		if strings.Contains(filename, ".html.txt") || strings.Contains(filename, ".htm.txt") {
+			return html.GetLanguage(), nil
+		}
 	}
 
 	ext := strings.ToLower(filepath.Ext(filePath))
@@ -70,26 +213,92 @@ func GetLanguageForFile(filePath string) (*sitter.Language, error) {
 		return javascript.GetLanguage(), nil
 	case ".ts", ".tsx":
 		return typescript.GetLanguage(), nil
-	case ".py":
+	case ".py", ".ipynb":
 		return python.GetLanguage(), nil
 	case ".java":
 		return java.GetLanguage(), nil
+	case ".rs":
+		return rust.GetLanguage(), nil
+	case ".cs":
+		return csharp.GetLanguage(), nil
+	case ".php":
+		return php.GetLanguage(), nil
+	case ".ex", ".exs":
+		return elixir.GetLanguage(), nil
+	case ".sh", ".bash":
+		return bash.GetLanguage(), nil
+	case ".tf", ".hcl":
+		return hcl.GetLanguage(), nil
+	case ".md", ".mdx":
+		return markdown.GetLanguage(), nil
+	case ".yaml", ".yml":
+		return yaml.GetLanguage(), nil
+	case ".css", ".scss", ".less":
+		return css.GetLanguage(), nil
+	case ".html", ".htm":
+		return html.GetLanguage(), nil
+	case "":
+		if langQueries := shebangLanguageQueries(filePath); langQueries != nil {
+			return langQueries.Language, nil
+		}
+		return nil, fmt.Errorf("unsupported file type: %s", filePath)
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", filePath)
 	}
 }
 
+// DiscoveryOptions controls which files are returned by file discovery
+type DiscoveryOptions struct {
+	// IncludeNestedModules descends into nested Go modules and the vendor directories
+	// of Go modules, which are skipped by default
+	IncludeNestedModules bool
+	// ExcludeTests skips test files and test directories below the pattern's base
+	ExcludeTests bool
+	// IncludeGenerated keeps generated files, which are skipped by default
+	IncludeGenerated bool
+	// Hidden includes dotfiles and dot-directories, which are skipped by default
+	Hidden bool
+	// PathRegex, if set, keeps only files whose path matches it
+	PathRegex *regexp.Regexp
+	// PathExcludeRegex, if set, drops files whose path matches it
+	PathExcludeRegex *regexp.Regexp
+	// ModifiedAfter and ModifiedBefore, if set, keep only files whose modification
+	// time falls in the range
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+	// Exclude lists gitignore-style negation patterns such as "!**/*.d.ts" whose
+	// matches are subtracted; relative patterns are resolved against the base directory
+	Exclude []string
+	// Roots, if set, keeps only files that are, with their symlinks resolved, inside
+	// one of these directories
+	Roots []string
+	// Files, if set, lists the files to extract instead of the pattern's matches, such
+	// as the files of a diff. They are filtered like matches, and ones that don't
+	// exist are dropped; the pattern only sets the base directory.
+	Files []string
+}
+
 // FindFiles finds files matching a glob pattern
 func FindFiles(pattern string) ([]string, error) {
+	return FindFilesWithOptions(pattern, DiscoveryOptions{})
+}
+
+// FindFilesWithOptions finds files matching a glob pattern using the given discovery
+// options. The files of a pattern list are merged, each listed once.
+func FindFilesWithOptions(pattern string, opts DiscoveryOptions) ([]string, error) {
+	if opts.Files != nil {
+		return filterListedFiles(opts.Files, PatternBaseDir(pattern), opts), nil
+	}
+	if patterns := SplitPatterns(pattern); len(patterns) > 1 {
+		return findFilesOfPatterns(patterns, opts)
+	}
+
 	// If pattern contains **, use filepath.Walk for recursive matching
 	if strings.Contains(pattern, "**") {
 		var files []string
 
-		// Split pattern at **
+		// Split pattern at each **
 		parts := strings.Split(pattern, "**")
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid pattern with **: %s", pattern)
-		}
 
 		baseDir := parts[0]
 		if baseDir == "" {
@@ -99,21 +308,40 @@ func FindFiles(pattern string) ([]string, error) {
 			baseDir = strings.TrimSuffix(baseDir, "/")
 		}
 
-		// Get the file pattern after **
-		filePattern := parts[1]
-		filePattern = strings.TrimPrefix(filePattern, "/")
+		// The rest of the pattern is matched element by element against the path
+		// below the base directory, so "**/cmd/*.go" only matches files in cmd/
+		var segments []string
+		for i, part := range parts[1:] {
+			part = strings.Trim(part, "/")
+			segments = append(segments, "**")
+			if part != "" {
+				segments = append(segments, strings.Split(part, "/")...)
+			} else if i == len(parts)-2 {
+				// A trailing ** matches every file below it
+				segments = append(segments, "*")
+			}
+		}
 
 		err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return nil // Skip errors
 			}
 
-			if !info.IsDir() {
-				// Check if the filename matches the pattern
-				matched, _ := filepath.Match(filePattern, filepath.Base(path))
-				if matched {
-					files = append(files, path)
+			if info.IsDir() {
+				if skipDirectory(path, baseDir, opts) {
+					return filepath.SkipDir
 				}
+				return nil
+			}
+
+			// Check if the path below the base directory matches the pattern
+			rel, err := filepath.Rel(baseDir, path)
+			if err != nil {
+				return nil
+			}
+			matched := matchSegments(segments, strings.Split(filepath.ToSlash(rel), "/"))
+			if matched && !skipFile(path, baseDir, opts) {
+				files = append(files, path)
 			}
 			return nil
 		})
@@ -131,5 +359,352 @@ func FindFiles(pattern string) ([]string, error) {
 		return nil, err
 	}
 
-	return matches, nil
+	baseDir := PatternBaseDir(pattern)
+	files := matches[:0]
+	for _, match := range matches {
+		if !inSkippedDirectory(match, baseDir, opts) && !skipFile(match, baseDir, opts) {
+			files = append(files, match)
+		}
+	}
+
+	return files, nil
+}
+
+// filterListedFiles keeps the listed files that exist and that discovery wouldn't
+// leave out, sorted and each once
+func filterListedFiles(listed []string, baseDir string, opts DiscoveryOptions) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, file := range listed {
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+		if !inSkippedDirectory(file, baseDir, opts) && !skipFile(file, baseDir, opts) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ReadFileList reads newline-separated file paths, such as the output of
+// git diff --name-only, making relative paths absolute against dir. Blank lines
+// are skipped.
+func ReadFileList(r io.Reader, dir string) ([]string, error) {
+	var files []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dir, line)
+		}
+		files = append(files, filepath.Clean(line))
+	}
+	return files, scanner.Err()
+}
+
+// FileListPattern returns a pattern naming listed files: every file below the
+// deepest directory holding them all, which is their base directory
+func FileListPattern(files []string) string {
+	dir := filepath.Dir(files[0])
+	for _, file := range files[1:] {
+		dir = commonDir(dir, filepath.Dir(file))
+	}
+	return filepath.Join(dir, "**")
+}
+
+// findFilesOfPatterns finds the files matching any of several patterns, sorted
+func findFilesOfPatterns(patterns []string, opts DiscoveryOptions) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := FindFilesWithOptions(pattern, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range matches {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// SplitPatterns splits a comma-separated pattern list, such as
+// "/repo/cmd/**/*.go,/repo/web/**/*.ts", into its patterns. The list is split only
+// when every part starts like a path, with a /, ~ or $VAR, so a pattern naming a
+// file with commas in its name, such as "/repo/a,b.go", is a single pattern.
+func SplitPatterns(pattern string) []string {
+	if !strings.Contains(pattern, ",") {
+		return []string{pattern}
+	}
+	parts := strings.Split(pattern, ",")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if !filepath.IsAbs(part) && !strings.HasPrefix(part, "~") && !strings.HasPrefix(part, "$") {
+			return []string{pattern}
+		}
+		parts[i] = part
+	}
+	return parts
+}
+
+// matchSegments matches path elements against pattern elements, where a ** element
+// matches any number of path elements and other elements use filepath.Match
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+	matched, _ := filepath.Match(pattern[0], path[0])
+	return matched && matchSegments(pattern[1:], path[1:])
+}
+
+// matchesExcludePattern reports whether a file matches a negation pattern. A leading
+// "!" is optional, and a relative pattern without a slash matches at any depth.
+func matchesExcludePattern(pattern, filePath, baseDir string) bool {
+	pattern = strings.TrimPrefix(pattern, "!")
+	if pattern == "" {
+		return false
+	}
+
+	if !filepath.IsAbs(pattern) {
+		if !strings.Contains(pattern, "/") {
+			pattern = "**/" + pattern
+		}
+		pattern = filepath.Join(baseDir, pattern)
+	}
+
+	return matchSegments(
+		strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/"),
+		strings.Split(filepath.ToSlash(filepath.Clean(filePath)), "/"),
+	)
+}
+
+// skipDirectory reports whether discovery should stay out of a directory below baseDir
+func skipDirectory(dir, baseDir string, opts DiscoveryOptions) bool {
+	if filepath.Clean(dir) == filepath.Clean(baseDir) {
+		return false
+	}
+
+	if !opts.Hidden && isHidden(dir) {
+		return true
+	}
+
+	// A directory whose every file is excluded, like **/node_modules/**, isn't walked
+	for _, exclude := range opts.Exclude {
+		if prefix, ok := strings.CutSuffix(strings.TrimPrefix(exclude, "!"), "/**"); ok && matchesExcludePattern(prefix, dir, baseDir) {
+			return true
+		}
+	}
+
+	if !opts.IncludeNestedModules {
+		// A go.mod below the base directory marks the root of a nested module when a
+		// module encloses it, so the sibling modules of a workspace are all walked.
+		// Only Go modules vendor their dependencies.
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil || filepath.Base(dir) == "vendor" {
+			return insideGoModule(filepath.Dir(dir))
+		}
+	}
+
+	return false
+}
+
+// insideGoModule reports whether dir or one of its parents has a go.mod
+func insideGoModule(dir string) bool {
+	for dir = filepath.Clean(dir); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return true
+		}
+		if filepath.Dir(dir) == dir {
+			return false
+		}
+	}
+}
+
+// skipFile reports whether discovery should leave out a file below baseDir
+func skipFile(filePath, baseDir string, opts DiscoveryOptions) bool {
+	if !opts.Hidden && isHidden(filePath) {
+		return true
+	}
+	if !withinRoots(filePath, opts.Roots) {
+		return true
+	}
+	slashPath := filepath.ToSlash(filePath)
+	if opts.PathRegex != nil && !opts.PathRegex.MatchString(slashPath) {
+		return true
+	}
+	if opts.PathExcludeRegex != nil && opts.PathExcludeRegex.MatchString(slashPath) {
+		return true
+	}
+	for _, exclude := range opts.Exclude {
+		if matchesExcludePattern(exclude, filePath, baseDir) {
+			return true
+		}
+	}
+	if !opts.ModifiedAfter.IsZero() || !opts.ModifiedBefore.IsZero() {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return true
+		}
+		if !opts.ModifiedAfter.IsZero() && info.ModTime().Before(opts.ModifiedAfter) {
+			return true
+		}
+		if !opts.ModifiedBefore.IsZero() && info.ModTime().After(opts.ModifiedBefore) {
+			return true
+		}
+	}
+	if opts.ExcludeTests {
+		// Only directories below the base count, so patterns rooted in a test directory still work
+		if rel, err := filepath.Rel(baseDir, filePath); err == nil && IsTestFile(rel) {
+			return true
+		}
+	}
+	if !opts.IncludeGenerated && IsGeneratedFile(filePath) {
+		return true
+	}
+	return false
+}
+
+// isHidden reports whether a file or directory name starts with a dot
+func isHidden(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// inSkippedDirectory reports whether any directory between baseDir and a file would be skipped
+func inSkippedDirectory(filePath, baseDir string, opts DiscoveryOptions) bool {
+	baseDir = filepath.Clean(baseDir)
+	for dir := filepath.Dir(filePath); dir != baseDir; dir = filepath.Dir(dir) {
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+		if skipDirectory(dir, baseDir, opts) {
+			return true
+		}
+	}
+	return false
+}
+
+// testDirectories are directory names whose contents are treated as tests
+var testDirectories = map[string]bool{
+	"test":      true,
+	"tests":     true,
+	"__tests__": true,
+	"testdata":  true,
+	"spec":      true,
+}
+
+// IsTestFile reports whether a file looks like a test by the naming conventions of its language
+func IsTestFile(filePath string) bool {
+	name := filepath.Base(filePath)
+	lower := strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(lower, "_test.go"):
+		return true
+	case strings.Contains(lower, ".test.") || strings.Contains(lower, ".spec."):
+		return true
+	case strings.HasSuffix(lower, ".py") && (strings.HasPrefix(lower, "test_") || strings.HasSuffix(lower, "_test.py") || lower == "conftest.py"):
+		return true
+	case strings.HasSuffix(name, "Test.java") || strings.HasSuffix(name, "Tests.java") || strings.HasSuffix(name, "IT.java"):
+		return true
+	}
+
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(filePath)), "/") {
+		if testDirectories[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// generatedHeaderPattern matches the "Code generated ... DO NOT EDIT." convention
+// (https://go.dev/s/generatedcode) and the @generated marker used by other tools
+var generatedHeaderPattern = regexp.MustCompile(`(?m)^\s*(//|#|/?\*+|--)\s*(Code generated .* DO NOT EDIT\.?|@generated\b)`)
+
+// generatedHeaderSize is how much of a file is searched for a generated code header
+const generatedHeaderSize = 4096
+
+// IsGeneratedFile reports whether a file was produced by a code generator or minifier,
+// judging by its name and by a generated code header near the top of the file
+func IsGeneratedFile(filePath string) bool {
+	lower := strings.ToLower(filepath.Base(filePath))
+	switch {
+	case strings.HasSuffix(lower, ".pb.go"), strings.HasSuffix(lower, "_gen.go"), strings.HasSuffix(lower, ".gen.go"):
+		return true
+	case strings.HasSuffix(lower, ".min.js"), strings.HasSuffix(lower, ".min.mjs"), strings.HasSuffix(lower, ".min.cjs"):
+		return true
+	case strings.HasSuffix(lower, "_pb2.py"), strings.HasSuffix(lower, "_pb2_grpc.py"):
+		return true
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, generatedHeaderSize)
+	n, _ := io.ReadFull(f, header)
+	return generatedHeaderPattern.Match(header[:n])
+}
+
+// PatternBaseDir returns the leading directory of a pattern that contains no glob
+// metacharacters, or the deepest directory holding those of every pattern of a list
+func PatternBaseDir(pattern string) string {
+	if patterns := SplitPatterns(pattern); len(patterns) > 1 {
+		dir := PatternBaseDir(patterns[0])
+		for _, pattern := range patterns[1:] {
+			dir = commonDir(dir, PatternBaseDir(pattern))
+		}
+		return dir
+	}
+
+	pattern = filepath.Clean(pattern)
+	if !strings.ContainsAny(pattern, "*?[") {
+		return filepath.Dir(pattern)
+	}
+
+	dir := pattern
+	for strings.ContainsAny(dir, "*?[") {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// commonDir returns the deepest directory that is a or b or holds both
+func commonDir(a, b string) string {
+	for {
+		if rel, err := filepath.Rel(a, b); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return a
+		}
+		parent := filepath.Dir(a)
+		if parent == a {
+			return a
+		}
+		a = parent
+	}
 }
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetLanguageForFile(t *testing.T) {
@@ -15,9 +19,11 @@ func TestGetLanguageForFile(t *testing.T) {
 		{"app.js", false},
 		{"index.ts", false},
 		{"script.py", false},
+		{"analysis.ipynb", false},
 		{"Main.java", false},
-		{"style.css", true},
-		{"readme.md", true},
+		{"style.css", false},
+		{"readme.md", false},
+		{"notes.rtf", true},
 	}
 
 	for _, tt := range tests {
@@ -51,6 +57,8 @@ func TestFindFiles(t *testing.T) {
 		"src/client.go",
 		"test/main_test.go",
 		"docs/readme.md",
+		"pkg/internal/cache/lru.go",
+		"pkg/api/internal/auth.go",
 	}
 
 	for _, file := range testFiles {
@@ -69,9 +77,18 @@ func TestFindFiles(t *testing.T) {
 		expected int
 	}{
 		{filepath.Join(testDir, "*.go"), 2},
-		{filepath.Join(testDir, "**/*.go"), 5},
+		{filepath.Join(testDir, "**/*.go"), 7},
 		{filepath.Join(testDir, "src/*.go"), 2},
 		{filepath.Join(testDir, "**/*_test.go"), 1},
+		{filepath.Join(testDir, "**/internal/**/*.go"), 2},
+		{filepath.Join(testDir, "pkg/**/api/**/*.go"), 1},
+		{filepath.Join(testDir, "**/cache/**/lru.go"), 1},
+		{filepath.Join(testDir, "pkg/**"), 2},
+		{filepath.Join(testDir, "**/src/*.go"), 2},
+		{filepath.Join(testDir, "**/internal/*.go"), 1},
+		{filepath.Join(testDir, "**/[st]*/*.go"), 3},
+		{filepath.Join(testDir, "**/src/?????t.go"), 1},
+		{filepath.Join(testDir, "**/cmd/*.go"), 0},
 	}
 
 	for _, tt := range tests {
@@ -86,3 +103,618 @@ func TestFindFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		filePath string
+		want     bool
+	}{
+		{"/repo/server_test.go", true},
+		{"/repo/server.go", false},
+		{"/repo/src/app.spec.ts", true},
+		{"/repo/src/app.test.js", true},
+		{"/repo/src/app.ts", false},
+		{"/repo/test_models.py", true},
+		{"/repo/models_test.py", true},
+		{"/repo/conftest.py", true},
+		{"/repo/models.py", false},
+		{"/repo/src/UserServiceTest.java", true},
+		{"/repo/src/UserService.java", false},
+		{"/repo/__tests__/app.js", true},
+		{"/repo/testdata/sample.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			if got := IsTestFile(tt.filePath); got != tt.want {
+				t.Errorf("IsTestFile(%q) = %v, want %v", tt.filePath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindFiles_ModuleBoundaries(t *testing.T) {
+	testDir := t.TempDir()
+
+	testFiles := []string{
+		"go.mod",
+		"main.go",
+		"pkg/util.go",
+		"vendor/github.com/dep/dep.go",
+		"tools/go.mod",
+		"tools/gen.go",
+		"tools/sub/helper.go",
+	}
+
+	for _, file := range testFiles {
+		path := filepath.Join(testDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("module example.com/test\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		pattern  string
+		opts     DiscoveryOptions
+		expected int
+	}{
+		{filepath.Join(testDir, "**/*.go"), DiscoveryOptions{}, 2},
+		{filepath.Join(testDir, "**/*.go"), DiscoveryOptions{IncludeNestedModules: true}, 5},
+		{filepath.Join(testDir, "*/*.go"), DiscoveryOptions{}, 1},
+		{filepath.Join(testDir, "*/*.go"), DiscoveryOptions{IncludeNestedModules: true}, 2},
+		// Patterns rooted inside a nested module still match its files
+		{filepath.Join(testDir, "tools/**/*.go"), DiscoveryOptions{}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			files, err := FindFilesWithOptions(tt.pattern, tt.opts)
+			if err != nil {
+				t.Fatalf("FindFilesWithOptions(%q) error = %v", tt.pattern, err)
+			}
+			if len(files) != tt.expected {
+				t.Errorf("FindFilesWithOptions(%q, %+v) returned %d files, want %d: %v",
+					tt.pattern, tt.opts, len(files), tt.expected, files)
+			}
+		})
+	}
+}
+
+func TestFindFiles_ExcludeTests(t *testing.T) {
+	testDir := t.TempDir()
+
+	testFiles := []string{
+		"server.go",
+		"server_test.go",
+		"web/app.ts",
+		"web/app.spec.ts",
+		"web/__tests__/render.ts",
+		"py/models.py",
+		"py/test_models.py",
+		"java/Service.java",
+		"java/ServiceTest.java",
+	}
+
+	for _, file := range testFiles {
+		path := filepath.Join(testDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		pattern  string
+		opts     DiscoveryOptions
+		expected int
+	}{
+		{filepath.Join(testDir, "**/*"), DiscoveryOptions{}, 9},
+		{filepath.Join(testDir, "**/*"), DiscoveryOptions{ExcludeTests: true}, 4},
+		{filepath.Join(testDir, "*.go"), DiscoveryOptions{ExcludeTests: true}, 1},
+		// Patterns rooted inside a test directory still match its files
+		{filepath.Join(testDir, "web/__tests__/*.ts"), DiscoveryOptions{ExcludeTests: true}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			files, err := FindFilesWithOptions(tt.pattern, tt.opts)
+			if err != nil {
+				t.Fatalf("FindFilesWithOptions(%q) error = %v", tt.pattern, err)
+			}
+			if len(files) != tt.expected {
+				t.Errorf("FindFilesWithOptions(%q, %+v) returned %d files, want %d: %v",
+					tt.pattern, tt.opts, len(files), tt.expected, files)
+			}
+		})
+	}
+}
+
+func TestIsGeneratedFile(t *testing.T) {
+	testDir := t.TempDir()
+
+	tests := []struct {
+		fileName string
+		content  string
+		expected bool
+	}{
+		{"server.go", "package server\n", false},
+		{"api.pb.go", "package api\n", true},
+		{"types_gen.go", "package types\n", true},
+		{"mock.go", "// Code generated by mockgen. DO NOT EDIT.\n\npackage mock\n", true},
+		{"doc.go", "// Package doc mentions Code generated files but is hand written.\npackage doc\n", false},
+		{"bundle.min.js", "function a(){}\n", true},
+		{"schema.ts", "/**\n * @generated\n */\nexport type A = string;\n", true},
+		{"models.py", "# Code generated by protoc. DO NOT EDIT.\nclass A: pass\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			path := filepath.Join(testDir, tt.fileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if got := IsGeneratedFile(path); got != tt.expected {
+				t.Errorf("IsGeneratedFile(%q) = %v, want %v", tt.fileName, got, tt.expected)
+			}
+		})
+	}
+
+	files, err := FindFiles(filepath.Join(testDir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("FindFiles returned %v, want only the hand written Go files", files)
+	}
+
+	files, err = FindFilesWithOptions(filepath.Join(testDir, "*.go"), DiscoveryOptions{IncludeGenerated: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 5 {
+		t.Errorf("FindFilesWithOptions with IncludeGenerated returned %v, want all 5 Go files", files)
+	}
+}
+
+func TestFindFiles_PathRegex(t *testing.T) {
+	testDir := t.TempDir()
+
+	testFiles := []string{
+		"main.go",
+		"services/api/handler.go",
+		"services/api/internal/store.go",
+		"services/billing/invoice.go",
+		"tools/gen.go",
+	}
+
+	for _, file := range testFiles {
+		path := filepath.Join(testDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     DiscoveryOptions
+		expected int
+	}{
+		{"no filters", DiscoveryOptions{}, 5},
+		{"include", DiscoveryOptions{PathRegex: regexp.MustCompile(`/services/`)}, 3},
+		{"exclude", DiscoveryOptions{PathExcludeRegex: regexp.MustCompile(`/internal/`)}, 4},
+		{"include and exclude", DiscoveryOptions{
+			PathRegex:        regexp.MustCompile(`/services/`),
+			PathExcludeRegex: regexp.MustCompile(`/internal/`),
+		}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := FindFilesWithOptions(filepath.Join(testDir, "**/*.go"), tt.opts)
+			if err != nil {
+				t.Fatalf("FindFilesWithOptions error = %v", err)
+			}
+			if len(files) != tt.expected {
+				t.Errorf("FindFilesWithOptions returned %d files, want %d: %v", len(files), tt.expected, files)
+			}
+		})
+	}
+}
+
+func TestFindFiles_Negations(t *testing.T) {
+	testDir := t.TempDir()
+
+	testFiles := []string{
+		"src/app.ts",
+		"src/app.d.ts",
+		"src/lib/util.ts",
+		"src/lib/util.d.ts",
+		"src/lib/util.spec.ts",
+	}
+
+	for _, file := range testFiles {
+		path := filepath.Join(testDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		exclude  []string
+		expected int
+	}{
+		{"none", nil, 5},
+		{"relative doublestar", []string{"!**/*.d.ts"}, 3},
+		{"basename at any depth", []string{"!*.spec.ts"}, 4},
+		{"several", []string{"!**/*.d.ts", "!*.spec.ts"}, 2},
+		{"relative directory", []string{"!src/lib/**"}, 2},
+		{"absolute", []string{"!" + filepath.Join(testDir, "src/app.ts")}, 4},
+		{"without the !", []string{"**/*.d.ts", "src/lib/**"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := FindFilesWithOptions(filepath.Join(testDir, "**/*.ts"), DiscoveryOptions{Exclude: tt.exclude})
+			if err != nil {
+				t.Fatalf("FindFilesWithOptions error = %v", err)
+			}
+			if len(files) != tt.expected {
+				t.Errorf("FindFilesWithOptions with %v returned %d files, want %d: %v", tt.exclude, len(files), tt.expected, files)
+			}
+		})
+	}
+}
+
+func TestFindFiles_Workspace(t *testing.T) {
+	testDir := t.TempDir()
+
+	// Sibling modules with no module above them, as in a go.work workspace
+	testFiles := []string{
+		"go.work",
+		"a/go.mod",
+		"a/a.go",
+		"a/vendor/github.com/dep/dep.go",
+		"a/tools/go.mod",
+		"a/tools/gen.go",
+		"b/go.mod",
+		"b/b.go",
+		"web/vendor/lib.js",
+	}
+	for _, file := range testFiles {
+		path := filepath.Join(testDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("module example.com/test\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		pattern  string
+		opts     DiscoveryOptions
+		expected []string
+	}{
+		{"**/*.go", DiscoveryOptions{}, []string{"a/a.go", "b/b.go"}},
+		{"**/*.go", DiscoveryOptions{IncludeNestedModules: true}, []string{"a/a.go", "a/tools/gen.go", "a/vendor/github.com/dep/dep.go", "b/b.go"}},
+		// Outside a Go module, vendor is an ordinary directory
+		{"**/*.js", DiscoveryOptions{}, []string{"web/vendor/lib.js"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			files, err := FindFilesWithOptions(filepath.Join(testDir, tt.pattern), tt.opts)
+			if err != nil {
+				t.Fatalf("FindFilesWithOptions(%q) error = %v", tt.pattern, err)
+			}
+			var got []string
+			for _, file := range files {
+				rel, _ := filepath.Rel(testDir, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("FindFilesWithOptions(%q, %+v) = %v, want %v", tt.pattern, tt.opts, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSkipDirectory_Exclude(t *testing.T) {
+	baseDir := t.TempDir()
+	opts := DiscoveryOptions{Exclude: []string{"**/node_modules/**", "!src/gen/**", "**/*.d.ts"}}
+
+	tests := []struct {
+		dir  string
+		skip bool
+	}{
+		{"web/node_modules", true},
+		{"node_modules", true},
+		{"web", false},
+		{"src/gen", true},
+		{"lib/src/gen", false},
+		{"types.d.ts", false},
+	}
+
+	for _, tt := range tests {
+		if got := skipDirectory(filepath.Join(baseDir, tt.dir), baseDir, opts); got != tt.skip {
+			t.Errorf("skipDirectory(%s) = %v, want %v", tt.dir, got, tt.skip)
+		}
+	}
+}
+
+func TestFindFiles_ListedFiles(t *testing.T) {
+	testDir := t.TempDir()
+	for _, file := range []string{"cmd/main.go", "cmd/main_test.go", "web/app.ts", "web/gen.pb.go"} {
+		path := filepath.Join(testDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := "web/app.ts\r\n\ncmd/main.go\n" + filepath.Join(testDir, "cmd/main_test.go") + "\nweb/gen.pb.go\ncmd/deleted.go\ncmd\n  web/app.ts  \n"
+	listed, err := ReadFileList(strings.NewReader(list), testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 7 || listed[0] != filepath.Join(testDir, "web/app.ts") {
+		t.Fatalf("ReadFileList = %v, want 7 absolute paths", listed)
+	}
+
+	pattern := FileListPattern(listed)
+	if pattern != filepath.Join(testDir, "**") {
+		t.Errorf("FileListPattern = %s, want every file below %s", pattern, testDir)
+	}
+
+	// Missing files, directories and generated files are dropped, and each file is listed once
+	files, err := FindFilesWithOptions(pattern, DiscoveryOptions{Files: listed, ExcludeTests: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(testDir, "cmd/main.go"), filepath.Join(testDir, "web/app.ts")}
+	if strings.Join(files, " ") != strings.Join(want, " ") {
+		t.Errorf("FindFilesWithOptions = %v, want %v", files, want)
+	}
+}
+
+func TestFindFiles_PatternList(t *testing.T) {
+	testDir := t.TempDir()
+	for _, file := range []string{"cmd/main.go", "web/app.ts", "web/app.go", "docs/a,b.md"} {
+		path := filepath.Join(testDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		pattern  string
+		expected []string
+	}{
+		{"two patterns", filepath.Join(testDir, "cmd/**/*.go") + "," + filepath.Join(testDir, "web/**/*.ts"), []string{"cmd/main.go", "web/app.ts"}},
+		{"overlapping patterns", filepath.Join(testDir, "**/*.go") + ", " + filepath.Join(testDir, "web/*"), []string{"cmd/main.go", "web/app.go", "web/app.ts"}},
+		{"comma in a file name", filepath.Join(testDir, "docs/a,b.md"), []string{"docs/a,b.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := FindFilesWithOptions(tt.pattern, DiscoveryOptions{})
+			if err != nil {
+				t.Fatalf("FindFilesWithOptions error = %v", err)
+			}
+			var rel []string
+			for _, file := range files {
+				r, _ := filepath.Rel(testDir, file)
+				rel = append(rel, filepath.ToSlash(r))
+			}
+			if strings.Join(rel, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("FindFilesWithOptions(%q) = %v, want %v", tt.pattern, rel, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPatternBaseDir_PatternList(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"/repo/cmd/**/*.go,/repo/web/**/*.ts", "/repo"},
+		{"/repo/cmd/*.go,/repo/cmd/sub/*.go", "/repo/cmd"},
+		{"/a/*.go,/b/*.go", "/"},
+	}
+	for _, tt := range tests {
+		if got := PatternBaseDir(tt.pattern); got != tt.want {
+			t.Errorf("PatternBaseDir(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestFindFiles_Hidden(t *testing.T) {
+	testDir := t.TempDir()
+
+	testFiles := []string{
+		"app.py",
+		".eslintrc.js",
+		".venv/lib/site.py",
+		"pkg/.cache/cached.py",
+		"pkg/module.py",
+	}
+
+	for _, file := range testFiles {
+		path := filepath.Join(testDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		pattern  string
+		opts     DiscoveryOptions
+		expected int
+	}{
+		{filepath.Join(testDir, "**/*"), DiscoveryOptions{}, 2},
+		{filepath.Join(testDir, "**/*"), DiscoveryOptions{Hidden: true}, 5},
+		{filepath.Join(testDir, "*/*/*.py"), DiscoveryOptions{}, 0},
+		{filepath.Join(testDir, "*/*/*.py"), DiscoveryOptions{Hidden: true}, 2},
+		// Patterns rooted inside a dot-directory still match its files
+		{filepath.Join(testDir, ".venv/**/*.py"), DiscoveryOptions{}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			files, err := FindFilesWithOptions(tt.pattern, tt.opts)
+			if err != nil {
+				t.Fatalf("FindFilesWithOptions(%q) error = %v", tt.pattern, err)
+			}
+			if len(files) != tt.expected {
+				t.Errorf("FindFilesWithOptions(%q, %+v) returned %d files, want %d: %v",
+					tt.pattern, tt.opts, len(files), tt.expected, files)
+			}
+		})
+	}
+}
+
+func TestFindFiles_ModifiedTime(t *testing.T) {
+	testDir := t.TempDir()
+	now := time.Now()
+
+	testFiles := map[string]time.Time{
+		"recent.go":   now.Add(-time.Hour),
+		"lastweek.go": now.Add(-6 * 24 * time.Hour),
+		"old.go":      now.Add(-90 * 24 * time.Hour),
+	}
+
+	for file, mtime := range testFiles {
+		path := filepath.Join(testDir, file)
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     DiscoveryOptions
+		expected int
+	}{
+		{"no bounds", DiscoveryOptions{}, 3},
+		{"after", DiscoveryOptions{ModifiedAfter: now.Add(-7 * 24 * time.Hour)}, 2},
+		{"before", DiscoveryOptions{ModifiedBefore: now.Add(-2 * time.Hour)}, 2},
+		{"range", DiscoveryOptions{
+			ModifiedAfter:  now.Add(-7 * 24 * time.Hour),
+			ModifiedBefore: now.Add(-2 * time.Hour),
+		}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := FindFilesWithOptions(filepath.Join(testDir, "*.go"), tt.opts)
+			if err != nil {
+				t.Fatalf("FindFilesWithOptions error = %v", err)
+			}
+			if len(files) != tt.expected {
+				t.Errorf("FindFilesWithOptions returned %d files, want %d: %v", len(files), tt.expected, files)
+			}
+		})
+	}
+}
+
+func TestReadStableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "busy.go")
+	if err := os.WriteFile(path, []byte("package busy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// appendOnRead grows the file after the first n reads, as an editor saving mid-scan would
+	appendOnRead := func(n int) func(string) ([]byte, error) {
+		reads := 0
+		return func(name string) ([]byte, error) {
+			content, err := os.ReadFile(name)
+			reads++
+			if reads <= n {
+				f, ferr := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0644)
+				if ferr != nil {
+					t.Fatal(ferr)
+				}
+				f.WriteString("\nfunc Added() {}\n")
+				f.Close()
+			}
+			return content, err
+		}
+	}
+
+	content, _, err := readStableFile(path, appendOnRead(0))
+	if err != nil || string(content) != "package busy\n" {
+		t.Errorf("readStableFile(stable) = %q, %v", content, err)
+	}
+
+	content, _, err = readStableFile(path, appendOnRead(1))
+	if err != nil {
+		t.Errorf("expected a file that changed once to be re-read, got %v", err)
+	}
+	if current, _ := os.ReadFile(path); string(content) != string(current) {
+		t.Errorf("expected the re-read content, got %q", content)
+	}
+
+	if _, _, err := readStableFile(path, appendOnRead(2)); !errors.Is(err, ErrFileUnstable) {
+		t.Errorf("expected ErrFileUnstable for a file that keeps changing, got %v", err)
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	t.Setenv("GLYPH_TEST_SRC", "/work/src")
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"/abs/**/*.go", "/abs/**/*.go", false},
+		{"~", home, false},
+		{"~/src/**/*.go", filepath.Join(home, "src/**/*.go"), false},
+		{"$GLYPH_TEST_SRC/**/*.go", "/work/src/**/*.go", false},
+		{"${GLYPH_TEST_SRC}/app/*.py", "/work/src/app/*.py", false},
+		{"$GLYPH_TEST_UNSET/*.go", "", true},
+		{"~glyph-no-such-user/*.go", "", true},
+		{"relative/*.go", "relative/*.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ExpandPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import "fmt"
+
+// Output formats selected by ExtractOptions.Format
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatCtags    = "ctags"
+	FormatSARIF    = "sarif"
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatDOT      = "dot"
+	FormatTree     = "tree"
+	FormatCompact  = "compact"
+	FormatHTML     = "html"
+	FormatQuickfix = "quickfix"
+)
+
+// parseFormat validates an output format, defaulting to Markdown
+func parseFormat(format string) (string, error) {
+	switch format {
+	case "", FormatMarkdown:
+		return FormatMarkdown, nil
+	case FormatJSON, FormatNDJSON, FormatCtags, FormatSARIF, FormatCSV, FormatTSV, FormatDOT, FormatTree, FormatCompact, FormatHTML, FormatQuickfix:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// isStructuredFormat reports whether a format replaces the Markdown outline,
+// whether for other tools or for reading in a terminal
+func isStructuredFormat(format string) bool {
+	return format != "" && format != FormatMarkdown
+}
+
+// setFormatOutput replaces the output of a result with the symbols written in a
+// structured format. Paths are written relative to root where the format expects
+// them to be, and scores rank fuzzy name matches by symbol ID.
+func (r *ExtractionResult) setFormatOutput(format, root string, symbols []Symbol, metadata map[string]*FileMetadata, scores map[string]int) error {
+	var err error
+	switch format {
+	case FormatJSON:
+		err = r.setJSONOutput(symbols, metadata, scores)
+	case FormatNDJSON:
+		r.Output, err = formatNDJSON(symbols, scores)
+	case FormatCtags:
+		r.Output = formatCtags(symbols, root)
+	case FormatSARIF:
+		r.Output, err = formatSARIF(symbols, metadata, r.Warnings, root)
+	case FormatCSV:
+		r.Output, err = formatCSV(symbols, ',')
+	case FormatTSV:
+		r.Output, err = formatCSV(symbols, '\t')
+	case FormatDOT:
+		r.Output = formatDOT(symbols, root)
+	case FormatTree:
+		r.Output = formatTree(symbols, root)
+	case FormatCompact:
+		r.Output = formatCompact(symbols, root)
+	case FormatHTML:
+		r.Output, err = formatHTMLReport(symbols, metadata, root)
+	case FormatQuickfix:
+		r.Output = formatQuickfix(symbols)
+	default:
+		err = fmt.Errorf("unknown format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	r.Format = format
+	return nil
+}
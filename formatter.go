@@ -2,11 +2,39 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 )
 
+// FormatOptions controls how symbols are rendered
+type FormatOptions struct {
+	Detail DetailLevel
+	// GroupByPackage groups files under the import path of their Go package
+	GroupByPackage bool
+	// Depth limits how many levels of nested symbols are shown; 0 shows all levels
+	// and 1 shows top-level declarations only
+	Depth int
+	// MaxSymbolsPerFile limits how many symbols are shown for each file; 0 means no limit
+	MaxSymbolsPerFile int
+	// MaxSignatureLength truncates longer signatures in standard detail; 0 means no limit
+	MaxSignatureLength int
+	// Files holds metadata shown under the heading of each file, when known
+	Files map[string]*FileMetadata
+	// Summarize is the size in bytes above which the largest files are collapsed
+	// to their top-level symbols until the outline fits; 0 disables summarization
+	Summarize int
+}
+
 // FormatSymbols formats symbols for output
 func FormatSymbols(symbols []Symbol, detailLevel DetailLevel) string {
+	return FormatSymbolsWithOptions(symbols, FormatOptions{Detail: detailLevel})
+}
+
+// FormatSymbolsWithOptions formats symbols for output using the given options
+func FormatSymbolsWithOptions(symbols []Symbol, opts FormatOptions) string {
 	if len(symbols) == 0 {
 		return "No symbols found"
 	}
@@ -14,59 +42,446 @@ func FormatSymbols(symbols []Symbol, detailLevel DetailLevel) string {
 	var sb strings.Builder
 	sb.WriteString("# Symbol Outline\n\n")
 
-	// Group symbols by file
+	// Group symbols by file, keeping files in the order they were extracted
+	var files []string
 	fileSymbols := make(map[string][]Symbol)
 	for _, sym := range symbols {
+		if _, ok := fileSymbols[sym.FilePath]; !ok {
+			files = append(files, sym.FilePath)
+		}
 		fileSymbols[sym.FilePath] = append(fileSymbols[sym.FilePath], sym)
 	}
 
+	collapsed := summarizeFiles(files, fileSymbols, opts)
+	if len(collapsed) > 0 {
+		sb.WriteString(fmt.Sprintf("Collapsed %d of %d files to top-level symbols to keep the outline under %d bytes\n\n",
+			len(collapsed), len(files), opts.Summarize))
+	}
+
+	if opts.GroupByPackage {
+		formatPackages(&sb, files, fileSymbols, collapsed, opts)
+		return sb.String()
+	}
+
 	// Format output
-	for file, syms := range fileSymbols {
-		sb.WriteString(fmt.Sprintf("## %s\n\n", file))
+	for _, file := range files {
+		formatFileSection(&sb, "##", file, fileSymbols[file], collapsed[file], opts)
+	}
+
+	return sb.String()
+}
+
+// formatFileSection formats the heading, metadata and symbols of one file
+func formatFileSection(sb *strings.Builder, heading, file string, symbols []Symbol, collapsed bool, opts FormatOptions) {
+	sb.WriteString(fmt.Sprintf("%s %s\n\n", heading, file))
+	formatFileMetadata(sb, opts.Files[file], len(symbols))
+	formatFileSymbols(sb, symbols, collapsed, opts)
+
+	sb.WriteString("\n")
+}
+
+// summarizeFiles picks the files to collapse to their top-level symbols so the
+// outline fits in opts.Summarize bytes, collapsing the largest files first.
+// Files are only collapsed while that makes the outline smaller.
+func summarizeFiles(files []string, fileSymbols map[string][]Symbol, opts FormatOptions) map[string]bool {
+	if opts.Summarize <= 0 {
+		return nil
+	}
+
+	sizes := make(map[string]int)
+	total := 0
+	for _, file := range files {
+		var sb strings.Builder
+		formatFileSection(&sb, "###", file, fileSymbols[file], false, opts)
+		sizes[file] = sb.Len()
+		total += sb.Len()
+	}
+	if total <= opts.Summarize {
+		return nil
+	}
+
+	bySize := make([]string, len(files))
+	copy(bySize, files)
+	sort.SliceStable(bySize, func(i, j int) bool { return sizes[bySize[i]] > sizes[bySize[j]] })
 
-		for _, sym := range syms {
-			formatSymbol(&sb, sym, detailLevel, 0)
+	collapsed := make(map[string]bool)
+	for _, file := range bySize {
+		if total <= opts.Summarize {
+			break
 		}
+		var sb strings.Builder
+		formatFileSection(&sb, "###", file, fileSymbols[file], true, opts)
+		if sb.Len() < sizes[file] {
+			collapsed[file] = true
+			total -= sizes[file] - sb.Len()
+		}
+	}
+	return collapsed
+}
 
-		sb.WriteString("\n")
+// formatPackages formats files grouped by Go package import path. Files outside of
+// a Go module are grouped by their package declaration, such as a Java package,
+// or else by directory.
+func formatPackages(sb *strings.Builder, files []string, fileSymbols map[string][]Symbol, collapsed map[string]bool, opts FormatOptions) {
+	resolver := newGoModuleResolver()
+
+	var packages []string
+	packageFiles := make(map[string][]string)
+	for _, file := range files {
+		pkg := resolver.ImportPath(file)
+		if pkg == "" {
+			pkg = declaredPackage(fileSymbols[file])
+		}
+		if pkg == "" {
+			pkg = filepath.Dir(file)
+		}
+		if _, ok := packageFiles[pkg]; !ok {
+			packages = append(packages, pkg)
+		}
+		packageFiles[pkg] = append(packageFiles[pkg], file)
 	}
+	sort.Strings(packages)
 
-	return sb.String()
+	for _, pkg := range packages {
+		sb.WriteString(fmt.Sprintf("## %s\n\n", pkg))
+
+		for _, file := range packageFiles[pkg] {
+			formatFileSection(sb, "###", file, fileSymbols[file], collapsed[file], opts)
+		}
+	}
+}
+
+// declaredPackage returns the name of the package declared by a file's symbols, if any
+func declaredPackage(symbols []Symbol) string {
+	for _, sym := range symbols {
+		if sym.Kind == "package" {
+			return sym.Name
+		}
+	}
+	return ""
+}
+
+// formatFileMetadata writes a one-line summary of a file, such as
+// "go · package server · 120 lines · 8 symbols · sha256:3f2a…"
+func formatFileMetadata(sb *strings.Builder, meta *FileMetadata, symbols int) {
+	if meta == nil {
+		return
+	}
+
+	var parts []string
+	if meta.Language != "" {
+		parts = append(parts, meta.Language)
+	}
+	if meta.Package != "" {
+		kind := "package"
+		if meta.Language == "python" {
+			kind = "module"
+		}
+		parts = append(parts, kind+" "+meta.Package)
+	}
+	parts = append(parts,
+		fmt.Sprintf("%d lines", meta.Lines),
+		fmt.Sprintf("%d symbols", symbols),
+		"sha256:"+meta.Hash)
+
+	sb.WriteString(strings.Join(parts, " · ") + "\n\n")
+
+	if len(meta.ParseErrors) > 0 {
+		lines := make([]string, len(meta.ParseErrors))
+		for i, r := range meta.ParseErrors {
+			lines[i] = r.String()
+		}
+		sb.WriteString(fmt.Sprintf("Syntax errors at lines %s; symbols there may be missing or incomplete\n\n", strings.Join(lines, ", ")))
+	}
+}
+
+// formatFileSymbols formats the symbols of one file as a tree, followed by a
+// marker for the symbols left out by MaxSymbolsPerFile. A collapsed file shows
+// only its top-level symbols, followed by a count of the nested ones.
+func formatFileSymbols(sb *strings.Builder, symbols []Symbol, collapsed bool, opts FormatOptions) {
+	roots := BuildHierarchy(symbols)
+	nested := 0
+	if collapsed {
+		opts.Depth = 1
+		nested = len(symbols) - len(roots)
+	}
+
+	budget := &symbolBudget{remaining: opts.MaxSymbolsPerFile}
+	formatSymbolTree(sb, roots, opts, 0, budget)
+
+	if budget.omitted > 0 {
+		sb.WriteString(fmt.Sprintf("- … and %d more\n", budget.omitted))
+	}
+	if nested > 0 {
+		sb.WriteString(fmt.Sprintf("- … %d nested symbols collapsed to fit the outline\n", nested))
+	}
+}
+
+// symbolBudget tracks how many more symbols of a file may be shown
+type symbolBudget struct {
+	remaining int
+	omitted   int
+}
+
+// formatSymbolTree formats nested symbols, indenting children under their parent
+func formatSymbolTree(sb *strings.Builder, nodes []*SymbolNode, opts FormatOptions, level int, budget *symbolBudget) {
+	if opts.Depth > 0 && level >= opts.Depth {
+		return
+	}
+
+	for _, node := range nodes {
+		if opts.MaxSymbolsPerFile > 0 {
+			if budget.remaining == 0 {
+				budget.omitted++
+				formatSymbolTree(sb, node.Children, opts, level+1, budget)
+				continue
+			}
+			budget.remaining--
+		}
+
+		formatSymbol(sb, node.Symbol, opts, level)
+		formatSymbolTree(sb, node.Children, opts, level+1, budget)
+	}
 }
 
-func formatSymbol(sb *strings.Builder, symbol Symbol, detailLevel DetailLevel, indent int) {
+func formatSymbol(sb *strings.Builder, symbol Symbol, opts FormatOptions, indent int) {
 	indentStr := strings.Repeat("  ", indent)
+	annotations := formatAnnotations(symbol)
 
-	switch detailLevel {
+	switch opts.Detail {
 	case Minimal:
-		sb.WriteString(fmt.Sprintf("%s- %s: %s (line %d)\n",
-			indentStr, symbol.Kind, symbol.Name, symbol.StartLine))
+		sb.WriteString(fmt.Sprintf("%s- %s: %s (line %d)%s\n",
+			indentStr, symbol.Kind, symbol.Name, symbol.StartLine, annotations))
 	case Standard:
-		if symbol.Signature != "" {
-			// For variables and constants, show name with type/signature
-			if symbol.Kind == "var" || symbol.Kind == "const" {
-				// Avoid duplicate names when signature equals name
-				if symbol.Signature == symbol.Name {
-					sb.WriteString(fmt.Sprintf("%s- %s: %s\n",
-						indentStr, symbol.Kind, symbol.Name))
-				} else {
-					sb.WriteString(fmt.Sprintf("%s- %s: %s %s\n",
-						indentStr, symbol.Kind, symbol.Name, symbol.Signature))
-				}
-			} else {
-				sb.WriteString(fmt.Sprintf("%s- %s: %s\n",
-					indentStr, symbol.Kind, symbol.Signature))
-			}
-		} else {
-			sb.WriteString(fmt.Sprintf("%s- %s: %s (lines %d-%d)\n",
-				indentStr, symbol.Kind, symbol.Name, symbol.StartLine, symbol.EndLine))
-		}
+		sb.WriteString(fmt.Sprintf("%s- %s\n", indentStr, standardEntry(symbol, opts.MaxSignatureLength)))
 	case Full:
-		sb.WriteString(fmt.Sprintf("%s- %s (lines %d-%d):\n",
-			indentStr, symbol.Kind, symbol.StartLine, symbol.EndLine))
+		sb.WriteString(fmt.Sprintf("%s- %s (lines %d-%d)%s:\n",
+			indentStr, symbol.Kind, symbol.StartLine, symbol.EndLine, annotations))
 		if symbol.Signature != "" {
 			sb.WriteString(fmt.Sprintf("%s  ```\n%s  %s\n%s  ```\n",
 				indentStr, indentStr, symbol.Signature, indentStr))
 		}
 	}
 }
+
+// standardEntry describes a symbol at standard detail, such as
+// "func: func NewServer() *Server", truncating its signature to maxSignatureLength
+func standardEntry(symbol Symbol, maxSignatureLength int) string {
+	annotations := formatAnnotations(symbol)
+	signature := TruncateSignature(symbol.Signature, maxSignatureLength)
+	if signature == "" {
+		return fmt.Sprintf("%s: %s (lines %d-%d)%s", symbol.Kind, symbol.Name, symbol.StartLine, symbol.EndLine, annotations)
+	}
+	// For variables and constants, show name with type/signature
+	if symbol.Kind == "var" || symbol.Kind == "const" {
+		// Avoid duplicate names when signature equals name
+		if signature == symbol.Name {
+			return fmt.Sprintf("%s: %s%s", symbol.Kind, symbol.Name, annotations)
+		}
+		return fmt.Sprintf("%s: %s %s%s", symbol.Kind, symbol.Name, signature, annotations)
+	}
+	return fmt.Sprintf("%s: %s%s", symbol.Kind, signature, annotations)
+}
+
+// TruncateSignature shortens a signature to at most maxLength runes, cutting at the
+// last token boundary before the limit and appending an ellipsis. A maxLength of 0
+// leaves the signature unchanged.
+func TruncateSignature(signature string, maxLength int) string {
+	runes := []rune(signature)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return signature
+	}
+
+	cut := maxLength
+	for i := maxLength; i > maxLength/2; i-- {
+		if isTokenBoundary(runes[i-1], runes[i]) {
+			cut = i
+			break
+		}
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + "…"
+}
+
+// isTokenBoundary reports whether a signature can be cut between two runes without splitting an identifier or literal
+func isTokenBoundary(before, after rune) bool {
+	return !isIdentRune(before) || !isIdentRune(after)
+}
+
+// isIdentRune reports whether r can be part of an identifier or number
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// TruncateOutput shortens formatted output to at most maxBytes, cutting before a
+// symbol entry or heading so no symbol is split, and appends a footer counting the
+// symbols and files omitted. A file counts as omitted when any of its symbols is.
+// Output within the limit is returned unchanged.
+func TruncateOutput(output string, maxBytes int) string {
+	if maxBytes <= 0 || len(output) <= maxBytes {
+		return output
+	}
+
+	// Byte offsets of the lines where an entry starts, outside of code blocks
+	var boundaries []int
+	inCode := false
+	for offset := 0; offset < len(output); {
+		end := strings.IndexByte(output[offset:], '\n')
+		if end < 0 {
+			end = len(output) - offset
+		}
+		line := strings.TrimSpace(output[offset : offset+end])
+		switch {
+		case line == "```":
+			inCode = !inCode
+		case !inCode && (strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "#")):
+			boundaries = append(boundaries, offset)
+		}
+		offset += end + 1
+	}
+
+	cut := 0
+	for _, b := range boundaries {
+		if b > maxBytes {
+			break
+		}
+		cut = b
+	}
+
+	// Files are headed "## path", or "### path" when grouped under packages
+	fileHeading := "## "
+	if strings.Contains(output, "\n### ") {
+		fileHeading = "### "
+	}
+
+	omitted := output[cut:]
+	symbols, files := 0, 0
+	inFile := false
+	inCode = false
+	for _, line := range strings.Split(omitted, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "```":
+			inCode = !inCode
+		case inCode:
+		case strings.HasPrefix(line, fileHeading):
+			inFile = false
+		case strings.HasPrefix(line, "- "):
+			symbols++
+			if !inFile {
+				files++
+				inFile = true
+			}
+		}
+	}
+
+	return fmt.Sprintf("%s\n… output truncated at %d bytes: %d symbols omitted from %d files (%d more bytes)\n",
+		output[:cut], maxBytes, symbols, files, len(omitted))
+}
+
+// FormatTimings formats per-file extraction times, slowest file first
+func FormatTimings(timings []FileTiming) string {
+	sorted := make([]FileTiming, len(timings))
+	copy(sorted, timings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Parse+sorted[i].Query > sorted[j].Parse+sorted[j].Query
+	})
+
+	var total FileTiming
+	var sb strings.Builder
+	sb.WriteString("# Timings\n\n")
+	for _, t := range sorted {
+		sb.WriteString(fmt.Sprintf("- %s: total %s, parse %s, query %s, %d symbols\n",
+			t.FilePath, roundDuration(t.Parse+t.Query), roundDuration(t.Parse), roundDuration(t.Query), t.Symbols))
+		total.Parse += t.Parse
+		total.Query += t.Query
+		total.Symbols += t.Symbols
+	}
+	sb.WriteString(fmt.Sprintf("\n%d files: total %s, parse %s, query %s, %d symbols\n",
+		len(sorted), roundDuration(total.Parse+total.Query), roundDuration(total.Parse), roundDuration(total.Query), total.Symbols))
+
+	return sb.String()
+}
+
+// roundDuration rounds a duration for display
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
+
+// formatAnnotations renders the optional metadata attached to a symbol
+func formatAnnotations(symbol Symbol) string {
+	var parts []string
+	if symbol.Cell != nil {
+		cell := fmt.Sprintf("cell %d", symbol.Cell.Index)
+		if symbol.Cell.ExecutionCount != nil {
+			cell += fmt.Sprintf(", execution %d", *symbol.Cell.ExecutionCount)
+		}
+		parts = append(parts, cell)
+	}
+	if symbol.Deprecated {
+		parts = append(parts, "deprecated")
+	}
+	if symbol.Coverage != nil {
+		parts = append(parts, fmt.Sprintf("coverage: %.0f%% (%d/%d lines)",
+			symbol.Coverage.Percent(), symbol.Coverage.Covered, symbol.Coverage.Total))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// FormatStringLiterals formats string literals for output, grouped by file
+func FormatStringLiterals(literals []StringLiteral) string {
+	if len(literals) == 0 {
+		return "No string literals found"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# String Literals\n\n")
+
+	fileLiterals := make(map[string][]StringLiteral)
+	var files []string
+	for _, lit := range literals {
+		if _, ok := fileLiterals[lit.FilePath]; !ok {
+			files = append(files, lit.FilePath)
+		}
+		fileLiterals[lit.FilePath] = append(fileLiterals[lit.FilePath], lit)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		lits := fileLiterals[file]
+		sort.SliceStable(lits, func(i, j int) bool { return lits[i].Line < lits[j].Line })
+
+		sb.WriteString(fmt.Sprintf("## %s\n\n", file))
+		for _, lit := range lits {
+			location := fmt.Sprintf("line %d", lit.Line)
+			if lit.Enclosing != "" {
+				location += ", in " + lit.Enclosing
+			}
+			sb.WriteString(fmt.Sprintf("- %s: %s (%s)\n", lit.Kind, quoteLiteral(compactLiteral(lit.Value)), location))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// compactLiteral collapses whitespace and shortens long literal values
+func compactLiteral(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if runes := []rune(value); len(runes) > maxStringLiteralLength {
+		value = string(runes[:maxStringLiteralLength]) + "..."
+	}
+	return value
+}
+
+// quoteLiteral wraps a literal in backticks, falling back to Go quoting when it contains one
+func quoteLiteral(value string) string {
+	if strings.Contains(value, "`") {
+		return fmt.Sprintf("%q", value)
+	}
+	return "`" + value + "`"
+}
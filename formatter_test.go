@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	output := "# Symbol Outline\n\n## /src/a.go\n\n- func: main (line 3)\n- func: run (line 7)\n  - var: err (line 8)\n\n## /src/b.go\n\n- type: Config (line 5)\n"
+
+	if got := TruncateOutput(output, 0); got != output {
+		t.Errorf("expected no limit to leave the output unchanged")
+	}
+	if got := TruncateOutput(output, len(output)); got != output {
+		t.Errorf("expected output within the limit to be unchanged")
+	}
+
+	truncated := TruncateOutput(output, 60)
+	if !strings.HasPrefix(truncated, "# Symbol Outline\n\n## /src/a.go\n\n- func: main (line 3)\n\n…") {
+		t.Errorf("expected truncation before the first symbol that exceeds the limit:\n%s", truncated)
+	}
+	if !strings.Contains(truncated, "output truncated at 60 bytes") || !strings.Contains(truncated, "3 symbols omitted from 2 files") {
+		t.Errorf("expected a truncation footer counting the omitted symbols:\n%s", truncated)
+	}
+
+	full := "## /src/a.go\n\n- func (lines 1-3):\n  ```\n  func main() {\n- not a symbol\n}\n  ```\n- func (lines 5-6):\n"
+	if got := TruncateOutput(full, 50); strings.Contains(got, "not a symbol") || !strings.Contains(got, "2 symbols omitted from 1 files") {
+		t.Errorf("expected code blocks to be kept whole:\n%s", got)
+	}
+}
+
+func TestFormatTimings(t *testing.T) {
+	output := FormatTimings([]FileTiming{
+		{FilePath: "/src/small.go", Parse: time.Millisecond, Query: time.Millisecond, Symbols: 3},
+		{FilePath: "/src/huge.go", Parse: 40 * time.Millisecond, Query: 10 * time.Millisecond, Symbols: 900},
+	})
+
+	huge := strings.Index(output, "- /src/huge.go: total 50ms, parse 40ms, query 10ms, 900 symbols")
+	small := strings.Index(output, "- /src/small.go: total 2ms, parse 1ms, query 1ms, 3 symbols")
+	if huge < 0 || small < 0 || huge > small {
+		t.Errorf("expected files sorted slowest first:\n%s", output)
+	}
+	if !strings.Contains(output, "2 files: total 52ms, parse 41ms, query 11ms, 903 symbols") {
+		t.Errorf("expected a summary line:\n%s", output)
+	}
+}
+
+func TestTruncateSignature(t *testing.T) {
+	tests := []struct {
+		signature string
+		maxLength int
+		want      string
+	}{
+		{"func Run() error", 0, "func Run() error"},
+		{"func Run() error", 16, "func Run() error"},
+		{"func Process(items []Item, opts Options) error", 30, "func Process(items []Item,…"},
+		{"var defaults = Config{Name: \"glyph\", Timeout: 30}", 27, "var defaults = Config{Name:…"},
+		// Identifiers are only split when no boundary is close enough
+		{"func averyveryveryverylongidentifier()", 20, "func averyveryveryve…"},
+		{"func 挨拶(名前 string, 年齢 int)", 22, "func 挨拶(名前 string, 年齢…"},
+	}
+
+	for _, tt := range tests {
+		if got := TruncateSignature(tt.signature, tt.maxLength); got != tt.want {
+			t.Errorf("TruncateSignature(%q, %d) = %q, want %q", tt.signature, tt.maxLength, got, tt.want)
+		}
+	}
+
+	symbols := []Symbol{{Name: "Process", Kind: "func", Signature: "func Process(items []Item, opts Options) error", FilePath: "/src/a.go", StartLine: 1, EndLine: 3}}
+	if output := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Standard, MaxSignatureLength: 30}); !strings.Contains(output, "- func: func Process(items []Item,…\n") {
+		t.Errorf("expected standard output to truncate the signature:\n%s", output)
+	}
+	if output := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Full, MaxSignatureLength: 30}); !strings.Contains(output, "opts Options) error") {
+		t.Errorf("expected full detail code to be kept whole:\n%s", output)
+	}
+}
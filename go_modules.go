@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// goModule describes the Go module a directory belongs to
+type goModule struct {
+	Path string
+	Dir  string
+}
+
+// goModuleResolver finds the enclosing Go module of files, caching go.mod lookups per directory
+type goModuleResolver struct {
+	modules map[string]*goModule
+}
+
+// newGoModuleResolver creates a resolver with an empty cache
+func newGoModuleResolver() *goModuleResolver {
+	return &goModuleResolver{modules: make(map[string]*goModule)}
+}
+
+// moduleFor returns the module containing dir, or nil when dir is outside of any module
+func (r *goModuleResolver) moduleFor(dir string) *goModule {
+	dir = filepath.Clean(dir)
+	if mod, ok := r.modules[dir]; ok {
+		return mod
+	}
+
+	var mod *goModule
+	if path := readModulePath(filepath.Join(dir, "go.mod")); path != "" {
+		mod = &goModule{Path: path, Dir: dir}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		mod = r.moduleFor(parent)
+	}
+
+	r.modules[dir] = mod
+	return mod
+}
+
+// ImportPath returns the import path of the Go package containing a file,
+// or "" when the file is not inside a Go module
+func (r *goModuleResolver) ImportPath(filePath string) string {
+	dir := filepath.Dir(filePath)
+	mod := r.moduleFor(dir)
+	if mod == nil {
+		return ""
+	}
+
+	rel, err := filepath.Rel(mod.Dir, dir)
+	if err != nil {
+		return ""
+	}
+	if rel == "." {
+		return mod.Path
+	}
+	return mod.Path + "/" + filepath.ToSlash(rel)
+}
+
+// readModulePath returns the module path declared in a go.mod file
+func readModulePath(goModPath string) string {
+	f, err := os.Open(goModPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			rest, _, _ = strings.Cut(rest, "//")
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
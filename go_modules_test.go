@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoModuleResolver_ImportPath(t *testing.T) {
+	testDir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":          "module github.com/example/app // main module\n\ngo 1.22\n",
+		"tools/go.mod":    "module \"github.com/example/tools\"\n",
+		"main.go":         "package main\n",
+		"internal/db.go":  "package db\n",
+		"tools/lint.go":   "package tools\n",
+		"tools/x/gen.go":  "package x\n",
+		"../outside.go":   "package outside\n",
+		"scripts/tool.py": "print()\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(testDir, "repo", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"repo/main.go", "github.com/example/app"},
+		{"repo/internal/db.go", "github.com/example/app/internal"},
+		{"repo/tools/lint.go", "github.com/example/tools"},
+		{"repo/tools/x/gen.go", "github.com/example/tools/x"},
+		{"outside.go", ""},
+	}
+
+	resolver := newGoModuleResolver()
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := resolver.ImportPath(filepath.Join(testDir, tt.file)); got != tt.want {
+				t.Errorf("ImportPath(%s) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+
+	symbols := []Symbol{
+		{Name: "main", Kind: "func", StartLine: 1, EndLine: 1, FilePath: filepath.Join(testDir, "repo/main.go")},
+		{Name: "Open", Kind: "func", StartLine: 1, EndLine: 1, FilePath: filepath.Join(testDir, "repo/internal/db.go")},
+	}
+	result := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Minimal, GroupByPackage: true})
+
+	for _, expected := range []string{
+		"## github.com/example/app\n\n### " + filepath.Join(testDir, "repo/main.go"),
+		"## github.com/example/app/internal\n\n### " + filepath.Join(testDir, "repo/internal/db.go"),
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected result to contain %q.\nResult:\n%s", expected, result)
+		}
+	}
+}
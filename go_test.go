@@ -159,3 +159,62 @@ func TestGoFilePatterns(t *testing.T) {
 		}
 	}
 }
+
+func TestGoMethodReceivers(t *testing.T) {
+	extractor := NewSymbolExtractor()
+
+	tests := []struct {
+		file     string
+		expected map[string]string
+	}{
+		{
+			file: "testdata/go_basic.go.txt",
+			expected: map[string]string{
+				"Start":     "Server",
+				"GetConfig": "Server",
+				"main":      "",
+			},
+		},
+		{
+			file: "testdata/go_generics.go.txt",
+			expected: map[string]string{
+				"Push":   "Stack",
+				"String": "Pair",
+				"Keys":   "Cache",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.file), func(t *testing.T) {
+			symbols, err := extractor.ExtractFromFile(tt.file, Minimal)
+			if err != nil {
+				t.Fatalf("Failed to extract symbols: %v", err)
+			}
+
+			for _, sym := range symbols {
+				if receiver, ok := tt.expected[sym.Name]; ok && sym.Receiver != receiver {
+					t.Errorf("%s: Receiver = %q, want %q", sym.Name, sym.Receiver, receiver)
+				}
+			}
+		})
+	}
+
+	pattern, err := filepath.Abs("testdata/go_*.go.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExtractSymbols(pattern, ExtractOptions{Detail: Minimal, Receiver: "*Stack"})
+	if err != nil {
+		t.Fatalf("ExtractSymbols error = %v", err)
+	}
+	for _, method := range []string{"Push", "Pop", "Peek", "Size"} {
+		if !strings.Contains(result, "method: "+method) {
+			t.Errorf("expected Stack method %s in output:\n%s", method, result)
+		}
+	}
+	if strings.Contains(result, "Start") || strings.Contains(result, "Keys") {
+		t.Errorf("expected only Stack methods in output:\n%s", result)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestHCLSymbolExtraction(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/tf_basic.tf.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	expected := map[string]string{
+		"provider aws":                "provider \"aws\"",
+		"var region":                  "variable \"region\"",
+		"resource aws_s3_bucket.logs": "resource \"aws_s3_bucket\" \"logs\"",
+		"data aws_ami.ubuntu":         "data \"aws_ami\" \"ubuntu\"",
+		"module vpc":                  "module \"vpc\"",
+		"output bucket_arn":           "output \"bucket_arn\"",
+	}
+
+	found := make(map[string]string)
+	for _, sym := range symbols {
+		found[sym.Kind+" "+sym.Name] = sym.Signature
+	}
+	for key, signature := range expected {
+		if got, ok := found[key]; !ok {
+			t.Errorf("symbol %s not extracted", key)
+		} else if got != signature {
+			t.Errorf("%s: Signature = %q, want %q", key, got, signature)
+		}
+	}
+
+	// Nested blocks and attributes are configuration, not declarations
+	if len(symbols) != len(expected) {
+		t.Errorf("extracted %d symbols, want %d: %+v", len(symbols), len(expected), symbols)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import "sort"
+
+// SymbolNode is a symbol together with the symbols declared inside it
+type SymbolNode struct {
+	Symbol   Symbol
+	Children []*SymbolNode
+}
+
+// BuildHierarchy nests the symbols of a single file by source position: a symbol
+// whose range lies within another symbol's range becomes its child. Symbols with
+// identical ranges, such as a Go type reported both as "type" and as "struct",
+// stay siblings.
+func BuildHierarchy(symbols []Symbol) []*SymbolNode {
+	sorted := make([]Symbol, len(symbols))
+	copy(sorted, symbols)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return symbolBefore(sorted[i], sorted[j])
+	})
+
+	var roots []*SymbolNode
+	var stack []*SymbolNode
+	for _, sym := range sorted {
+		node := &SymbolNode{Symbol: sym}
+
+		for len(stack) > 0 && !containsSymbol(stack[len(stack)-1].Symbol, sym) {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+		stack = append(stack, node)
+	}
+
+	return roots
+}
+
+// symbolBefore orders symbols by start position, with enclosing symbols
+// coming before the symbols they contain
+func symbolBefore(a, b Symbol) bool {
+	if c := comparePositions(symbolStart(a), symbolStart(b)); c != 0 {
+		return c < 0
+	}
+	return comparePositions(symbolEnd(a), symbolEnd(b)) > 0
+}
+
+// symbolPosition orders symbols by notebook cell, then line and column
+type symbolPosition [3]uint32
+
+func symbolStart(sym Symbol) symbolPosition {
+	return symbolPosition{cellIndex(sym), sym.StartLine, sym.StartColumn}
+}
+
+func symbolEnd(sym Symbol) symbolPosition {
+	return symbolPosition{cellIndex(sym), sym.EndLine, sym.EndColumn}
+}
+
+// cellIndex returns the notebook cell of a symbol, or 0 outside of notebooks,
+// since line numbers of notebook symbols are relative to their cell
+func cellIndex(sym Symbol) uint32 {
+	if sym.Cell == nil {
+		return 0
+	}
+	return uint32(sym.Cell.Index)
+}
+
+// comparePositions returns -1, 0 or 1 as a is before, equal to or after b
+func comparePositions(a, b symbolPosition) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// containsSymbol reports whether inner lies within outer without spanning the same range
+func containsSymbol(outer, inner Symbol) bool {
+	startCmp := comparePositions(symbolStart(outer), symbolStart(inner))
+	endCmp := comparePositions(symbolEnd(inner), symbolEnd(outer))
+	if startCmp > 0 || endCmp > 0 {
+		return false
+	}
+	return startCmp != 0 || endCmp != 0
+}
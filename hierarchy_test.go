@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBuildHierarchy(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "getName", Kind: "method", StartLine: 5, StartColumn: 5, EndLine: 7, EndColumn: 6},
+		{Name: "User", Kind: "class", StartLine: 1, StartColumn: 1, EndLine: 12, EndColumn: 2},
+		{Name: "name", Kind: "var", StartLine: 6, StartColumn: 9, EndLine: 6, EndColumn: 20},
+		{Name: "main", Kind: "func", StartLine: 14, StartColumn: 1, EndLine: 16, EndColumn: 2},
+		{Name: "Config", Kind: "type", StartLine: 18, StartColumn: 6, EndLine: 20, EndColumn: 2},
+		{Name: "Config", Kind: "struct", StartLine: 18, StartColumn: 6, EndLine: 20, EndColumn: 2},
+	}
+
+	roots := BuildHierarchy(symbols)
+
+	var rootNames []string
+	for _, root := range roots {
+		rootNames = append(rootNames, root.Symbol.Kind+" "+root.Symbol.Name)
+	}
+	expected := "class User, func main, type Config, struct Config"
+	if got := strings.Join(rootNames, ", "); got != expected {
+		t.Fatalf("roots = %s, want %s", got, expected)
+	}
+
+	user := roots[0]
+	if len(user.Children) != 1 || user.Children[0].Symbol.Name != "getName" {
+		t.Fatalf("User children = %v, want getName", user.Children)
+	}
+	if method := user.Children[0]; len(method.Children) != 1 || method.Children[0].Symbol.Name != "name" {
+		t.Errorf("getName children = %v, want name", method.Children)
+	}
+
+	// Symbols with identical ranges are siblings
+	if len(roots[2].Children) != 0 || len(roots[3].Children) != 0 {
+		t.Errorf("expected identical ranges to stay siblings")
+	}
+}
+
+func TestBuildHierarchy_NotebookCells(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Model", Kind: "class", StartLine: 1, EndLine: 10, Cell: &NotebookCell{Index: 1}},
+		{Name: "load", Kind: "func", StartLine: 2, EndLine: 3, Cell: &NotebookCell{Index: 2}},
+	}
+
+	roots := BuildHierarchy(symbols)
+	if len(roots) != 2 {
+		t.Errorf("expected symbols of different cells not to nest, got %d roots", len(roots))
+	}
+}
+
+func TestFormatSymbols_Depth(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	symbols, err := extractor.ExtractFromFile("testdata/java_basic_class.java.txt", Minimal)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	nested := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Minimal})
+	if !strings.Contains(nested, "- class: BasicExample") || !strings.Contains(nested, "\n  - method: getName") {
+		t.Errorf("expected methods indented under their class:\n%s", nested)
+	}
+
+	topLevel := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Minimal, Depth: 1})
+	if !strings.Contains(topLevel, "- class: BasicExample") {
+		t.Errorf("expected class at depth 1:\n%s", topLevel)
+	}
+	if strings.Contains(topLevel, "getName") {
+		t.Errorf("expected methods to be hidden at depth 1:\n%s", topLevel)
+	}
+}
+
+func TestFormatSymbols_MaxSymbolsPerFile(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	symbols, err := extractor.ExtractFromFile("testdata/java_basic_class.java.txt", Minimal)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	result := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Minimal, MaxSymbolsPerFile: 3})
+
+	if got := strings.Count(result, "\n- ") + strings.Count(result, "\n  - "); got != 4 {
+		t.Errorf("expected 3 symbols and an overflow marker, got %d entries:\n%s", got, result)
+	}
+	expected := fmt.Sprintf("- … and %d more\n", len(symbols)-3)
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected overflow marker %q:\n%s", expected, result)
+	}
+	if !strings.Contains(result, "class: BasicExample") {
+		t.Errorf("expected the first symbols in source order:\n%s", result)
+	}
+}
+
+func TestFormatSymbols_Summarize(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Big", Kind: "class", FilePath: "big.java", StartLine: 1, EndLine: 100},
+		{Name: "small", Kind: "class", FilePath: "small.java", StartLine: 1, EndLine: 5},
+		{Name: "run", Kind: "method", FilePath: "small.java", StartLine: 2, EndLine: 4},
+	}
+	for i := 0; i < 20; i++ {
+		line := uint32(2 + i*4)
+		symbols = append(symbols, Symbol{Name: fmt.Sprintf("method%d", i), Kind: "method", FilePath: "big.java", StartLine: line, EndLine: line + 2})
+	}
+
+	full := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Minimal})
+	if unchanged := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Minimal, Summarize: len(full)}); unchanged != full {
+		t.Errorf("expected an outline within the threshold to be unchanged:\n%s", unchanged)
+	}
+
+	summary := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Minimal, Summarize: len(full) / 2})
+	if len(summary) >= len(full)/2 {
+		t.Errorf("expected the summary to fit in %d bytes, got %d:\n%s", len(full)/2, len(summary), summary)
+	}
+	for _, want := range []string{
+		"Collapsed 1 of 2 files to top-level symbols",
+		"- class: Big",
+		"- … 20 nested symbols collapsed to fit the outline\n",
+		"  - method: run",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "method0") {
+		t.Errorf("expected the methods of the largest file to be collapsed:\n%s", summary)
+	}
+}
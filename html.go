@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// extractInlineScripts extracts the functions and classes of the inline JavaScript
+// in an HTML document's <script> elements. Each script is parsed on its own from a
+// copy of the document with everything else blanked out, so symbol positions are
+// those of the HTML file.
+func (e *SymbolExtractor) extractInlineScripts(tree *sitter.Tree, content []byte, filePath string, detailLevel DetailLevel) ([]Symbol, error) {
+	var scripts []*sitter.Node
+	collectInlineScripts(tree.RootNode(), content, &scripts)
+
+	var allSymbols []Symbol
+	for _, script := range scripts {
+		code := blankOutside(content, script.StartByte(), script.EndByte())
+		scriptTree, err := e.parse(code, javascriptLanguageQueries)
+		if err != nil {
+			return nil, err
+		}
+
+		symbols, err := e.extractSymbolsFromTree(scriptTree, code, filePath, javascriptLanguageQueries, detailLevel)
+		if err != nil {
+			return nil, err
+		}
+		allSymbols = append(allSymbols, symbols...)
+	}
+
+	return allSymbols, nil
+}
+
+// collectInlineScripts appends the script text of every JavaScript <script>
+// element below node
+func collectInlineScripts(node *sitter.Node, content []byte, scripts *[]*sitter.Node) {
+	if node.Type() == "script_element" {
+		if isJavaScriptElement(node, content) {
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				if child := node.NamedChild(i); child.Type() == "raw_text" {
+					*scripts = append(*scripts, child)
+				}
+			}
+		}
+		return
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		collectInlineScripts(node.NamedChild(i), content, scripts)
+	}
+}
+
+// isJavaScriptElement reports whether a <script> element holds JavaScript rather
+// than data such as JSON or client-side templates
+func isJavaScriptElement(script *sitter.Node, content []byte) bool {
+	scriptType := strings.ToLower(htmlAttribute(script.NamedChild(0), "type", content))
+	return scriptType == "" || scriptType == "module" || strings.Contains(scriptType, "javascript")
+}
+
+// htmlAttribute returns the value of an attribute of a start tag, or ""
+func htmlAttribute(startTag *sitter.Node, name string, content []byte) string {
+	if startTag == nil || startTag.Type() != "start_tag" {
+		return ""
+	}
+	for i := 0; i < int(startTag.NamedChildCount()); i++ {
+		attr := startTag.NamedChild(i)
+		if attr.Type() != "attribute" || attr.NamedChildCount() == 0 {
+			continue
+		}
+		attrName := attr.NamedChild(0)
+		if !strings.EqualFold(string(content[attrName.StartByte():attrName.EndByte()]), name) {
+			continue
+		}
+		if attr.NamedChildCount() < 2 {
+			return ""
+		}
+		value := string(content[attr.NamedChild(1).StartByte():attr.NamedChild(1).EndByte()])
+		return strings.Trim(value, `"'`)
+	}
+	return ""
+}
+
+// blankOutside returns a copy of content up to end in which every byte outside
+// [start, end) is replaced by a space, keeping line breaks so lines and columns
+// are unchanged
+func blankOutside(content []byte, start, end uint32) []byte {
+	code := make([]byte, end)
+	for i := uint32(0); i < end; i++ {
+		switch {
+		case i >= start:
+			code[i] = content[i]
+		case content[i] == '\n' || content[i] == '\r':
+			code[i] = content[i]
+		default:
+			code[i] = ' '
+		}
+	}
+	return code
+}
+
+// htmlStartTag returns the start tag of an element on one line, such as
+// `<div id="app" class="root">`
+func htmlStartTag(node *sitter.Node, content []byte) string {
+	if node.Type() == "element" && node.NamedChildCount() > 0 {
+		node = node.NamedChild(0)
+	}
+	return strings.Join(strings.Fields(string(content[node.StartByte():node.EndByte()])), " ")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+)
+
+// reportTemplate is the page written by the html format
+//
+//go:embed report/report.html.tmpl
+var reportTemplate string
+
+var reportPage = template.Must(template.New("report").Parse(reportTemplate))
+
+// reportFile is a file of the HTML report and its top-level symbols
+type reportFile struct {
+	Path, Language, Package string
+	Symbols                 []*reportSymbol
+}
+
+// reportSymbol is a symbol of the HTML report and the symbols declared inside it
+type reportSymbol struct {
+	Kind, Label, Href string
+	Line              uint32
+	Children          []*reportSymbol
+}
+
+// formatHTMLReport writes symbols as a standalone HTML page with a collapsible
+// tree for each file and a search box that filters symbols by name, kind and
+// signature. Each symbol links to its line as path#L<line>, relative to root,
+// the form code browsers such as GitHub's use.
+func formatHTMLReport(symbols []Symbol, metadata map[string]*FileMetadata, root string) (string, error) {
+	var files []string
+	fileSymbols := make(map[string][]Symbol)
+	for _, sym := range symbols {
+		if _, ok := fileSymbols[sym.FilePath]; !ok {
+			files = append(files, sym.FilePath)
+		}
+		fileSymbols[sym.FilePath] = append(fileSymbols[sym.FilePath], sym)
+	}
+
+	title := "Symbol Outline"
+	if root != "" {
+		title += " of " + filepath.Base(root)
+	}
+	page := struct {
+		Title   string
+		Symbols int
+		Files   []reportFile
+	}{Title: title, Symbols: len(symbols)}
+
+	for _, file := range files {
+		path := file
+		if root != "" {
+			if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+				path = filepath.ToSlash(rel)
+			}
+		}
+		rf := reportFile{Path: path, Symbols: newReportSymbols(BuildHierarchy(fileSymbols[file]), path)}
+		if meta := metadata[file]; meta != nil {
+			rf.Language, rf.Package = meta.Language, meta.Package
+		}
+		page.Files = append(page.Files, rf)
+	}
+
+	var sb strings.Builder
+	if err := reportPage.Execute(&sb, page); err != nil {
+		return "", fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// newReportSymbols converts a symbol hierarchy to its HTML report form
+func newReportSymbols(nodes []*SymbolNode, path string) []*reportSymbol {
+	symbols := make([]*reportSymbol, 0, len(nodes))
+	for _, node := range nodes {
+		sym := node.Symbol
+		label := sym.Name
+		if sym.Signature != "" && sym.Kind != "var" && sym.Kind != "const" {
+			label = strings.Join(strings.Fields(sym.Signature), " ")
+		}
+		symbols = append(symbols, &reportSymbol{
+			Kind:     sym.Kind,
+			Label:    label,
+			Href:     fmt.Sprintf("%s#L%d", path, sym.StartLine),
+			Line:     sym.StartLine,
+			Children: newReportSymbols(node.Children, path),
+		})
+	}
+	return symbols
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatHTMLReport(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Repository", Kind: "interface", Signature: "interface Repository<T>", StartLine: 3, EndLine: 9, StartColumn: 1, EndColumn: 2, FilePath: "/proj/src/repo.ts"},
+		{Name: "find", Kind: "method", Signature: "find(id: string): T", StartLine: 4, EndLine: 4, StartColumn: 3, EndColumn: 20, FilePath: "/proj/src/repo.ts"},
+		{Name: "main", Kind: "func", Signature: "func main()", StartLine: 5, EndLine: 7, StartColumn: 1, EndColumn: 2, FilePath: "/proj/main.go"},
+	}
+	metadata := map[string]*FileMetadata{
+		"/proj/main.go": {Language: "go", Package: "main"},
+	}
+
+	got, err := formatHTMLReport(symbols, metadata, "/proj")
+	if err != nil {
+		t.Fatalf("formatHTMLReport: %v", err)
+	}
+
+	for _, want := range []string{
+		"<title>Symbol Outline of proj</title>",
+		"3 symbols in 2 files",
+		`<input id="search" type="search"`,
+		`<details class="file" open>`,
+		"<summary>src/repo.ts</summary>",
+		`<summary>main.go<span class="meta">go · main</span></summary>`,
+		`<a href="src/repo.ts#L3"><code>interface Repository&lt;T&gt;</code></a>`,
+		`<a href="main.go#L5"><code>func main()</code></a>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report does not contain %q:\n%s", want, got)
+		}
+	}
+
+	// The method is nested in the list of the interface
+	repository := strings.Index(got, "interface Repository")
+	find := strings.Index(got, "find(id: string): T")
+	if repository < 0 || find < 0 || !strings.Contains(got[repository:find], "<ul>") {
+		t.Errorf("method is not nested under its interface:\n%s", got)
+	}
+}
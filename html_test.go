@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestHTMLSymbolExtraction(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/html_basic.html.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	expected := map[string]string{
+		"element app":           `<div id="app" class="root">`,
+		"element top":           `<header id="top">`,
+		"element user-card":     `<user-card name="x">`,
+		"template row-template": `<template id="row-template">`,
+		"func render":           "function render(items)",
+		"class Widget":          "class Widget",
+		"method mount":          "mount()",
+	}
+
+	found := make(map[string]Symbol)
+	for _, sym := range symbols {
+		found[sym.Kind+" "+sym.Name] = sym
+	}
+	for key, signature := range expected {
+		if got, ok := found[key]; !ok {
+			t.Errorf("symbol %s not extracted", key)
+		} else if got.Signature != signature {
+			t.Errorf("%s: Signature = %q, want %q", key, got.Signature, signature)
+		}
+	}
+	if len(symbols) != len(expected) {
+		t.Errorf("extracted %d symbols, want %d", len(symbols), len(expected))
+	}
+
+	// Script symbols are positioned within the HTML file
+	if render := found["func render"]; render.StartLine != 15 || render.StartColumn != 5 {
+		t.Errorf("render at %d:%d, want 15:5", render.StartLine, render.StartColumn)
+	}
+}
+
+func TestIsJavaScriptElement(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	tests := []struct {
+		script string
+		want   bool
+	}{
+		{"<script>function a() {}</script>", true},
+		{`<script type="module">function a() {}</script>`, true},
+		{`<script type="text/javascript">function a() {}</script>`, true},
+		{`<script type="application/json">{"a": 1}</script>`, false},
+		{`<script type="text/x-template">function a() {}</script>`, false},
+	}
+
+	for _, tt := range tests {
+		symbols, err := extractor.ExtractFromContent("page.html", []byte(tt.script), htmlLanguageQueries, Standard)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.script, err)
+		}
+		if got := len(symbols) == 1; got != tt.want {
+			t.Errorf("%s: extracted %v, want script extracted = %v", tt.script, symbols, tt.want)
+		}
+	}
+}
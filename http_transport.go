@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sessionPrefix starts every session ID issued by signedSessionManager
+const sessionPrefix = "glyph-"
+
+// signedSessionManager issues session IDs signed with a shared secret, so every
+// replica behind a load balancer accepts the sessions of the others without
+// shared state, while forged IDs are rejected. Terminated sessions are only
+// remembered by the replica that terminated them.
+type signedSessionManager struct {
+	secret     []byte
+	terminated sync.Map
+}
+
+// newSignedSessionManager creates a session manager signing with secret, or with
+// a random secret when it is empty, which limits sessions to this process
+func newSignedSessionManager(secret string) (*signedSessionManager, error) {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate session secret: %w", err)
+		}
+	}
+	return &signedSessionManager{secret: key}, nil
+}
+
+// Generate returns a new session ID: a random nonce and its signature
+func (m *signedSessionManager) Generate() string {
+	nonce := make([]byte, 16)
+	// crypto/rand.Read never returns an error on supported platforms
+	_, _ = rand.Read(nonce)
+	id := hex.EncodeToString(nonce)
+	return sessionPrefix + id + "." + m.sign(id)
+}
+
+// Validate reports whether a session ID was issued with the secret, and whether
+// it has been terminated
+func (m *signedSessionManager) Validate(sessionID string) (bool, error) {
+	id, signature, ok := strings.Cut(strings.TrimPrefix(sessionID, sessionPrefix), ".")
+	if !ok || !strings.HasPrefix(sessionID, sessionPrefix) || !hmac.Equal([]byte(signature), []byte(m.sign(id))) {
+		return false, fmt.Errorf("invalid session id: %s", sessionID)
+	}
+	_, terminated := m.terminated.Load(sessionID)
+	return terminated, nil
+}
+
+// Terminate ends a session at the client's request
+func (m *signedSessionManager) Terminate(sessionID string) (bool, error) {
+	if _, err := m.Validate(sessionID); err != nil {
+		return false, err
+	}
+	m.terminated.Store(sessionID, struct{}{})
+	return false, nil
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of a session nonce
+func (m *signedSessionManager) sign(id string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// withCORS lets browsers on the allowed origins call handler, answering the
+// preflight requests of web-based clients and exposing the session header. An
+// origin of "*" allows any origin; with no origins, handler is returned as is.
+func withCORS(handler http.Handler, origins []string) http.Handler {
+	if len(origins) == 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && (slices.Contains(origins, "*") || slices.Contains(origins, origin)) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Authorization, Mcp-Session-Id, Mcp-Protocol-Version, Last-Event-ID")
+			w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
+			w.Header().Add("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// HTTPOptions configures the streamable HTTP transport of the MCP server
+type HTTPOptions struct {
+	// Addr is the address to listen on, such as ":8080"
+	Addr string
+	// Path is the endpoint path, "/mcp" by default
+	Path string
+	// SessionSecret signs session IDs; replicas sharing it accept each other's sessions
+	SessionSecret string
+	// Stateless issues no session IDs at all
+	Stateless bool
+	// AllowedOrigins are the browser origins allowed to call the server
+	AllowedOrigins []string
+}
+
+// newHTTPHandler returns the handler of the streamable HTTP transport, serving
+// MCP at the endpoint path of opts. Subscription requests go to subs, if any.
+func newHTTPHandler(mcpServer *server.MCPServer, subs *outlineSubscriptions, opts HTTPOptions) (http.Handler, error) {
+	var streamOpts []server.StreamableHTTPOption
+	var sessions *signedSessionManager
+	if opts.Stateless {
+		streamOpts = append(streamOpts, server.WithStateLess(true))
+	} else {
+		var err error
+		sessions, err = newSignedSessionManager(opts.SessionSecret)
+		if err != nil {
+			return nil, err
+		}
+		streamOpts = append(streamOpts, server.WithSessionIdManager(sessions))
+	}
+
+	var handler http.Handler = server.NewStreamableHTTPServer(mcpServer, streamOpts...)
+	if subs != nil {
+		handler = subs.withSubscriptions(handler, sessions)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(endpointPath(opts.Path), withCORS(handler, opts.AllowedOrigins))
+	return mux, nil
+}
+
+// endpointPath normalizes the endpoint path of the HTTP transport, "/mcp" by default
+func endpointPath(path string) string {
+	path = "/" + strings.Trim(path, "/")
+	if path == "/" {
+		return "/mcp"
+	}
+	return path
+}
+
+// serveHTTP serves MCP over the streamable HTTP transport until the process
+// receives SIGINT or SIGTERM. Like serveStdio, it then stops accepting tool
+// calls, lets the calls in flight finish for up to grace before cancelling them,
+// and returns once their responses have been written.
+func serveHTTP(mcpServer *server.MCPServer, tracker *requestTracker, subs *outlineSubscriptions, grace time.Duration, opts HTTPOptions) error {
+	handler, err := newHTTPHandler(mcpServer, subs, opts)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{Addr: opts.Addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdown := make(chan error, 1)
+	go func() {
+		<-signals.Done()
+		// Restore the default behavior, so a second signal terminates the process
+		stop()
+		fmt.Fprintln(os.Stderr, "Shutting down: waiting for in-flight requests")
+
+		drained := tracker.drain()
+		select {
+		case <-drained:
+		case <-time.After(grace):
+			fmt.Fprintln(os.Stderr, "Shutting down: cancelling in-flight requests")
+			tracker.cancel()
+			<-drained
+		}
+		// Responses of drained calls are written before their connections close
+		ctx, cancel := context.WithTimeout(context.Background(), grace)
+		defer cancel()
+		shutdown <- httpServer.Shutdown(ctx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving MCP over HTTP at %s%s\n", opts.Addr, endpointPath(opts.Path))
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-shutdown
+}
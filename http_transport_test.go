@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSignedSessionManager(t *testing.T) {
+	replica, err := newSignedSessionManager("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := newSignedSessionManager("secret")
+	stranger, _ := newSignedSessionManager("")
+
+	id := replica.Generate()
+	if !strings.HasPrefix(id, sessionPrefix) || id == replica.Generate() {
+		t.Fatalf("expected unique prefixed session IDs, got %q", id)
+	}
+	if terminated, err := other.Validate(id); err != nil || terminated {
+		t.Errorf("expected a replica sharing the secret to accept the session, got %v, %v", terminated, err)
+	}
+	if _, err := stranger.Validate(id); err == nil {
+		t.Error("expected a different secret to reject the session")
+	}
+	for _, forged := range []string{"", "glyph-abc", "glyph-abc.def", id + "0", "mcp-session-" + strings.TrimPrefix(id, sessionPrefix)} {
+		if _, err := replica.Validate(forged); err == nil {
+			t.Errorf("expected %q to be rejected", forged)
+		}
+	}
+
+	if _, err := replica.Terminate(id); err != nil {
+		t.Fatal(err)
+	}
+	if terminated, err := replica.Validate(id); err != nil || !terminated {
+		t.Errorf("expected the session to be terminated, got %v, %v", terminated, err)
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	mcpServer := server.NewMCPServer("glyph", "1.0.0", server.WithToolCapabilities(false))
+	opts := HTTPOptions{SessionSecret: "secret", AllowedOrigins: []string{"https://agent.example"}}
+	first, err := newHTTPHandler(mcpServer, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := newHTTPHandler(mcpServer, nil, opts)
+
+	post := func(handler http.Handler, sessionID, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Origin", "https://agent.example")
+		if sessionID != "" {
+			request.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	initialized := post(first, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	sessionID := initialized.Header().Get("Mcp-Session-Id")
+	if initialized.Code != http.StatusOK || sessionID == "" {
+		t.Fatalf("initialize: status %d, session %q: %s", initialized.Code, sessionID, initialized.Body)
+	}
+	if initialized.Header().Get("Access-Control-Allow-Origin") != "https://agent.example" || initialized.Header().Get("Access-Control-Expose-Headers") != "Mcp-Session-Id" {
+		t.Errorf("expected CORS headers for the allowed origin, got %v", initialized.Header())
+	}
+
+	// Another replica behind the load balancer continues the session
+	if listed := post(second, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`); listed.Code != http.StatusOK || !strings.Contains(listed.Body.String(), `"tools"`) {
+		t.Errorf("tools/list on another replica: status %d: %s", listed.Code, listed.Body)
+	}
+	if forged := post(second, "mcp-session-forged", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`); forged.Code != http.StatusBadRequest {
+		t.Errorf("expected a forged session to be rejected, got status %d", forged.Code)
+	}
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/mcp", nil)
+	preflight.Header.Set("Origin", "https://elsewhere.example")
+	recorder := httptest.NewRecorder()
+	first.ServeHTTP(recorder, preflight)
+	if recorder.Code != http.StatusNoContent || recorder.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected no CORS grant for another origin, got status %d, %v", recorder.Code, recorder.Header())
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Reference categories reported by impact analysis
+const (
+	ReferenceProduction = "production"
+	ReferenceTests      = "tests"
+	ReferenceDocs       = "docs"
+)
+
+// referenceCategories lists the categories in the order they are reported
+var referenceCategories = []string{ReferenceProduction, ReferenceTests, ReferenceDocs}
+
+// FindReferencesInFile finds identifier nodes named name, plus comments mentioning it
+func (e *SymbolExtractor) FindReferencesInFile(filePath, name string) ([]Reference, error) {
+	tree, content, _, err := e.parseFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return referencesInTree(tree, content, filePath, name), nil
+}
+
+// referencesInTree finds the references to name in the parsed tree of a file's content
+func referencesInTree(tree *sitter.Tree, content []byte, filePath, name string) []Reference {
+	category := ReferenceProduction
+	if IsTestFile(filePath) {
+		category = ReferenceTests
+	}
+
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	lines := strings.Split(string(content), "\n")
+
+	var refs []Reference
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		nodeType := node.Type()
+		switch {
+		case isCommentNode(nodeType):
+			text := string(content[node.StartByte():node.EndByte()])
+			for i, line := range strings.Split(text, "\n") {
+				if word.MatchString(line) {
+					refs = append(refs, newReference(filePath, node.StartPoint().Row+uint32(i)+1, ReferenceDocs, lines))
+				}
+			}
+			return
+		case strings.HasSuffix(nodeType, "identifier") && node.ChildCount() == 0:
+			if string(content[node.StartByte():node.EndByte()]) == name {
+				refs = append(refs, newReference(filePath, node.StartPoint().Row+1, category, lines))
+			}
+			return
+		}
+
+		for i := 0; i < int(node.ChildCount()); i++ {
+			walk(node.Child(i))
+		}
+	}
+	walk(tree.RootNode())
+
+	return refs
+}
+
+// newReference creates a Reference with the trimmed source line as context
+func newReference(filePath string, line uint32, category string, lines []string) Reference {
+	ref := Reference{
+		FilePath: filePath,
+		Line:     line,
+		Category: category,
+	}
+	if int(line) <= len(lines) {
+		ref.Context = strings.TrimSpace(lines[line-1])
+	}
+	return ref
+}
+
+// isCommentNode reports whether a node type is a comment in any supported grammar
+func isCommentNode(nodeType string) bool {
+	switch nodeType {
+	case "comment", "line_comment", "block_comment":
+		return true
+	}
+	return false
+}
+
+// AnalyzeImpact lists every reference to a symbol name in files matching a pattern,
+// found as opts.Discovery selects and parsed by opts.Jobs workers
+func AnalyzeImpact(name, pattern string, opts ExtractOptions) (string, error) {
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
+	if err != nil {
+		return "", fmt.Errorf("failed to find files: %w", err)
+	}
+
+	if len(files) == 0 {
+		return "No files found matching pattern: " + pattern, nil
+	}
+
+	// References are found by walking the tree, so no custom query applies
+	opts.Query = ""
+	outcomes, stop := extractConcurrently(context.Background(), files, opts, func(extractor *SymbolExtractor, file string) fileOutcome {
+		refs, err := extractor.FindReferencesInFile(file, name)
+		return fileOutcome{refs: refs, err: err}
+	})
+	defer stop()
+
+	var allRefs []Reference
+	for i := range files {
+		outcome := <-outcomes[i]
+		if outcome.err != nil {
+			continue // Skip files that can't be parsed
+		}
+		allRefs = append(allRefs, outcome.refs...)
+	}
+
+	if len(allRefs) == 0 {
+		return fmt.Sprintf("No references to %s found", name), nil
+	}
+
+	return FormatImpact(name, allRefs), nil
+}
+
+// FormatImpact formats references grouped by category and file
+func FormatImpact(name string, refs []Reference) string {
+	byCategory := make(map[string][]Reference)
+	files := make(map[string]bool)
+	for _, ref := range refs {
+		byCategory[ref.Category] = append(byCategory[ref.Category], ref)
+		files[ref.FilePath] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Impact of %s\n\n", name))
+
+	var counts []string
+	for _, category := range referenceCategories {
+		counts = append(counts, fmt.Sprintf("%s: %d", category, len(byCategory[category])))
+	}
+	sb.WriteString(fmt.Sprintf("%d references in %d files (%s)\n\n", len(refs), len(files), strings.Join(counts, ", ")))
+
+	for _, category := range referenceCategories {
+		catRefs := byCategory[category]
+		if len(catRefs) == 0 {
+			continue
+		}
+
+		sort.SliceStable(catRefs, func(i, j int) bool {
+			if catRefs[i].FilePath != catRefs[j].FilePath {
+				return catRefs[i].FilePath < catRefs[j].FilePath
+			}
+			return catRefs[i].Line < catRefs[j].Line
+		})
+
+		sb.WriteString(fmt.Sprintf("## %s%s (%d)\n", strings.ToUpper(category[:1]), category[1:], len(catRefs)))
+
+		currentFile := ""
+		for _, ref := range catRefs {
+			if ref.FilePath != currentFile {
+				currentFile = ref.FilePath
+				sb.WriteString(fmt.Sprintf("\n### %s\n\n", currentFile))
+			}
+			sb.WriteString(fmt.Sprintf("- line %d: %s\n", ref.Line, ref.Context))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
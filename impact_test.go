@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeImpact(t *testing.T) {
+	testDir := t.TempDir()
+
+	testFiles := map[string]string{
+		"server.go": `package app
+
+// NewServer creates a server
+func NewServer() *Server {
+	return &Server{}
+}
+
+// NewServerless is unrelated
+var label = "NewServer"
+`,
+		"main.go": `package app
+
+func main() {
+	s := NewServer()
+	s.Start()
+}
+`,
+		"server_test.go": `package app
+
+func TestNewServer(t *testing.T) {
+	_ = NewServer()
+}
+`,
+	}
+
+	for name, code := range testFiles {
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	extractor := NewSymbolExtractor()
+	refs, err := extractor.FindReferencesInFile(filepath.Join(testDir, "server.go"), "NewServer")
+	if err != nil {
+		t.Fatalf("FindReferencesInFile error = %v", err)
+	}
+
+	// The string literal and the NewServerless comment must not match
+	if len(refs) != 2 {
+		t.Fatalf("Expected 2 references in server.go, got %d: %+v", len(refs), refs)
+	}
+	if refs[0].Category != ReferenceDocs || refs[0].Line != 3 {
+		t.Errorf("Expected doc comment reference on line 3, got %+v", refs[0])
+	}
+	if refs[1].Category != ReferenceProduction || refs[1].Line != 4 {
+		t.Errorf("Expected production reference on line 4, got %+v", refs[1])
+	}
+
+	result, err := AnalyzeImpact("NewServer", filepath.Join(testDir, "*.go"), ExtractOptions{})
+	if err != nil {
+		t.Fatalf("AnalyzeImpact error = %v", err)
+	}
+
+	expected := []string{
+		"4 references in 3 files (production: 2, tests: 1, docs: 1)",
+		"## Production (2)",
+		"- line 4: s := NewServer()",
+		"## Tests (1)",
+		"- line 4: _ = NewServer()",
+		"## Docs (1)",
+		"- line 3: // NewServer creates a server",
+	}
+	for _, e := range expected {
+		if !strings.Contains(result, e) {
+			t.Errorf("Expected result to contain %q.\nResult:\n%s", e, result)
+		}
+	}
+
+	// The output doesn't depend on the number of workers
+	parallel, err := AnalyzeImpact("NewServer", filepath.Join(testDir, "*.go"), ExtractOptions{Jobs: 4})
+	if err != nil || parallel != result {
+		t.Errorf("AnalyzeImpact with 4 jobs = %q, %v, want\n%s", parallel, err, result)
+	}
+
+	// Files are found with the discovery options
+	result, err = AnalyzeImpact("NewServer", filepath.Join(testDir, "*.go"), ExtractOptions{Discovery: DiscoveryOptions{ExcludeTests: true}})
+	if err != nil {
+		t.Fatalf("AnalyzeImpact error = %v", err)
+	}
+	if !strings.Contains(result, "3 references in 2 files (production: 2, tests: 0, docs: 1)") {
+		t.Errorf("Expected test files to be skipped.\nResult:\n%s", result)
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IndexVersion is the format version of index snapshots
+const IndexVersion = 7
+
+// Index is a snapshot of the symbols extracted from a directory tree.
+// Paths are stored relative to the root so a snapshot built in CI can be
+// loaded on a developer machine where the checkout lives elsewhere.
+type Index struct {
+	Version   int                    `json:"version"`
+	Root      string                 `json:"root"`
+	Detail    string                 `json:"detail"`
+	CreatedAt time.Time              `json:"created_at"`
+	Files     map[string]*IndexEntry `json:"files"`
+}
+
+// IndexEntry holds the symbols of one file along with the hash of the content they were extracted from
+type IndexEntry struct {
+	Hash    string   `json:"hash"`
+	Symbols []Symbol `json:"symbols"`
+}
+
+// BuildIndex extracts symbols from files matching a pattern, found as
+// opts.Discovery selects and opts.Jobs at a time, into an index rooted at root
+func BuildIndex(root, pattern string, opts ExtractOptions) (*Index, error) {
+	idx, _, err := buildIndex(root, pattern, opts, nil)
+	return idx, err
+}
+
+// BuildLocalIndex builds the local index for root from the files matching a
+// pattern, which extractions below root then use for unchanged files, and
+// returns it with the number of files parsed. Files whose content is the same as
+// when the previous local index was built keep their symbols without being parsed.
+func BuildLocalIndex(root, pattern string, opts ExtractOptions) (*Index, int, error) {
+	path, err := localIndexPath(filepath.Clean(root))
+	if err != nil {
+		return nil, 0, err
+	}
+	previous, err := ReadIndex(path)
+	if err != nil || previous.Detail != opts.Detail.String() {
+		previous = nil
+	}
+
+	idx, parsed, err := buildIndex(root, pattern, opts, previous)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, 0, err
+	}
+	if err := idx.Write(path); err != nil {
+		return nil, 0, err
+	}
+	return idx, parsed, nil
+}
+
+// buildIndex builds an index, taking the entries of files that are unchanged
+// since previous was built from it, and returns the number of files parsed
+func buildIndex(root, pattern string, opts ExtractOptions, previous *Index) (*Index, int, error) {
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find files: %w", err)
+	}
+
+	idx := &Index{
+		Version:   IndexVersion,
+		Root:      filepath.Clean(root),
+		Detail:    opts.Detail.String(),
+		CreatedAt: time.Now().UTC(),
+		Files:     make(map[string]*IndexEntry),
+	}
+
+	// Files outside of the index root are skipped, but not all of them, which
+	// would leave an empty index for a root that doesn't match the pattern
+	var rels []string
+	matched := len(files)
+	indexed := files[:0]
+	for _, file := range files {
+		if rel, ok := idx.relativePath(file); ok {
+			indexed = append(indexed, file)
+			rels = append(rels, rel)
+		}
+	}
+	if matched > 0 && len(indexed) == 0 {
+		return nil, 0, fmt.Errorf("none of the %d files matching %s is under the root %s", matched, pattern, idx.Root)
+	}
+
+	// Only the symbols of a file go into the index, so nothing is logged or cached
+	opts.Log, opts.Cache, opts.Query = nil, nil, ""
+	outcomes, stop := extractConcurrently(context.Background(), indexed, opts, func(extractor *SymbolExtractor, file string) fileOutcome {
+		return extractOutcome(extractor, previous, opts, file, opts.Detail)
+	})
+	defer stop()
+
+	parsed := 0
+	for i, rel := range rels {
+		outcome := <-outcomes[i]
+		if outcome.indexed {
+			idx.Files[rel] = previous.Files[rel]
+			continue
+		}
+		if outcome.err != nil {
+			continue // Skip files that can't be parsed
+		}
+		parsed++
+
+		symbols := outcome.symbols
+		AssignSymbolIDs(symbols, idx.Root)
+		for i := range symbols {
+			symbols[i].FilePath = rel
+		}
+		idx.Files[rel] = &IndexEntry{
+			Hash:    hashContent(outcome.content),
+			Symbols: symbols,
+		}
+	}
+
+	return idx, parsed, nil
+}
+
+// Query returns the indexed symbols whose name matches name, as selected by mode
+// (NameMatchRegex, NameMatchExact, NameMatchGlob or NameMatchFuzzy), with their
+// absolute paths below the index root. Fuzzy matches are ranked best first and
+// other matches are ordered by path and line. Nothing is parsed, so the symbols
+// are those of the files when the index was built.
+func (idx *Index) Query(name, mode string) ([]Symbol, error) {
+	var symbols []Symbol
+	for rel, entry := range idx.Files {
+		for _, sym := range entry.Symbols {
+			sym.FilePath = filepath.Join(idx.Root, filepath.FromSlash(rel))
+			symbols = append(symbols, sym)
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].FilePath != symbols[j].FilePath {
+			return symbols[i].FilePath < symbols[j].FilePath
+		}
+		return symbolBefore(symbols[i], symbols[j])
+	})
+
+	if mode != NameMatchFuzzy {
+		return filterByName(symbols, name, mode)
+	}
+	ranked := RankByFuzzyName(symbols, name)
+	matched := make([]Symbol, len(ranked))
+	for i, r := range ranked {
+		matched[i] = r.Symbol
+	}
+	return matched, nil
+}
+
+// ReadIndex reads an index snapshot from disk
+func ReadIndex(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	var idx Index
+	if err := json.NewDecoder(gz).Decode(&idx); err != nil {
+		return nil, fmt.Errorf("invalid index %s: %w", path, err)
+	}
+	if idx.Version != IndexVersion {
+		return nil, fmt.Errorf("unsupported index version %d in %s", idx.Version, path)
+	}
+	if idx.Files == nil {
+		idx.Files = make(map[string]*IndexEntry)
+	}
+
+	return &idx, nil
+}
+
+// Write writes the index as gzip-compressed JSON, replacing the file atomically
+func (idx *Index) Write(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".glyphidx-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	gz := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(gz).Encode(idx); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Lookup returns the indexed symbols of a file if the index holds them for the same content
+func (idx *Index) Lookup(filePath string, content []byte) ([]Symbol, bool) {
+	rel, ok := idx.relativePath(filePath)
+	if !ok {
+		return nil, false
+	}
+
+	entry, ok := idx.Files[rel]
+	if !ok || entry.Hash != hashContent(content) {
+		return nil, false
+	}
+
+	symbols := make([]Symbol, len(entry.Symbols))
+	copy(symbols, entry.Symbols)
+	for i := range symbols {
+		symbols[i].FilePath = filePath
+	}
+	return symbols, true
+}
+
+// relativePath converts a file path to the slash-separated path used as an index key
+func (idx *Index) relativePath(filePath string) (string, bool) {
+	rel, err := filepath.Rel(idx.Root, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// hashContent returns the hex-encoded SHA-256 of file content
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheDir returns the directory glyph stores local data in.
+// GLYPH_CACHE_DIR overrides the user cache directory.
+func cacheDir() (string, error) {
+	if dir := os.Getenv("GLYPH_CACHE_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "glyph"), nil
+}
+
+// localIndexPath returns where the imported index for a root directory is stored
+func localIndexPath(root string) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(root)))
+	return filepath.Join(dir, "indexes", hex.EncodeToString(sum[:8])+".glyphidx"), nil
+}
+
+// ImportIndex installs an index snapshot as the local index for root
+func ImportIndex(snapshot, root string) (*Index, error) {
+	idx, err := ReadIndex(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	idx.Root = filepath.Clean(root)
+
+	path, err := localIndexPath(idx.Root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := idx.Write(path); err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// FindLocalIndex returns the imported index covering dir, searching dir and its parents
+func FindLocalIndex(dir string) *Index {
+	dir = filepath.Clean(dir)
+	for {
+		if path, err := localIndexPath(dir); err == nil {
+			if idx, err := readLocalIndex(path); err == nil {
+				return idx
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// localIndexes caches the local indexes FindLocalIndex read, so that extractions,
+// such as every MCP tool call, decode an index again only once it is rewritten.
+// The cached indexes are shared, and only read.
+var localIndexes = struct {
+	sync.Mutex
+	entries map[string]localIndex
+}{entries: make(map[string]localIndex)}
+
+// localIndex is a local index, or the error reading it, as of its file's modification
+type localIndex struct {
+	modTime time.Time
+	size    int64
+	idx     *Index
+	err     error
+}
+
+// readLocalIndex reads the index stored at path, reusing the index read last while
+// the file's modification time and size are unchanged
+func readLocalIndex(path string) (*Index, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	localIndexes.Lock()
+	cached, ok := localIndexes.entries[path]
+	localIndexes.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.idx, cached.err
+	}
+
+	idx, err := ReadIndex(path)
+	localIndexes.Lock()
+	localIndexes.entries[path] = localIndex{modTime: info.ModTime(), size: info.Size(), idx: idx, err: err}
+	localIndexes.Unlock()
+	return idx, err
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain keeps the tests off the user cache directory, where local indexes of
+// real checkouts would be used by the extractions under test
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "glyph-cache-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("GLYPH_CACHE_DIR", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestIndexExportImport(t *testing.T) {
+	t.Setenv("GLYPH_CACHE_DIR", t.TempDir())
+
+	// Build the snapshot in one checkout ("CI") ...
+	ciRoot := t.TempDir()
+	code := "package app\n\nfunc Start() {}\n\nfunc Stop() {}\n"
+	if err := os.WriteFile(filepath.Join(ciRoot, "app.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := BuildIndex(ciRoot, filepath.Join(ciRoot, "*.go"), ExtractOptions{Detail: Minimal})
+	if err != nil {
+		t.Fatalf("BuildIndex error = %v", err)
+	}
+	// A root that none of the matched files is under is an error, not an empty index
+	if _, err := BuildIndex(t.TempDir(), filepath.Join(ciRoot, "*.go"), ExtractOptions{Detail: Minimal}); err == nil || !strings.Contains(err.Error(), "under the root") {
+		t.Errorf("Expected an error for a root outside the pattern, got %v", err)
+	}
+
+	entry, ok := idx.Files["app.go"]
+	if !ok || len(entry.Symbols) != 3 {
+		t.Fatalf("Expected 3 indexed symbols for app.go, got %+v", idx.Files)
+	}
+	if entry.Symbols[0].FilePath != "app.go" {
+		t.Errorf("Expected indexed paths to be relative, got %s", entry.Symbols[0].FilePath)
+	}
+
+	// Mark the indexed symbols so the test can tell whether the index was used
+	for i := range entry.Symbols {
+		entry.Symbols[i].Name += "Indexed"
+	}
+
+	snapshot := filepath.Join(t.TempDir(), "app.glyphidx")
+	if err := idx.Write(snapshot); err != nil {
+		t.Fatalf("Write error = %v", err)
+	}
+
+	// ... and load it in another checkout
+	localRoot := t.TempDir()
+	localFile := filepath.Join(localRoot, "app.go")
+	if err := os.WriteFile(localFile, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ImportIndex(snapshot, localRoot); err != nil {
+		t.Fatalf("ImportIndex error = %v", err)
+	}
+
+	result, err := ExtractSymbols(filepath.Join(localRoot, "*.go"), ExtractOptions{Detail: Minimal})
+	if err != nil {
+		t.Fatalf("ExtractSymbols error = %v", err)
+	}
+	if !strings.Contains(result, "StartIndexed") || !strings.Contains(result, "## "+localFile) {
+		t.Errorf("Expected symbols to come from the imported index.\nResult:\n%s", result)
+	}
+
+	// A different detail level can't be served from the index
+	result, err = ExtractSymbols(filepath.Join(localRoot, "*.go"), ExtractOptions{Detail: Full})
+	if err != nil {
+		t.Fatalf("ExtractSymbols error = %v", err)
+	}
+	if strings.Contains(result, "Indexed") {
+		t.Errorf("Expected full extraction to bypass the minimal index.\nResult:\n%s", result)
+	}
+
+	// Changed files are parsed again
+	if err := os.WriteFile(localFile, []byte(code+"\nfunc Restart() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = ExtractSymbols(filepath.Join(localRoot, "*.go"), ExtractOptions{Detail: Minimal})
+	if err != nil {
+		t.Fatalf("ExtractSymbols error = %v", err)
+	}
+	if strings.Contains(result, "Indexed") || !strings.Contains(result, "Restart") {
+		t.Errorf("Expected changed file to be re-parsed.\nResult:\n%s", result)
+	}
+}
+
+func TestReadIndex_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.glyphidx")
+	if err := os.WriteFile(path, []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadIndex(path); err == nil {
+		t.Errorf("Expected error for invalid index")
+	}
+}
+
+func TestBuildLocalIndex(t *testing.T) {
+	t.Setenv("GLYPH_CACHE_DIR", t.TempDir())
+	root := t.TempDir()
+	files := map[string]string{
+		"server.go":   "package app\n\nfunc NewServer() {}\n\nfunc (s *Server) Start() {}\n",
+		"pkg/user.py": "class UserService:\n    def find(self):\n        pass\n",
+		// Discovery leaves out hidden, vendored and generated files, as in other extractions
+		".venv/lib/site.py": "def hidden():\n    pass\n",
+		"go.mod":            "module example.com/app\n",
+		"vendor/dep/dep.go": "package dep\n\nfunc Vendored() {}\n",
+		"api/service.pb.go": "package api\n\nfunc Generated() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if idx, parsed, err := BuildLocalIndex(root, filepath.Join(root, "**"), ExtractOptions{Detail: Standard}); err != nil || parsed != 2 || len(idx.Files) != 2 {
+		t.Fatalf("BuildLocalIndex = %d parsed, %v; want 2", parsed, err)
+	}
+	if idx, err := BuildIndex(root, filepath.Join(root, "**"), ExtractOptions{Detail: Standard, Discovery: DiscoveryOptions{Exclude: []string{"!pkg/**"}}}); err != nil || len(idx.Files) != 1 {
+		t.Fatalf("BuildIndex excluding pkg = %v, %v; want only server.go", idx, err)
+	}
+	// The index is read once while it is unchanged
+	if first := FindLocalIndex(root); first == nil || FindLocalIndex(filepath.Join(root, "pkg")) != first {
+		t.Fatal("Expected the local index to be read once and reused")
+	}
+
+	// Rebuilding parses only the changed file, and extractions see the new index
+	if err := os.WriteFile(filepath.Join(root, "server.go"), []byte(files["server.go"]+"\nfunc (s *Server) Stop() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, parsed, err := BuildLocalIndex(root, filepath.Join(root, "**"), ExtractOptions{Detail: Standard, Jobs: 4}); err != nil || parsed != 1 {
+		t.Fatalf("BuildLocalIndex after a change = %d parsed, %v; want 1", parsed, err)
+	}
+
+	idx := FindLocalIndex(filepath.Join(root, "pkg"))
+	if idx == nil {
+		t.Fatal("Expected the index to cover the root's subdirectories")
+	}
+	tests := []struct {
+		name, mode string
+		want       []string
+	}{
+		{"usrSvc", NameMatchFuzzy, []string{"UserService"}},
+		{"St*", NameMatchGlob, []string{"Start", "Stop"}},
+		{"NewServer", NameMatchExact, []string{"NewServer"}},
+		{"^find$", NameMatchRegex, []string{"find"}},
+	}
+	for _, tt := range tests {
+		symbols, err := idx.Query(tt.name, tt.mode)
+		if err != nil {
+			t.Fatalf("Query(%q, %s) error = %v", tt.name, tt.mode, err)
+		}
+		var names []string
+		for _, sym := range symbols {
+			names = append(names, sym.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Query(%q, %s) = %v, want %v", tt.name, tt.mode, names, tt.want)
+		}
+	}
+
+	symbols, _ := idx.Query("find", NameMatchExact)
+	if len(symbols) != 1 || symbols[0].FilePath != filepath.Join(root, "pkg", "user.py") {
+		t.Errorf("Expected queried symbols to have absolute paths, got %+v", symbols)
+	}
+	if _, err := idx.Query("(", NameMatchRegex); err == nil {
+		t.Error("Expected an error for an invalid name pattern")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import "encoding/json"
+
+// jsonOutline is the document written by the json output format, described by
+// the "outline" definition of the output schema
+type jsonOutline struct {
+	SchemaVersion int        `json:"schema_version"`
+	Status        string     `json:"status"`
+	Files         int        `json:"files"`
+	Symbols       int        `json:"symbols"`
+	Warnings      []string   `json:"warnings,omitempty"`
+	NextCursor    string     `json:"next_cursor,omitempty"`
+	Outline       []jsonFile `json:"outline"`
+}
+
+// jsonFile holds the metadata and nested symbols of one file
+type jsonFile struct {
+	Path string `json:"file"`
+	*FileMetadata
+	Symbols []*jsonSymbol `json:"symbols"`
+}
+
+// jsonSymbol is a symbol together with the symbols declared inside it
+type jsonSymbol struct {
+	Symbol
+	// Score ranks fuzzy name matches, best first
+	Score    int           `json:"score,omitempty"`
+	Children []*jsonSymbol `json:"children,omitempty"`
+}
+
+// setJSONOutput replaces the output of a result with a JSON document listing
+// symbols by file, nested as in the outline
+func (r *ExtractionResult) setJSONOutput(symbols []Symbol, metadata map[string]*FileMetadata, scores map[string]int) error {
+	output, err := json.MarshalIndent(r.newJSONOutline(symbols, metadata, scores), "", "  ")
+	if err != nil {
+		return err
+	}
+	r.Output = string(output) + "\n"
+	return nil
+}
+
+// newJSONOutline builds the json format's document of a result's symbols. Files
+// are listed in the order of their first symbol, so ranked matches keep their best
+// file first; scores are keyed by symbol ID.
+func (r *ExtractionResult) newJSONOutline(symbols []Symbol, metadata map[string]*FileMetadata, scores map[string]int) *jsonOutline {
+	doc := &jsonOutline{
+		SchemaVersion: SchemaVersion,
+		Status:        r.Status,
+		Files:         r.Files,
+		Symbols:       r.Symbols,
+		Warnings:      r.Warnings,
+		NextCursor:    r.NextCursor,
+		Outline:       []jsonFile{},
+	}
+
+	var files []string
+	fileSymbols := make(map[string][]Symbol)
+	for _, sym := range symbols {
+		if _, ok := fileSymbols[sym.FilePath]; !ok {
+			files = append(files, sym.FilePath)
+		}
+		fileSymbols[sym.FilePath] = append(fileSymbols[sym.FilePath], sym)
+	}
+
+	for _, file := range files {
+		doc.Outline = append(doc.Outline, jsonFile{
+			Path:         file,
+			FileMetadata: metadata[file],
+			Symbols:      newJSONSymbols(BuildHierarchy(fileSymbols[file]), scores),
+		})
+	}
+	return doc
+}
+
+// newJSONSymbols converts a symbol hierarchy to its JSON form
+func newJSONSymbols(nodes []*SymbolNode, scores map[string]int) []*jsonSymbol {
+	symbols := make([]*jsonSymbol, 0, len(nodes))
+	for _, node := range nodes {
+		symbols = append(symbols, &jsonSymbol{
+			Symbol:   node.Symbol,
+			Score:    scores[node.Symbol.ID],
+			Children: newJSONSymbols(node.Children, scores),
+		})
+	}
+	return symbols
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractSymbolsResult_JSON(t *testing.T) {
+	tempDir := t.TempDir()
+	source := "class Circle:\n    def area(self) -> float:\n        return 3.14\n\ndef new():\n    return Circle()\n"
+	file := filepath.Join(tempDir, "shapes.py")
+	if err := os.WriteFile(file, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExtractSymbolsResult(file, ExtractOptions{Detail: Standard, Format: FormatJSON, Depth: 1})
+	if err != nil {
+		t.Fatalf("ExtractSymbolsResult error = %v", err)
+	}
+	if result.Format != FormatJSON {
+		t.Errorf("result format = %q, want json", result.Format)
+	}
+
+	var doc jsonOutline
+	if err := json.Unmarshal([]byte(result.Output), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, result.Output)
+	}
+	if doc.SchemaVersion != SchemaVersion || doc.Status != StatusOK || doc.Files != 1 || doc.Symbols != result.Symbols {
+		t.Errorf("header = %d %s %d %d, want %d ok 1 %d", doc.SchemaVersion, doc.Status, doc.Files, doc.Symbols, SchemaVersion, result.Symbols)
+	}
+	if len(doc.Outline) != 1 {
+		t.Fatalf("outline has %d files, want 1", len(doc.Outline))
+	}
+
+	outline := doc.Outline[0]
+	if outline.Path != file || outline.FileMetadata == nil || outline.Language != "python" || outline.Package != "shapes" || outline.Hash == "" {
+		t.Errorf("file = %+v, want metadata of %s", outline, file)
+	}
+
+	// Depth is a display option, so nested symbols are still listed as children
+	symbols := make(map[string]*jsonSymbol)
+	for _, sym := range outline.Symbols {
+		symbols[sym.Kind+" "+sym.Name] = sym
+		if sym.ID == "" {
+			t.Errorf("%s has no ID", sym.Name)
+		}
+	}
+	circle, ok := symbols["class Circle"]
+	if !ok {
+		t.Fatalf("class Circle not listed at the top level: %+v", outline.Symbols)
+	}
+	if len(circle.Children) != 1 || circle.Children[0].Name != "area" || circle.Children[0].StartLine != 2 {
+		t.Errorf("Circle children = %+v, want area on line 2", circle.Children)
+	}
+	if _, ok := symbols["func new"]; !ok {
+		t.Errorf("func new not listed at the top level: %+v", outline.Symbols)
+	}
+}
+
+func TestExtractSymbolsResult_JSONEmpty(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "empty.py"), []byte("# nothing here\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pattern string
+		status  string
+	}{
+		{filepath.Join(tempDir, "*.go"), StatusNoFiles},
+		{filepath.Join(tempDir, "*.py"), StatusNoSymbols},
+	}
+	for _, tt := range tests {
+		result, err := ExtractSymbolsResult(tt.pattern, ExtractOptions{Format: FormatJSON, Name: "^missing$"})
+		if err != nil {
+			t.Fatalf("%s: ExtractSymbolsResult error = %v", tt.pattern, err)
+		}
+		var doc jsonOutline
+		if err := json.Unmarshal([]byte(result.Output), &doc); err != nil {
+			t.Fatalf("%s: output is not JSON: %v\n%s", tt.pattern, err, result.Output)
+		}
+		if doc.Status != tt.status || doc.Outline == nil || len(doc.Outline) != 0 {
+			t.Errorf("%s: status %s with outline %v, want %s with an empty outline", tt.pattern, doc.Status, doc.Outline, tt.status)
+		}
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		format, mode string
+		want         string
+		wantErr      bool
+	}{
+		{"", "symbols", FormatMarkdown, false},
+		{"markdown", "strings", FormatMarkdown, false},
+		{"json", "", FormatJSON, false},
+		{"json", "strings", "", true},
+		{"xml", "symbols", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseOutputFormat(tt.format, tt.mode)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseOutputFormat(%q, %q) = %q, %v; want %q, error %v", tt.format, tt.mode, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
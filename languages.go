@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// supportedLanguages lists every language glyph parses with the file extensions
+// GetLanguageQueriesForFile recognizes for it, and the interpreters whose shebang
+// lines mark an extensionless script as the language
+var supportedLanguages = []struct {
+	langQueries  *LanguageQueries
+	extensions   []string
+	interpreters []string
+}{
+	{goLanguageQueries, []string{".go"}, nil},
+	{javaLanguageQueries, []string{".java"}, nil},
+	{javascriptLanguageQueries, []string{".js", ".jsx"}, nil},
+	{typescriptLanguageQueries, []string{".ts", ".tsx"}, nil},
+	{pythonLanguageQueries, []string{".py"}, nil},
+	{rustLanguageQueries, []string{".rs"}, nil},
+	{csharpLanguageQueries, []string{".cs"}, nil},
+	{phpLanguageQueries, []string{".php"}, nil},
+	{elixirLanguageQueries, []string{".ex", ".exs"}, nil},
+	{bashLanguageQueries, []string{".sh", ".bash"}, []string{"bash", "sh"}},
+	{hclLanguageQueries, []string{".tf", ".hcl"}, nil},
+	{markdownLanguageQueries, []string{".md", ".mdx"}, nil},
+	{yamlLanguageQueries, []string{".yaml", ".yml"}, nil},
+	{cssLanguageQueries, []string{".css", ".scss", ".less"}, nil},
+	{htmlLanguageQueries, []string{".html", ".htm"}, nil},
+}
+
+// LanguageInfo describes a supported language for the languages subcommand
+type LanguageInfo struct {
+	Name       string   `json:"name"`
+	Extensions []string `json:"extensions"`
+	// Interpreters are the shebang interpreters of extensionless scripts in the language
+	Interpreters []string `json:"interpreters,omitempty"`
+	// Kinds are the kinds of the symbols extracted from the language
+	Kinds []string `json:"kinds"`
+	// Note describes how the language is outlined where its kinds don't say
+	Note string `json:"note,omitempty"`
+}
+
+// Languages returns the supported languages, followed by Jupyter notebooks
+func Languages() []LanguageInfo {
+	var languages []LanguageInfo
+	for _, lang := range supportedLanguages {
+		languages = append(languages, LanguageInfo{
+			Name:         lang.langQueries.Name,
+			Extensions:   lang.extensions,
+			Interpreters: lang.interpreters,
+			Kinds:        languageKinds(lang.langQueries),
+		})
+	}
+	return append(languages, LanguageInfo{
+		Name:       "notebook",
+		Extensions: []string{".ipynb"},
+		Kinds:      languageKinds(pythonLanguageQueries),
+		Note:       "cells are outlined in the kernel's language, Python by default, or the language of a cell magic such as %%bash",
+	})
+}
+
+// languageKinds returns the sorted kinds of the symbols the queries of a language
+// extract, together with the module symbols of Python files
+func languageKinds(langQueries *LanguageQueries) []string {
+	seen := make(map[string]bool)
+	for symbolType := range langQueries.Queries {
+		seen[mapSymbolKind(symbolType)] = true
+	}
+	if langQueries == pythonLanguageQueries {
+		seen["module"] = true
+	}
+	kinds := make([]string, 0, len(seen))
+	for kind := range seen {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// FormatLanguages formats the supported languages, one per line with its
+// extensions and symbol kinds
+func FormatLanguages(languages []LanguageInfo) string {
+	width := 0
+	for _, lang := range languages {
+		width = max(width, len(lang.Name))
+	}
+
+	var sb strings.Builder
+	for _, lang := range languages {
+		extensions := strings.Join(lang.Extensions, " ")
+		if len(lang.Interpreters) > 0 {
+			extensions += ", #!" + strings.Join(lang.Interpreters, " #!")
+		}
+		sb.WriteString(fmt.Sprintf("%-*s  %s\n", width, lang.Name, extensions))
+		sb.WriteString(fmt.Sprintf("%-*s  kinds: %s\n", width, "", strings.Join(lang.Kinds, ", ")))
+		if lang.Note != "" {
+			sb.WriteString(fmt.Sprintf("%-*s  %s\n", width, "", lang.Note))
+		}
+	}
+	return sb.String()
+}
+
+// FormatLanguagesJSON formats the supported languages as a JSON document
+func FormatLanguagesJSON(languages []LanguageInfo) (string, error) {
+	output, err := json.MarshalIndent(map[string]any{"languages": languages}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output) + "\n", nil
+}
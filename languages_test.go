@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLanguages(t *testing.T) {
+	dir := t.TempDir()
+	listed := make(map[*LanguageQueries]bool)
+	for _, lang := range supportedLanguages {
+		listed[lang.langQueries] = true
+		// The listed extensions and interpreters select the language's queries
+		for _, ext := range lang.extensions {
+			if got := GetLanguageQueriesForFile("/src/file" + ext); got != lang.langQueries {
+				t.Errorf("%s files are not parsed as %s", ext, lang.langQueries.Name)
+			}
+		}
+		for _, interpreter := range lang.interpreters {
+			script := filepath.Join(dir, interpreter+"-script")
+			if err := os.WriteFile(script, []byte("#!/usr/bin/env "+interpreter+"\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if got := GetLanguageQueriesForFile(script); got != lang.langQueries {
+				t.Errorf("#!%s scripts are not parsed as %s", interpreter, lang.langQueries.Name)
+			}
+		}
+	}
+	// Every language the doctor checks is listed
+	for _, sample := range doctorSamples {
+		if !listed[sample.langQueries] {
+			t.Errorf("%s is missing from supportedLanguages", sample.langQueries.Name)
+		}
+	}
+
+	languages := Languages()
+	for _, lang := range languages {
+		switch lang.Name {
+		case "go":
+			if !slices.Contains(lang.Kinds, "method") || !slices.Contains(lang.Kinds, "struct") {
+				t.Errorf("go kinds = %v, want method and struct", lang.Kinds)
+			}
+		case "python":
+			if !slices.Contains(lang.Kinds, "module") || !slices.IsSorted(lang.Kinds) {
+				t.Errorf("python kinds = %v, want sorted kinds with module", lang.Kinds)
+			}
+		}
+	}
+	if last := languages[len(languages)-1]; last.Name != "notebook" || last.Extensions[0] != ".ipynb" {
+		t.Errorf("last language = %+v, want notebooks", last)
+	}
+
+	if text := FormatLanguages(languages); !strings.Contains(text, ".sh .bash, #!bash #!sh") || !strings.Contains(text, "kinds: ") {
+		t.Errorf("unexpected text:\n%s", text)
+	}
+
+	output, err := FormatLanguagesJSON(languages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Languages []LanguageInfo `json:"languages"`
+	}
+	if err := json.Unmarshal([]byte(output), &doc); err != nil || len(doc.Languages) != len(languages) {
+		t.Errorf("invalid JSON (%v):\n%s", err, output)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// listedFile is a file matched by a list_files call
+type listedFile struct {
+	path string
+	// language is the name of the file's language, or "" if it is not supported
+	language string
+	size     int64
+}
+
+// languageTotals counts the matched files of one language
+type languageTotals struct {
+	language string
+	files    int
+	bytes    int64
+}
+
+// ListFilesContext lists the files matching a pattern with their language and size
+// without parsing them, so the cost of an extraction can be estimated and the
+// pattern refined first. Only the files of opts.Page are listed, while the totals
+// cover every matched file. Nothing is parsed, so opts.Limits is not enforced, but
+// the output notes whether an extraction would exceed it.
+func ListFilesContext(ctx context.Context, pattern string, opts ExtractOptions) (*ExtractionResult, error) {
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files: %w", err)
+	}
+	if len(files) == 0 {
+		return noFilesResult(pattern), nil
+	}
+
+	result := &ExtractionResult{Status: StatusOK, Files: len(files), log: opts.Log}
+	page, nextCursor, err := paginate(files, opts.Page)
+	if err != nil {
+		return nil, err
+	}
+	result.NextCursor = nextCursor
+	listed := make(map[string]bool, len(page))
+	for _, file := range page {
+		listed[file] = true
+	}
+
+	var entries []listedFile
+	totals := make(map[string]*languageTotals)
+	var supported int
+	var supportedBytes int64
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			result.warnSkipped(file, err)
+			continue
+		}
+		entry := listedFile{path: file, language: fileLanguage(file), size: info.Size()}
+		if listed[file] {
+			entries = append(entries, entry)
+		}
+		if entry.language == "" {
+			continue
+		}
+		supported++
+		supportedBytes += entry.size
+		if totals[entry.language] == nil {
+			totals[entry.language] = &languageTotals{language: entry.language}
+		}
+		totals[entry.language].files++
+		totals[entry.language].bytes += entry.size
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Files matching %s\n\n", pattern))
+	sb.WriteString(fmt.Sprintf("%d files: %d supported with %d bytes of source to parse, %d unsupported\n", len(files), supported, supportedBytes, len(files)-supported))
+	if err := opts.Limits.check(len(files), supportedBytes); err != nil {
+		sb.WriteString(fmt.Sprintf("\nExtracting them would fail: %v\n", err))
+	}
+
+	if len(totals) > 0 {
+		languages := make([]*languageTotals, 0, len(totals))
+		for _, t := range totals {
+			languages = append(languages, t)
+		}
+		sort.Slice(languages, func(i, j int) bool {
+			if languages[i].bytes != languages[j].bytes {
+				return languages[i].bytes > languages[j].bytes
+			}
+			return languages[i].language < languages[j].language
+		})
+		sb.WriteString("\n## Languages\n\n")
+		for _, t := range languages {
+			sb.WriteString(fmt.Sprintf("- %s: %d files, %d bytes\n", t.language, t.files, t.bytes))
+		}
+	}
+
+	sb.WriteString("\n## Files\n\n")
+	for _, entry := range entries {
+		language := entry.language
+		if language == "" {
+			language = "unsupported"
+		}
+		sb.WriteString(fmt.Sprintf("- %s (%s, %d bytes)\n", entry.path, language, entry.size))
+	}
+	result.Output = sb.String()
+	return result, nil
+}
+
+// fileLanguage returns the name of the language a file would be parsed as, or ""
+// if glyph does not support it
+func fileLanguage(filePath string) string {
+	if IsNotebookFile(filePath) {
+		return "notebook"
+	}
+	if langQueries := GetLanguageQueriesForFile(filePath); langQueries != nil {
+		return langQueries.Name
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// writeListFiles writes two Go files, a Python file, a Markdown file and a file of
+// no supported language
+func writeListFiles(t *testing.T) string {
+	t.Helper()
+	testDir := t.TempDir()
+	testFiles := map[string]string{
+		"main.go":   "package main\n\nfunc main() {}\n",
+		"util.go":   "package main\n",
+		"tool.py":   "def run():\n    pass\n",
+		"README.md": "# Tool\n",
+		"logo.bin":  "\x00\x01",
+	}
+	for name, content := range testFiles {
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return testDir
+}
+
+func TestListFilesContext(t *testing.T) {
+	testDir := writeListFiles(t)
+
+	result, err := ListFilesContext(context.Background(), filepath.Join(testDir, "*"), ExtractOptions{})
+	if err != nil {
+		t.Fatalf("ListFilesContext error = %v", err)
+	}
+	if result.Status != StatusOK || result.Files != 5 || result.Symbols != 0 {
+		t.Errorf("result = %+v, want 5 files and no symbols", result)
+	}
+
+	for _, want := range []string{
+		"5 files: 4 supported with 69 bytes of source to parse, 1 unsupported\n",
+		"- go: 2 files, 42 bytes\n",
+		"- " + filepath.Join(testDir, "main.go") + " (go, 29 bytes)\n",
+		"- " + filepath.Join(testDir, "logo.bin") + " (unsupported, 2 bytes)\n",
+	} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("Expected %q in:\n%s", want, result.Output)
+		}
+	}
+	if strings.Contains(result.Output, "would fail") {
+		t.Errorf("Expected no limit note without limits, got:\n%s", result.Output)
+	}
+}
+
+func TestListFilesContext_PageAndLimits(t *testing.T) {
+	testDir := writeListFiles(t)
+
+	result, err := ListFilesContext(context.Background(), filepath.Join(testDir, "*"), ExtractOptions{
+		Limits: ExtractLimits{MaxFiles: 3},
+		Page:   Page{Size: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NextCursor == "" || strings.Count(result.Output, " bytes)\n") != 2 {
+		t.Errorf("Expected a page of 2 files with a cursor, got %q:\n%s", result.NextCursor, result.Output)
+	}
+	// The totals and the limit note cover every matched file, not just the page
+	if !strings.Contains(result.Output, "5 files: ") || !strings.Contains(result.Output, "Extracting them would fail: limit exceeded: max_files is 3") {
+		t.Errorf("Expected totals of all files and a limit note, got:\n%s", result.Output)
+	}
+}
+
+func TestToolHandlers_ListFiles(t *testing.T) {
+	testDir := writeListFiles(t)
+
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(testDir, "*.go")}
+
+	result, err := handlers.listFiles(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || result.Meta["files"] != 2 || !strings.Contains(text, "# Files matching "+filepath.Join(testDir, "*.go")) {
+		t.Errorf("expected a list of the Go files, got %v: %q", result.Meta, text)
+	}
+
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(testDir, "*.rs")}
+	result, err = handlers.listFiles(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("expected an error when no files match")
+	}
+}
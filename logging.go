@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Levels of log events, named as the MCP logging levels they are sent at
+const (
+	LogDebug   = "debug"
+	LogWarning = "warning"
+)
+
+// Kinds of log events
+const (
+	LogFileSkipped  = "file_skipped"
+	LogFileUnstable = "file_unstable"
+	LogParseErrors  = "parse_errors"
+	LogCacheHit     = "cache_hit"
+)
+
+// logger is the name MCP log messages are sent under
+const logger = "glyph"
+
+// LogEvent is something notable that happened while extracting a file, reported
+// to ExtractOptions.Log
+type LogEvent struct {
+	Level string `json:"-"`
+	// Event is LogFileSkipped, LogFileUnstable, LogParseErrors or LogCacheHit
+	Event   string `json:"event"`
+	File    string `json:"file"`
+	Message string `json:"message"`
+}
+
+// toolLog returns an ExtractOptions.Log callback that sends the events of a tool
+// call to its client as notifications/message. mcp-go drops the events below the
+// level the client set with logging/setLevel, which is error until it sets one.
+func toolLog(ctx context.Context) func(LogEvent) {
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil
+	}
+	return func(event LogEvent) {
+		// Logging is best effort: a message the client can't take is dropped
+		_ = mcpServer.SendLogMessageToClient(ctx, mcp.NewLoggingMessageNotification(mcp.LoggingLevel(event.Level), logger, event))
+	}
+}
+
+// logParseErrors reports the lines of a file with syntax errors, where symbols may be missing
+func logParseErrors(log func(LogEvent), file string, parseErrors []LineRange) {
+	if log == nil || len(parseErrors) == 0 {
+		return
+	}
+	lines := make([]string, len(parseErrors))
+	for i, r := range parseErrors {
+		lines[i] = r.String()
+	}
+	log(LogEvent{
+		Level:   LogWarning,
+		Event:   LogParseErrors,
+		File:    file,
+		Message: "syntax errors on lines " + strings.Join(lines, ", ") + "; symbols there may be missing",
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestExtractSymbolsContext_Log(t *testing.T) {
+	testDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(testDir, "ok.go"), []byte("package app\n\nfunc Run() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(testDir, "broken.go"), []byte("package app\n\nfunc Broken( {\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A dangling symlink is matched but can't be read
+	if err := os.Symlink(filepath.Join(testDir, "missing.go"), filepath.Join(testDir, "gone.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []LogEvent
+	opts := ExtractOptions{
+		Detail: Standard,
+		Cache:  NewSymbolCache(10),
+		Log:    func(event LogEvent) { events = append(events, event) },
+	}
+	if _, err := ExtractSymbolsContext(context.Background(), filepath.Join(testDir, "*.go"), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	for _, event := range events {
+		got[event.Event] = filepath.Base(event.File) + " " + event.Level
+	}
+	want := map[string]string{
+		LogParseErrors: "broken.go warning",
+		LogFileSkipped: "gone.go warning",
+	}
+	for event, file := range want {
+		if got[event] != file {
+			t.Errorf("%s event = %q, want %q (all events: %+v)", event, got[event], file, events)
+		}
+	}
+	if _, ok := got[LogCacheHit]; ok {
+		t.Errorf("Expected no cache hits on the first extraction, got %+v", events)
+	}
+
+	// The second extraction takes both readable files from the cache, and still
+	// reports where the broken one has syntax errors
+	events = nil
+	if _, err := ExtractSymbolsContext(context.Background(), filepath.Join(testDir, "*.go"), opts); err != nil {
+		t.Fatal(err)
+	}
+	var hits, parseErrors int
+	for _, event := range events {
+		switch event.Event {
+		case LogCacheHit:
+			hits++
+			if event.Level != LogDebug {
+				t.Errorf("cache hit level = %q, want %q", event.Level, LogDebug)
+			}
+		case LogParseErrors:
+			parseErrors++
+		}
+	}
+	if hits != 2 || parseErrors != 1 {
+		t.Errorf("Expected 2 cache hits and 1 parse error event, got %+v", events)
+	}
+}
+
+// loggingSession is an MCP session at a fixed log level that buffers its notifications
+type loggingSession struct {
+	notifications chan mcp.JSONRPCNotification
+	level         mcp.LoggingLevel
+}
+
+func (s *loggingSession) SessionID() string                                   { return "s1" }
+func (s *loggingSession) Initialize()                                         {}
+func (s *loggingSession) Initialized() bool                                   { return true }
+func (s *loggingSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *loggingSession) SetLogLevel(level mcp.LoggingLevel)                  { s.level = level }
+func (s *loggingSession) GetLogLevel() mcp.LoggingLevel                       { return s.level }
+
+func TestToolLog(t *testing.T) {
+	if toolLog(context.Background()) != nil {
+		t.Error("Expected no log callback outside of an MCP request")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.py"), []byte("def run(:\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mcpServer := server.NewMCPServer("glyph", "1.0.0", server.WithLogging())
+	handlers := &toolHandlers{cache: NewSymbolCache(10)}
+	mcpServer.AddTool(mcp.NewTool("extract_symbols", withPatterns("pattern")), handlers.extractSymbols)
+
+	session := &loggingSession{notifications: make(chan mcp.JSONRPCNotification, 10), level: mcp.LoggingLevelWarning}
+	if err := mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+	ctx := mcpServer.WithContext(context.Background(), session)
+
+	// The second call hits the cache, which is logged at debug level and so not sent
+	for id := 1; id <= 2; id++ {
+		message, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0", "id": id, "method": "tools/call",
+			"params": map[string]any{"name": "extract_symbols", "arguments": map[string]any{"pattern": filepath.Join(dir, "*.py")}},
+		})
+		if _, ok := mcpServer.HandleMessage(ctx, message).(mcp.JSONRPCResponse); !ok {
+			t.Fatal("expected a response to the tool call")
+		}
+	}
+
+	var messages []string
+	for len(session.notifications) > 0 {
+		notification := <-session.notifications
+		if notification.Method != "notifications/message" {
+			continue
+		}
+		data, _ := json.Marshal(notification.Params.AdditionalFields)
+		messages = append(messages, string(data))
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected a parse error message per call, got %v", messages)
+	}
+	for _, message := range messages {
+		if !strings.Contains(message, `"level":"warning"`) || !strings.Contains(message, `"logger":"glyph"`) || !strings.Contains(message, `"event":"parse_errors"`) {
+			t.Errorf("unexpected log message %s", message)
+		}
+	}
+}
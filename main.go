@@ -2,10 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -23,6 +32,30 @@ func main() {
 		runMCPServer(os.Args[2:])
 	case "cli":
 		runCLI(os.Args[2:])
+	case "impact":
+		runImpact(os.Args[2:])
+	case "index":
+		runIndex(os.Args[2:])
+	case "query":
+		runQuery(os.Args[2:])
+	case "churn":
+		runChurn(os.Args[2:])
+	case "watch":
+		runWatch(os.Args[2:])
+	case "search":
+		runSearch(os.Args[2:])
+	case "count":
+		runCount(os.Args[2:])
+	case "diff":
+		runDiff(os.Args[2:])
+	case "serve":
+		runServe(os.Args[2:])
+	case "languages":
+		runLanguages(os.Args[2:])
+	case "doctor":
+		runDoctor(os.Args[2:])
+	case "schema":
+		fmt.Print(outputSchema)
 	default:
 		printUsage()
 		os.Exit(1)
@@ -30,30 +63,222 @@ func main() {
 }
 
 func printUsage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s [mcp|cli] [options]\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "  mcp  - Run as MCP server (default)\n")
-	fmt.Fprintf(os.Stderr, "  cli  - Run in CLI mode\n")
+	fmt.Fprintf(os.Stderr, "Usage: %s [mcp|cli|impact|index|query|churn|watch|search|count|diff|serve|languages|doctor|schema] [options]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  mcp       - Run as MCP server (default)\n")
+	fmt.Fprintf(os.Stderr, "  cli       - Run in CLI mode\n")
+	fmt.Fprintf(os.Stderr, "  impact    - List references to a symbol to estimate a rename or refactor\n")
+	fmt.Fprintf(os.Stderr, "  index     - Build the local symbol index, or export or import index snapshots\n")
+	fmt.Fprintf(os.Stderr, "  query     - Look symbols up by name in the local index without parsing\n")
+	fmt.Fprintf(os.Stderr, "  churn     - Report the most frequently changed symbols from git history\n")
+	fmt.Fprintf(os.Stderr, "  watch     - Print the outline again whenever the matched files change\n")
+	fmt.Fprintf(os.Stderr, "  search    - List the symbols whose name matches a query as editor locations\n")
+	fmt.Fprintf(os.Stderr, "  count     - Count the matched files and their symbols by language and kind\n")
+	fmt.Fprintf(os.Stderr, "  diff      - Report the symbols added, removed or changed between two outlines\n")
+	fmt.Fprintf(os.Stderr, "  serve     - Serve symbols as JSON over an HTTP REST API\n")
+	fmt.Fprintf(os.Stderr, "  languages - List the supported languages, their extensions and symbol kinds\n")
+	fmt.Fprintf(os.Stderr, "  doctor    - Check grammars, queries, the cache and optionally the MCP server\n")
+	fmt.Fprintf(os.Stderr, "  schema    - Print the JSON Schema of the machine-readable output\n")
 }
 
-func validateAbsolutePath(pattern string) error {
-	if !filepath.IsAbs(pattern) {
-		return fmt.Errorf("pattern must be an absolute path, got: %s", pattern)
+// contentLanguages lists the language names accepted for inline content
+const contentLanguages = "'go', 'java', 'javascript', 'typescript', 'python', 'rust', 'csharp', 'php', 'elixir', 'bash', 'hcl', 'markdown', 'yaml', 'css' or 'html'"
+
+// defaultMaxSignatureLength is the signature length cap used unless a client asks otherwise
+const defaultMaxSignatureLength = 300
+
+// resolvePattern expands ~ and environment variables in a pattern, or in each
+// pattern of a list, and checks that it is absolute
+func resolvePattern(pattern string) (string, error) {
+	patterns := SplitPatterns(pattern)
+	for i, p := range patterns {
+		expanded, err := ExpandPath(p)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(expanded) {
+			return "", fmt.Errorf("pattern must be an absolute path, got: %s", p)
+		}
+		patterns[i] = expanded
+	}
+	return strings.Join(patterns, ","), nil
+}
+
+// parsePatternArgs splits the positional arguments of the cli command into the
+// patterns whose files are extracted, merged into one pattern list so each file is
+// extracted once, and the negations starting with '!' that subtract matches,
+// gitignore-style. The pattern is empty if there are only negations.
+func parsePatternArgs(args []string) (string, []string, error) {
+	var patterns, negations []string
+	for _, arg := range args {
+		if negation, ok := strings.CutPrefix(arg, "!"); ok {
+			expanded, err := ExpandPath(negation)
+			if err != nil {
+				return "", nil, err
+			}
+			negations = append(negations, "!"+expanded)
+			continue
+		}
+		pattern, err := resolvePattern(arg)
+		if err != nil {
+			return "", nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return strings.Join(patterns, ","), negations, nil
+}
+
+// readListedFiles reads the files of the cli command's -files flag from a file, or
+// from stdin for "-", and returns a pattern naming them along with the files
+func readListedFiles(from string) (string, []string, error) {
+	r := io.Reader(os.Stdin)
+	if from != "-" {
+		f, err := os.Open(from)
+		if err != nil {
+			return "", nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	files, err := ReadFileList(r, dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read the file list: %w", err)
+	}
+	if len(files) == 0 {
+		if from == "-" {
+			from = "stdin"
+		}
+		return "", nil, fmt.Errorf("no files listed in %s", from)
+	}
+	return FileListPattern(files), files, nil
+}
+
+// expandExcludes turns the globs of -exclude flags, which may omit the leading '!',
+// into expanded negation patterns
+func expandExcludes(excludes []string) ([]string, error) {
+	negations := make([]string, 0, len(excludes))
+	for _, exclude := range excludes {
+		expanded, err := ExpandPath(strings.TrimPrefix(exclude, "!"))
+		if err != nil {
+			return nil, err
+		}
+		negations = append(negations, "!"+expanded)
 	}
+	return negations, nil
+}
+
+// repeatedFlag collects the values of a flag that can be given more than once,
+// such as -root or -exclude
+type repeatedFlag []string
+
+func (r *repeatedFlag) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *repeatedFlag) Set(value string) error {
+	*r = append(*r, value)
 	return nil
 }
 
+// discoveryFlags are the file discovery flags, and -jobs, shared by the subcommands
+// that extract symbols
+type discoveryFlags struct {
+	includeNestedModules *bool
+	noTests              *bool
+	hidden               *bool
+	includeGenerated     *bool
+	excludes             repeatedFlag
+	jobs                 *int
+}
+
+// addDiscoveryFlags registers the shared discovery flags on a flag set
+func addDiscoveryFlags(flags *flag.FlagSet) *discoveryFlags {
+	d := &discoveryFlags{
+		includeNestedModules: flags.Bool("include-nested-modules", false, "Include nested Go modules and the vendor directories of Go modules"),
+		noTests:              flags.Bool("no-tests", false, "Skip test files such as _test.go, *.spec.ts, test_*.py and *Test.java"),
+		hidden:               flags.Bool("hidden", false, "Include dotfiles and dot-directories such as .venv and .cache"),
+		includeGenerated:     flags.Bool("include-generated", false, "Include generated files such as *.pb.go, *_gen.go, *.min.js and files with a \"Code generated ... DO NOT EDIT.\" header"),
+		jobs:                 flags.Int("jobs", runtime.NumCPU(), "Number of files to parse at once"),
+	}
+	flags.Var(&d.excludes, "exclude", "Exclude matched files matching this glob, e.g. '**/node_modules/**' or '**/*_test.go'; relative globs are resolved against the pattern's base directory (repeatable)")
+	return d
+}
+
+// options checks the parsed flags and returns the discovery options and number of
+// jobs they select. The negations of the pattern arguments exclude files along
+// with -exclude.
+func (d *discoveryFlags) options(negations []string) (DiscoveryOptions, int, error) {
+	if *d.jobs < 1 {
+		return DiscoveryOptions{}, 0, fmt.Errorf("-jobs must be at least 1, got: %d", *d.jobs)
+	}
+	excluded, err := expandExcludes(d.excludes)
+	if err != nil {
+		return DiscoveryOptions{}, 0, err
+	}
+	return DiscoveryOptions{
+		IncludeNestedModules: *d.includeNestedModules,
+		ExcludeTests:         *d.noTests,
+		IncludeGenerated:     *d.includeGenerated,
+		Hidden:               *d.hidden,
+		Exclude:              append(negations, excluded...),
+	}, *d.jobs, nil
+}
+
 func runCLI(args []string) {
 	// Set up CLI flags
 	cliFlags := flag.NewFlagSet("cli", flag.ExitOnError)
-	detail := cliFlags.String("detail", "standard", "Level of detail: minimal or standard")
+	detail := cliFlags.String("detail", "standard", "Level of detail: minimal, standard or full")
+	mode := cliFlags.String("mode", "symbols", "Extraction mode: symbols or strings (SQL, URLs, regexes and templates)")
+	coverage := cliFlags.String("coverage", "", "Go coverprofile or lcov file to annotate symbols with line coverage")
+	sourceMaps := cliFlags.Bool("source-maps", false, "Report original source locations for generated .js files with source maps")
+	discovery := addDiscoveryFlags(cliFlags)
+	pathRegex := cliFlags.String("path-regex", "", "Only include matched files whose path matches this regular expression")
+	pathExcludeRegex := cliFlags.String("path-exclude-regex", "", "Exclude matched files whose path matches this regular expression")
+	modifiedWithin := cliFlags.String("modified-within", "", "Only include files modified within this duration, e.g. 7d, 2w or 36h")
+	modifiedAfter := cliFlags.String("modified-after", "", "Only include files modified after this date (YYYY-MM-DD or RFC 3339)")
+	modifiedBefore := cliFlags.String("modified-before", "", "Only include files modified before this date (YYYY-MM-DD or RFC 3339)")
+	groupBy := cliFlags.String("group-by", "file", "Group output by: file or package (Go import path)")
+	visibility := cliFlags.String("visibility", "all", "Symbols to include: all or public (exported)")
+	startLine := cliFlags.Int("start-line", 0, "Only include symbols ending on or after this line (also accepted as a path:start-end suffix)")
+	endLine := cliFlags.Int("end-line", 0, "Only include symbols starting on or before this line")
+	name := cliFlags.String("name", "", "Only include symbols whose name matches this regular expression (see -name-match)")
+	nameMatch := cliFlags.String("name-match", "regex", "How -name is matched: regex, exact, glob such as 'New*', or fuzzy (subsequence, ranked best first)")
+	query := cliFlags.String("query", "", "Custom tree-sitter query with an @name capture (and optional @symbol capture) whose matches are reported as symbols")
+	annotatedWith := cliFlags.String("annotated-with", "", "Only include symbols carrying this annotation or decorator, e.g. @RestController")
+	deprecated := cliFlags.String("deprecated", "include", "Deprecated symbols: include, exclude or only")
+	receiver := cliFlags.String("receiver", "", "Only include Go methods defined on this type, e.g. Server")
+	minLines := cliFlags.Int("min-lines", 0, "Omit symbols spanning fewer lines, such as one-line getters and constants")
+	debugTimings := cliFlags.Bool("debug-timings", false, "Report parse time, query time and symbol count per file on stderr, slowest first")
+	maxOutputBytes := cliFlags.Int("max-output-bytes", 0, "Truncate the output at a symbol boundary once it exceeds this many bytes (0 means no limit)")
+	summarize := cliFlags.Int("summarize", 0, "Collapse the largest files to top-level symbols until the outline fits in this many bytes (0 disables)")
+	maxSignatureLength := cliFlags.Int("max-signature-length", defaultMaxSignatureLength, "Truncate signatures longer than this many characters at a token boundary (0 means no limit)")
+	maxSymbolsPerFile := cliFlags.Int("max-symbols-per-file", 0, "Show at most this many symbols per file, followed by a count of the rest (0 shows all)")
+	depth := cliFlags.Int("depth", 0, "Levels of nested symbols to show, e.g. 1 for top-level declarations only (0 shows all)")
+	lang := cliFlags.String("lang", "", "Language of the source read from stdin when the pattern is -: "+contentLanguages)
+	filesFrom := cliFlags.String("files", "", "Extract the files listed one per line in this file, or - for stdin, instead of matching a pattern, e.g. git diff --name-only | glyph cli -files -")
+	output := cliFlags.String("output", "", "Write the output to this file, replaced atomically, instead of stdout")
+	cliFlags.StringVar(output, "o", "", "Shorthand for -output")
+	appendOutput := cliFlags.Bool("append", false, "With -output, append to the file instead of replacing it")
+	format := cliFlags.String("format", "markdown", "Output format: markdown, json for the symbols of every file with their children, metadata and IDs, ndjson for one JSON object per symbol and line, ctags for a tags file, sarif for a SARIF 2.1.0 log, csv or tsv for a file,kind,name,start,end,signature table, dot for a Graphviz graph of what contains what, tree for a box-drawn tree, compact for one dense line per symbol, html for a standalone HTML report, or quickfix for path:line:col locations an editor can jump between")
 
 	cliFlags.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s cli [options] <pattern>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s cli [options] <pattern>... [!negation...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s cli [options] -lang=<language> -\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
 		cliFlags.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  %s cli '/path/to/project/*.go'                    # Extract symbols from all .go files\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s cli -detail=minimal '/path/to/project/**/*.js' # Extract minimal symbols from all .js files\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s cli -mode=strings '/path/to/project/**/*.py'   # Extract notable string literals from all .py files\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s cli -coverage=cover.out '/path/to/project/**/*.go' # Annotate symbols with test coverage\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s cli '/path/to/project/**/*.ts' '!**/*.d.ts'        # Exclude matches with negation patterns\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s cli '/path/to/project/**/*.go' '/path/to/project/**/*.ts' # Extract several languages at once\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s cli -lang=go - < main.go                          # Outline source read from stdin\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  git diff --name-only | %s cli -files -               # Extract the files of a diff\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s cli -exclude '**/node_modules/**' '/path/to/project/**/*.js' # Skip dependency directories\n", os.Args[0])
 	}
 
 	if err := cliFlags.Parse(args); err != nil {
@@ -61,79 +286,1818 @@ func runCLI(args []string) {
 	}
 
 	// Check for pattern argument
-	if cliFlags.NArg() < 1 {
+	if cliFlags.NArg() < 1 && *filesFrom == "" {
 		cliFlags.Usage()
 		os.Exit(1)
 	}
 
-	pattern := cliFlags.Arg(0)
-	if err := validateAbsolutePath(pattern); err != nil {
+	// A pattern of - outlines source read from stdin, such as an unsaved buffer
+	fromStdin := cliFlags.NArg() == 1 && cliFlags.Arg(0) == "-"
+	switch {
+	case fromStdin && *lang == "":
+		fmt.Fprintf(os.Stderr, "Error: -lang is required to read source from stdin\n")
+		os.Exit(1)
+	case fromStdin && *filesFrom != "":
+		fmt.Fprintf(os.Stderr, "Error: -files cannot be combined with source read from stdin\n")
+		os.Exit(1)
+	case fromStdin && *mode != "symbols":
+		fmt.Fprintf(os.Stderr, "Error: source read from stdin is only supported in symbols mode\n")
+		os.Exit(1)
+	case !fromStdin && *lang != "":
+		fmt.Fprintf(os.Stderr, "Error: -lang only applies to source read from stdin with the pattern -\n")
+		os.Exit(1)
+	}
+
+	var err error
+	var pattern string
+	var negations, listedFiles []string
+	if !fromStdin {
+		pattern, negations, err = parsePatternArgs(cliFlags.Args())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	switch {
+	case fromStdin:
+	case *filesFrom != "" && pattern != "":
+		fmt.Fprintf(os.Stderr, "Error: patterns cannot be combined with -files, only negations\n")
+		os.Exit(1)
+	case *filesFrom != "":
+		pattern, listedFiles, err = readListedFiles(*filesFrom)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case pattern == "":
+		fmt.Fprintf(os.Stderr, "Error: at least one pattern is required besides negations\n")
+		os.Exit(1)
+	}
+
+	if *appendOutput && *output == "" {
+		fmt.Fprintf(os.Stderr, "Error: -append requires -output\n")
+		os.Exit(1)
+	}
+	outputPath, err := ExpandPath(*output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	groupByPackage, err := parseGroupBy(*groupBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	publicOnly, err := parseVisibility(*visibility)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	outputFormat, err := parseOutputFormat(*format, *mode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if isStructuredFormat(outputFormat) && *maxOutputBytes > 0 {
+		fmt.Fprintf(os.Stderr, "Error: -max-output-bytes cannot be combined with -format=%s\n", outputFormat)
+		os.Exit(1)
+	}
+
+	includeRegex, err := compilePathRegex("path-regex", *pathRegex)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	excludeRegex, err := compilePathRegex("path-exclude-regex", *pathExcludeRegex)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	after, before, err := parseModifiedRange(*modifiedWithin, *modifiedAfter, *modifiedBefore)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	discoveryOpts, jobs, err := discovery.options(negations)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	discoveryOpts.PathRegex, discoveryOpts.PathExcludeRegex = includeRegex, excludeRegex
+	discoveryOpts.ModifiedAfter, discoveryOpts.ModifiedBefore = after, before
+	discoveryOpts.Files = listedFiles
+
+	opts := ExtractOptions{
+		Detail:             ParseDetailLevel(*detail),
+		Coverage:           *coverage,
+		SourceMaps:         *sourceMaps,
+		Discovery:          discoveryOpts,
+		GroupByPackage:     groupByPackage,
+		PublicOnly:         publicOnly,
+		Depth:              *depth,
+		MaxSymbolsPerFile:  *maxSymbolsPerFile,
+		MaxSignatureLength: *maxSignatureLength,
+		Summarize:          *summarize,
+		MinLines:           *minLines,
+		AnnotatedWith:      *annotatedWith,
+		Deprecated:         *deprecated,
+		Query:              *query,
+		DebugTimings:       *debugTimings,
+		Jobs:               jobs,
+		Name:               *name,
+		NameMatch:          *nameMatch,
+		Receiver:           *receiver,
+		Lines:              LineRange{Start: uint32(max(*startLine, 0)), End: uint32(max(*endLine, 0))},
+		Format:             outputFormat,
+	}
 
 	// Extract symbols
-	result, err := ExtractSymbols(pattern, *detail)
+	var result *ExtractionResult
+	if fromStdin {
+		var content []byte
+		content, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read stdin: %v\n", err)
+			os.Exit(1)
+		}
+		result, err = ExtractContentContext(context.Background(), "", content, *lang, opts)
+	} else {
+		result, err = extract(context.Background(), pattern, *mode, opts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if result.Format != FormatJSON && result.Format != FormatSARIF {
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	}
+
+	if *debugTimings && len(result.Timings) > 0 {
+		fmt.Fprint(os.Stderr, FormatTimings(result.Timings))
+	}
+
+	if outputPath == "" {
+		fmt.Print(TruncateOutput(result.Output, *maxOutputBytes))
+		return
+	}
+	if err := writeOutputFile(outputPath, TruncateOutput(result.Output, *maxOutputBytes), *appendOutput); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runImpact(args []string) {
+	impactFlags := flag.NewFlagSet("impact", flag.ExitOnError)
+	discovery := addDiscoveryFlags(impactFlags)
+
+	impactFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s impact [options] <symbol> <pattern>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		impactFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s impact NewServer '/path/to/project/**/*.go' # List references to NewServer\n", os.Args[0])
+	}
+
+	if err := impactFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if impactFlags.NArg() < 2 {
+		impactFlags.Usage()
+		os.Exit(1)
+	}
+
+	name := impactFlags.Arg(0)
+	pattern, err := resolvePattern(impactFlags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	discoveryOpts, jobs, err := discovery.options(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := AnalyzeImpact(name, pattern, ExtractOptions{Discovery: discoveryOpts, Jobs: jobs})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Print results to stdout
 	fmt.Print(result)
 }
 
-func runMCPServer(args []string) {
-	// Set up MCP flags
-	mcpFlags := flag.NewFlagSet("mcp", flag.ExitOnError)
+func runChurn(args []string) {
+	churnFlags := flag.NewFlagSet("churn", flag.ExitOnError)
+	top := churnFlags.Int("top", 20, "Number of hotspots to show (0 for all)")
+	since := churnFlags.String("since", "", "Only count commits more recent than a date, e.g. '2024-01-01' or '3 months ago'")
+	discovery := addDiscoveryFlags(churnFlags)
 
-	if err := mcpFlags.Parse(args); err != nil {
+	churnFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s churn [options] <pattern>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		churnFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s churn -top=10 '/path/to/project/**/*.go' # Show the 10 most changed symbols\n", os.Args[0])
+	}
+
+	if err := churnFlags.Parse(args); err != nil {
 		os.Exit(1)
 	}
 
-	// Create MCP server
-	mcpServer := server.NewMCPServer(
-		"glyph",
-		"1.0.0",
-		server.WithToolCapabilities(false),
-	)
+	if churnFlags.NArg() < 1 {
+		churnFlags.Usage()
+		os.Exit(1)
+	}
 
-	// Register tools
-	extractSymbolsTool := mcp.NewTool(
-		"extract_symbols",
-		mcp.WithDescription("Extract symbol outlines from source code files using tree-sitter parsing"),
-		mcp.WithString("pattern", mcp.Required(), mcp.Description("Absolute path glob pattern to match files (e.g., '/path/to/project/**/*.go', '/home/user/src/**/*.js')")),
-		mcp.WithString("detail", mcp.Description("Level of detail: 'minimal', 'standard' (default: 'standard')")),
-	)
+	pattern, err := resolvePattern(churnFlags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	mcpServer.AddTool(extractSymbolsTool, extractSymbolsHandler)
+	discoveryOpts, jobs, err := discovery.options(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Start server
-	if err := server.ServeStdio(mcpServer); err != nil {
-		fmt.Printf("Server error: %v\n", err)
+	result, err := AnalyzeChurn(pattern, *top, *since, ExtractOptions{Discovery: discoveryOpts, Jobs: jobs})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
+
+	fmt.Print(result)
 }
 
-func extractSymbolsHandler(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	pattern, err := request.RequireString("pattern")
+func runWatch(args []string) {
+	watchFlags := flag.NewFlagSet("watch", flag.ExitOnError)
+	detail := watchFlags.String("detail", "standard", "Level of detail: minimal, standard or full")
+	format := watchFlags.String("format", "markdown", "Output format, as for the cli command: markdown, json, ndjson, ctags, sarif, csv, tsv, dot, tree, compact, html or quickfix")
+	discovery := addDiscoveryFlags(watchFlags)
+	clearScreen := watchFlags.Bool("clear", false, "Clear the terminal before each outline, for a live view in a tmux pane")
+
+	watchFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s watch [options] <pattern>... [!negation...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		watchFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s watch -clear -format=tree '/path/to/project/**/*.go' # Keep a live outline in a pane\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s watch -format=ndjson '/path/to/project/**/*.ts' | my-indexer # Feed every change to another tool\n", os.Args[0])
+	}
+
+	if err := watchFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	pattern, negations, err := parsePatternArgs(watchFlags.Args())
 	if err != nil {
-		return mcp.NewToolResultError("pattern argument is required"), nil
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if pattern == "" {
+		watchFlags.Usage()
+		os.Exit(1)
 	}
 
-	detail := "standard"
-	if d := request.GetString("detail", ""); d != "" {
-		detail = d
+	discoveryOpts, jobs, err := discovery.options(negations)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	if err := validateAbsolutePath(pattern); err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	outputFormat, err := parseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	opts := ExtractOptions{
+		Detail:             ParseDetailLevel(*detail),
+		Discovery:          discoveryOpts,
+		MaxSignatureLength: defaultMaxSignatureLength,
+		Format:             outputFormat,
+		Jobs:               jobs,
+	}
+	emit := func(output string) {
+		if *clearScreen {
+			fmt.Print("\033[H\033[2J")
+		}
+		fmt.Print(output)
+	}
+	warn := func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := watchPattern(ctx, pattern, opts, watchDebounce, emit, warn); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runSearch(args []string) {
+	searchFlags := flag.NewFlagSet("search", flag.ExitOnError)
+	match := searchFlags.String("match", "fuzzy", "How the query is matched: fuzzy (subsequence, ranked best first), exact, glob such as 'New*', or regex")
+	format := searchFlags.String("format", "quickfix", "Output format, as for the cli command: quickfix for path:line:col: kind name: signature lines, compact, ndjson, json and so on")
+	visibility := searchFlags.String("visibility", "all", "Symbols to include: all or public (exported)")
+	discovery := addDiscoveryFlags(searchFlags)
+
+	searchFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s search [options] <pattern>... [!negation...] <query>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nPrints the symbols whose name matches the query, one location per line, and exits with status 1 if there are none.\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		searchFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s search '/path/to/project/**/*.go' usrSvc            # Find UserService and the like\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s search -match=glob '/path/to/project/**/*.ts' '*Handler' # Find every handler\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  vim -q <(%s search '/path/to/project/**/*.py' parse)   # Step through the matches in Vim\n", os.Args[0])
+	}
+
+	if err := searchFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if searchFlags.NArg() < 2 || searchFlags.Arg(searchFlags.NArg()-1) == "" {
+		searchFlags.Usage()
+		os.Exit(1)
 	}
 
-	// Extract symbols from files matching the pattern
-	result, err := ExtractSymbols(pattern, detail)
+	query := searchFlags.Arg(searchFlags.NArg() - 1)
+	pattern, negations, err := parsePatternArgs(searchFlags.Args()[:searchFlags.NArg()-1])
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to extract symbols: %v", err)), nil
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if pattern == "" {
+		searchFlags.Usage()
+		os.Exit(1)
+	}
+	discoveryOpts, jobs, err := discovery.options(negations)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	outputFormat, err := parseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	publicOnly, err := parseVisibility(*visibility)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := ExtractSymbolsContext(context.Background(), pattern, ExtractOptions{
+		Detail:             Standard,
+		Discovery:          discoveryOpts,
+		PublicOnly:         publicOnly,
+		Name:               query,
+		NameMatch:          *match,
+		MaxSignatureLength: defaultMaxSignatureLength,
+		Format:             outputFormat,
+		Jobs:               jobs,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if result.Format != FormatJSON && result.Format != FormatSARIF {
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	}
+
+	fmt.Print(result.Output)
+	// As with grep, scripts can tell from the exit status whether anything matched
+	if result.Symbols == 0 {
+		os.Exit(1)
+	}
+}
+
+func runCount(args []string) {
+	countFlags := flag.NewFlagSet("count", flag.ExitOnError)
+	jsonOutput := countFlags.Bool("json", false, "Print a JSON document for scripts, such as CI jobs comparing counts between builds")
+	visibility := countFlags.String("visibility", "all", "Symbols to count: all or public (exported)")
+	discovery := addDiscoveryFlags(countFlags)
+
+	countFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s count [options] <pattern>... [!negation...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		countFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s count '/path/to/project/**/*'                  # Count every supported file's symbols\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s count -json -no-tests '/path/to/project/**/*.go' > counts.json # Record counts to compare in CI\n", os.Args[0])
+	}
+
+	if err := countFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	pattern, negations, err := parsePatternArgs(countFlags.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if pattern == "" {
+		countFlags.Usage()
+		os.Exit(1)
+	}
+	discoveryOpts, jobs, err := discovery.options(negations)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	publicOnly, err := parseVisibility(*visibility)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	counts, warnings, err := CountSymbolsContext(context.Background(), pattern, ExtractOptions{
+		Detail:     Minimal,
+		Discovery:  discoveryOpts,
+		PublicOnly: publicOnly,
+		Jobs:       jobs,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
 	}
 
-	return mcp.NewToolResultText(result), nil
+	if !*jsonOutput {
+		fmt.Print(FormatCounts(counts, pattern))
+		return
+	}
+	output, err := FormatCountsJSON(counts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(output)
+}
+
+func runDiff(args []string) {
+	diffFlags := flag.NewFlagSet("diff", flag.ExitOnError)
+	jsonOutput := diffFlags.Bool("json", false, "Print a JSON document for scripts instead of Markdown")
+	visibility := diffFlags.String("visibility", "all", "Symbols to compare: all or public (exported), e.g. to review API changes")
+	discovery := addDiscoveryFlags(diffFlags)
+
+	diffFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s diff [options] <old> <new>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nEach side is a file, a directory, whose files are all compared, or a pattern. The exit status\n")
+		fmt.Fprintf(os.Stderr, "is 1 if any symbol was added, removed or changed, as with diff.\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		diffFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s diff /tmp/project-v1 /path/to/project                 # Compare two checkouts\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s diff -visibility=public '/tmp/v1/**/*.go' '/path/to/project/**/*.go' # Review API changes\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s diff /tmp/server.go.orig /path/to/project/server.go    # Compare two versions of a file\n", os.Args[0])
+	}
+
+	if err := diffFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if diffFlags.NArg() != 2 {
+		diffFlags.Usage()
+		os.Exit(1)
+	}
+
+	var patterns [2]string
+	for i, arg := range diffFlags.Args() {
+		pattern, err := resolvePattern(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			pattern = filepath.Join(pattern, "**")
+		}
+		patterns[i] = pattern
+	}
+	discoveryOpts, jobs, err := discovery.options(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	publicOnly, err := parseVisibility(*visibility)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	changes, warnings, err := DiffOutlines(context.Background(), patterns[0], patterns[1], ExtractOptions{
+		Detail:     Standard,
+		Discovery:  discoveryOpts,
+		PublicOnly: publicOnly,
+		Jobs:       jobs,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	output := FormatDiff(changes)
+	if *jsonOutput {
+		output, err = FormatDiffJSON(changes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Print(output)
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+func runServe(args []string) {
+	serveFlags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := serveFlags.String("addr", "localhost:7070", "Address to serve the REST API on, e.g. :7070 for every interface")
+	maxFiles := serveFlags.Int("max-files", 20000, "Most files a request's pattern may match (0 means no limit)")
+	maxBytes := serveFlags.Int64("max-bytes", 512<<20, "Most bytes of source a request may parse (0 means no limit)")
+	cacheFiles := serveFlags.Int("cache-files", defaultCacheFiles, "Most files whose symbols are cached for later requests, which reuse them while the files are unchanged (0 disables the cache)")
+	allowedOrigins := serveFlags.String("allowed-origins", "", "Comma-separated browser origins allowed to call the API, or * for any")
+	var rootDirs repeatedFlag
+	serveFlags.Var(&rootDirs, "root", "Directory requests may read; repeat for several, and patterns and files outside all of them are rejected (default: no restriction)")
+
+	serveFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s serve [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nEndpoints, each responding with the json format's document:\n")
+		fmt.Fprintf(os.Stderr, "  GET /outline?pattern=...  Symbols of the files matching one or more patterns\n")
+		fmt.Fprintf(os.Stderr, "  GET /file?path=...        Symbols of one file\n")
+		fmt.Fprintf(os.Stderr, "  GET /search?q=...&pattern=...  Symbols whose name fuzzily matches q, with their scores\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		serveFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s serve -root /path/to/project\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  curl 'localhost:7070/search?q=usrSvc&pattern=/path/to/project/**/*.go'\n")
+	}
+
+	if err := serveFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if serveFlags.NArg() > 0 {
+		serveFlags.Usage()
+		os.Exit(1)
+	}
+
+	roots, err := resolveRoots(rootDirs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	handlers := &toolHandlers{limits: ExtractLimits{MaxFiles: *maxFiles, MaxBytes: *maxBytes}, roots: roots}
+	if *cacheFiles > 0 {
+		handlers.cache = NewSymbolCache(*cacheFiles)
+	}
+	var origins []string
+	if *allowedOrigins != "" {
+		origins = strings.Split(*allowedOrigins, ",")
+		for i := range origins {
+			origins[i] = strings.TrimSpace(origins[i])
+		}
+	}
+
+	if err := serveREST(newRESTHandler(handlers, origins), *addr, shutdownGracePeriod); err != nil {
+		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runLanguages(args []string) {
+	languagesFlags := flag.NewFlagSet("languages", flag.ExitOnError)
+	jsonOutput := languagesFlags.Bool("json", false, "Print a JSON document for scripts instead of text")
+
+	languagesFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s languages [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		languagesFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s languages -json | jq -r '.languages[].extensions[]' # List the extensions glyph parses\n", os.Args[0])
+	}
+
+	if err := languagesFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	languages := Languages()
+	if !*jsonOutput {
+		fmt.Print(FormatLanguages(languages))
+		return
+	}
+	output, err := FormatLanguagesJSON(languages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(output)
+}
+
+func runDoctor(args []string) {
+	doctorFlags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	mcpCheck := doctorFlags.Bool("mcp", false, "Also start the MCP server over stdio and perform a handshake")
+
+	doctorFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s doctor [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		doctorFlags.PrintDefaults()
+	}
+
+	if err := doctorFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	var mcpCommand string
+	if *mcpCheck {
+		executable, err := os.Executable()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		mcpCommand = executable
+	}
+
+	checks := RunDoctor(mcpCommand)
+	fmt.Print(FormatDoctor(checks))
+	for _, check := range checks {
+		if !check.OK {
+			os.Exit(1)
+		}
+	}
+}
+
+func printIndexUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s index [options] <pattern>... [!negation...]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s index [export|import] [options]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  <pattern> - Build or update the local index from files matching a pattern, for glyph query and later extractions\n")
+	fmt.Fprintf(os.Stderr, "  export    - Build an index snapshot from files matching a pattern\n")
+	fmt.Fprintf(os.Stderr, "  import    - Install an index snapshot for use by local extractions\n")
+}
+
+func runIndex(args []string) {
+	if len(args) < 1 {
+		printIndexUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "export":
+		runIndexExport(args[1:])
+	case "import":
+		runIndexImport(args[1:])
+	default:
+		runIndexBuild(args)
+	}
+}
+
+func runIndexBuild(args []string) {
+	buildFlags := flag.NewFlagSet("index", flag.ExitOnError)
+	detail := buildFlags.String("detail", "standard", "Level of detail: minimal, standard or full; extractions only use an index of their own level")
+	root := buildFlags.String("root", "", "Root directory the index covers (default: the pattern's base directory)")
+	discovery := addDiscoveryFlags(buildFlags)
+
+	buildFlags.Usage = func() {
+		printIndexUsage()
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		buildFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s index '/repo/**/*'   # Index every supported file in /repo, or update the index\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s query -root /repo NewServer # Then look symbols up without parsing\n", os.Args[0])
+	}
+
+	if err := buildFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	pattern, negations, err := parsePatternArgs(buildFlags.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if pattern == "" {
+		buildFlags.Usage()
+		os.Exit(1)
+	}
+	discoveryOpts, jobs, err := discovery.options(negations)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	indexRoot := *root
+	if indexRoot == "" {
+		indexRoot = PatternBaseDir(pattern)
+	}
+	indexRoot, err = resolvePattern(indexRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	idx, parsed, err := BuildLocalIndex(indexRoot, pattern, ExtractOptions{Detail: ParseDetailLevel(*detail), Discovery: discoveryOpts, Jobs: jobs})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to build index: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Indexed %d files for %s (%d parsed, %d unchanged)\n", len(idx.Files), idx.Root, parsed, len(idx.Files)-parsed)
+}
+
+func runQuery(args []string) {
+	queryFlags := flag.NewFlagSet("query", flag.ExitOnError)
+	root := queryFlags.String("root", "", "Directory whose local index is queried; the index of the nearest indexed parent is used (default: current directory)")
+	match := queryFlags.String("match", "fuzzy", "How the name is matched: fuzzy (subsequence, ranked best first), exact, glob such as 'New*', or regex")
+	kind := queryFlags.String("kind", "", "Only include symbols of this kind, e.g. func, method or class")
+	format := queryFlags.String("format", "quickfix", "Output format: quickfix for path:line:col: kind name: signature lines, compact, ndjson or json")
+
+	queryFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s query [options] <name>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nLooks symbols up in the local index built by glyph index, without parsing any file, and exits\n")
+		fmt.Fprintf(os.Stderr, "with status 1 if none matched. Run glyph index again to bring the index up to date.\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		queryFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s query -root /repo usrSvc                     # Find UserService and the like\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s query -match=exact -kind=func -format=json Parse # Every function named Parse\n", os.Args[0])
+	}
+
+	if err := queryFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if queryFlags.NArg() != 1 || queryFlags.Arg(0) == "" {
+		queryFlags.Usage()
+		os.Exit(1)
+	}
+	switch *format {
+	case FormatQuickfix, FormatCompact, FormatNDJSON, FormatJSON:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format for query: %s\n", *format)
+		os.Exit(1)
+	}
+
+	dir := *root
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		dir = wd
+	}
+	dir, err := resolvePattern(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	idx := FindLocalIndex(dir)
+	if idx == nil {
+		fmt.Fprintf(os.Stderr, "Error: no index covers %s; build one with: %s index '%s/**'\n", dir, os.Args[0], dir)
+		os.Exit(1)
+	}
+
+	symbols, err := idx.Query(queryFlags.Arg(0), *match)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *kind != "" {
+		kept := symbols[:0]
+		for _, sym := range symbols {
+			if sym.Kind == *kind {
+				kept = append(kept, sym)
+			}
+		}
+		symbols = kept
+	}
+
+	result := &ExtractionResult{Status: StatusOK, Files: len(idx.Files), Symbols: len(symbols)}
+	if len(symbols) == 0 {
+		result.Status = StatusNoSymbols
+	}
+	if err := result.setFormatOutput(*format, idx.Root, symbols, nil, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(result.Output)
+	if len(symbols) == 0 {
+		os.Exit(1)
+	}
+}
+
+func runIndexExport(args []string) {
+	exportFlags := flag.NewFlagSet("index export", flag.ExitOnError)
+	detail := exportFlags.String("detail", "standard", "Level of detail: minimal, standard or full")
+	root := exportFlags.String("root", "", "Root directory that indexed paths are relative to (default: the pattern's base directory)")
+	discovery := addDiscoveryFlags(exportFlags)
+
+	exportFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s index export [options] <out.glyphidx> <pattern>... [!negation...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		exportFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s index export -root /repo repo.glyphidx '/repo/**/*.go' # Index all .go files in /repo\n", os.Args[0])
+	}
+
+	if err := exportFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if exportFlags.NArg() < 2 {
+		exportFlags.Usage()
+		os.Exit(1)
+	}
+
+	out := exportFlags.Arg(0)
+	pattern, negations, err := parsePatternArgs(exportFlags.Args()[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if pattern == "" {
+		exportFlags.Usage()
+		os.Exit(1)
+	}
+	discoveryOpts, jobs, err := discovery.options(negations)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	indexRoot := *root
+	if indexRoot == "" {
+		indexRoot = PatternBaseDir(pattern)
+	}
+	indexRoot, err = resolvePattern(indexRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	idx, err := BuildIndex(indexRoot, pattern, ExtractOptions{Detail: ParseDetailLevel(*detail), Discovery: discoveryOpts, Jobs: jobs})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := idx.Write(out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write index: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Indexed %d files into %s\n", len(idx.Files), out)
+}
+
+func runIndexImport(args []string) {
+	importFlags := flag.NewFlagSet("index import", flag.ExitOnError)
+	root := importFlags.String("root", "", "Local directory the snapshot describes (default: current directory)")
+
+	importFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s index import [options] <in.glyphidx>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		importFlags.PrintDefaults()
+	}
+
+	if err := importFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if importFlags.NArg() < 1 {
+		importFlags.Usage()
+		os.Exit(1)
+	}
+
+	indexRoot := *root
+	if indexRoot == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		indexRoot = wd
+	}
+	indexRoot, err := resolvePattern(indexRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	idx, err := ImportIndex(importFlags.Arg(0), indexRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to import index: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Imported %d files for %s\n", len(idx.Files), idx.Root)
+}
+
+func runMCPServer(args []string) {
+	// Set up MCP flags
+	mcpFlags := flag.NewFlagSet("mcp", flag.ExitOnError)
+	maxConcurrent := mcpFlags.Int("max-concurrent", runtime.NumCPU(), "Most extractions run at once; further tool calls wait for a free slot")
+	maxFiles := mcpFlags.Int("max-files", 20000, "Most files a tool call's pattern may match (0 means no limit)")
+	maxBytes := mcpFlags.Int64("max-bytes", 512<<20, "Most bytes of source a tool call may parse (0 means no limit)")
+	cacheFiles := mcpFlags.Int("cache-files", defaultCacheFiles, "Most files whose symbols are cached for later tool calls, which reuse them while the files are unchanged (0 disables the cache)")
+	httpAddr := mcpFlags.String("http", "", "Serve the streamable HTTP transport on this address, e.g. :8080, instead of stdio")
+	httpPath := mcpFlags.String("http-path", "/mcp", "Endpoint path of the HTTP transport")
+	sessionSecret := mcpFlags.String("session-secret", os.Getenv("GLYPH_SESSION_SECRET"), "Secret signing HTTP session IDs, shared by replicas behind a load balancer (default: $GLYPH_SESSION_SECRET, or a random secret)")
+	stateless := mcpFlags.Bool("stateless", false, "Issue no HTTP session IDs; every request stands alone")
+	allowedOrigins := mcpFlags.String("allowed-origins", "", "Comma-separated browser origins allowed to call the HTTP transport, or * for any")
+	var rootDirs repeatedFlag
+	mcpFlags.Var(&rootDirs, "root", "Directory tool calls may read; repeat for several, and patterns, files and resources outside all of them are rejected (default: no restriction)")
+
+	if err := mcpFlags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	roots, err := resolveRoots(rootDirs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create MCP server
+	hooks := &server.Hooks{}
+	mcpServer := server.NewMCPServer(
+		"glyph",
+		"1.0.0",
+		server.WithToolCapabilities(false),
+		server.WithResourceCapabilities(true, false),
+		server.WithPromptCapabilities(false),
+		server.WithLogging(),
+		server.WithHooks(hooks),
+	)
+
+	// Register tools
+	extractSymbolsTool := mcp.NewTool(
+		"extract_symbols",
+		mcp.WithDescription("Extract symbol outlines from source code files using tree-sitter parsing. Results carry the selected symbols as structuredContent, the json format's document, alongside the text outline"),
+		mcp.WithRawOutputSchema(toolOutputSchema("outline")),
+		withPatterns("pattern", mcp.Description("Absolute path glob pattern to match files (e.g., '/path/to/project/**/*.go', '/home/user/src/**/*.js'); a leading ~ and $VARS are expanded. An array of patterns, or a comma-separated list, extracts the files of all of them, each once (e.g., ['/repo/cmd/**/*.go', '/repo/web/**/*.ts']). Required unless content is given, in which case it is the absolute path the content is reported as")),
+		mcp.WithString("content", mcp.Description("Source code to outline instead of files on disk, such as an unsaved editor buffer or a generated snippet")),
+		mcp.WithString("language", mcp.Description("Language of content: "+contentLanguages+" (default: the language of the pattern's extension)")),
+		mcp.WithString("detail", mcp.Description("Level of detail: 'minimal', 'standard' or 'full' (default: 'standard')")),
+		mcp.WithString("mode", mcp.Description("Extraction mode: 'symbols' for symbol outlines, 'strings' for notable string literals such as SQL queries, URLs, regexes and templates (default: 'symbols')")),
+		mcp.WithString("coverage", mcp.Description("Absolute path of a Go coverprofile or lcov file; symbols are annotated with their line coverage")),
+		mcp.WithBoolean("source_maps", mcp.Description("Map symbols in generated .js files with adjacent source maps back to their original sources (default: false)")),
+		mcp.WithBoolean("include_nested_modules", mcp.Description("Include nested Go modules and vendor directories, which are skipped by default (default: false)")),
+		mcp.WithArray("exclude", mcp.Items(map[string]any{"type": "string"}), mcp.Description("Gitignore-style negation patterns whose matches are subtracted, e.g. ['!**/*.d.ts']; relative patterns are resolved against the pattern's base directory")),
+		mcp.WithString("path_regex", mcp.Description("Regular expression; only files matched by the pattern whose path also matches it are included")),
+		mcp.WithString("path_exclude_regex", mcp.Description("Regular expression; files matched by the pattern whose path matches it are excluded")),
+		mcp.WithBoolean("hidden", mcp.Description("Include dotfiles and dot-directories such as .venv, .idea and .cache, which are skipped by default (default: false)")),
+		mcp.WithString("modified_within", mcp.Description("Only include files modified within this duration, e.g. '7d', '2w' or '36h'")),
+		mcp.WithString("modified_after", mcp.Description("Only include files modified after this date (YYYY-MM-DD or RFC 3339)")),
+		mcp.WithString("modified_before", mcp.Description("Only include files modified before this date (YYYY-MM-DD or RFC 3339)")),
+		mcp.WithBoolean("include_generated", mcp.Description("Include generated files (*.pb.go, *_gen.go, *.min.js, \"Code generated ... DO NOT EDIT.\" headers), which are skipped by default (default: false)")),
+		mcp.WithBoolean("no_tests", mcp.Description("Skip test files such as _test.go, *.spec.ts, test_*.py, *Test.java and files in test directories (default: false)")),
+		mcp.WithString("group_by", mcp.Description("Group output by 'file' or 'package' (Go import path) (default: 'file')")),
+		mcp.WithNumber("start_line", mcp.Description("Only include symbols intersecting lines start_line to end_line; a 'path:120-340' pattern suffix works too")),
+		mcp.WithNumber("end_line", mcp.Description("Last line of the range given by start_line (default: end of file)")),
+		mcp.WithString("name", mcp.Description("Only include symbols whose name matches this value, as selected by name_match")),
+		mcp.WithString("name_match", mcp.Description("How name is matched: 'regex' (default), 'exact', 'glob' such as 'New*' or '*Handler', or 'fuzzy' for subsequence matching such as 'usrSvc' finding UserService, with results ranked best first")),
+		mcp.WithString("query", mcp.Description("Custom tree-sitter query reported instead of the built-in symbols; it must have an @name capture, and an optional @symbol capture sets the reported range, e.g. '(call_expression function: (identifier) @name) @symbol'")),
+		mcp.WithString("annotated_with", mcp.Description("Only include symbols carrying this Java annotation, Python decorator or TS decorator, e.g. '@RestController' or '@app.route'")),
+		mcp.WithString("deprecated", mcp.Description("Deprecated symbols, marked by a Go 'Deprecated:' doc comment, Java @Deprecated, a JSDoc or Javadoc @deprecated tag or Python @warnings.deprecated: 'include', 'exclude' or 'only' (default: 'include')")),
+		mcp.WithString("receiver", mcp.Description("Only include Go methods defined on this type, e.g. 'Server' (pointer and value receivers alike)")),
+		mcp.WithNumber("min_lines", mcp.Description("Omit symbols spanning fewer lines than this, such as one-line getters and constants (default: 0)")),
+		mcp.WithNumber("summarize", mcp.Description("If the outline would exceed this many bytes, collapse the largest files to their top-level symbols until it fits, noting each collapse, instead of returning a huge response (default: 0, disabled)")),
+		mcp.WithNumber("max_chars", mcp.Description("Keep the response under this many characters: the largest files are first collapsed to their top-level symbols, then the outline is cut at a symbol boundary with a note of how many symbols were omitted from how many files (default: 0, no limit)")),
+		mcp.WithNumber("max_tokens", mcp.Description("Like max_chars, counting roughly 4 characters per token; the smaller of the two applies")),
+		mcp.WithNumber("max_signature_length", mcp.Description("Truncate signatures longer than this many characters at a token boundary, e.g. long struct literals and generic signatures (default: 300; 0 means no limit)")),
+		mcp.WithNumber("max_symbols_per_file", mcp.Description("Show at most this many symbols per file, followed by a count of the rest, so one huge file can't dominate the outline (default: 0, no limit)")),
+		mcp.WithNumber("depth", mcp.Description("Levels of nested symbols to show, e.g. 1 for top-level declarations only (default: 0, all levels)")),
+		mcp.WithString("format", mcp.Description("Output format: 'markdown' (default), 'json' for a document listing the symbols of every file with their children, file metadata and IDs, as described by the output schema, 'ndjson' for one JSON object per symbol and line with its parent's ID, 'ctags' for a Universal Ctags tags file, 'sarif' for a SARIF 2.1.0 log, 'csv' or 'tsv' for a table with file, kind, name, start, end and signature columns, 'dot' for a Graphviz graph of files containing symbols, 'tree' for an outline drawn with box-drawing characters, 'compact' for one 'path:line kind name(params)' line per symbol, using the fewest tokens, 'html' for a standalone HTML report, or 'quickfix' for one 'path:line:col: kind name: signature' location per symbol; the outline display options such as depth and summarize do not apply")),
+		mcp.WithNumber("page_size", mcp.Description("Extract at most this many of the matched files per call; when more remain, the result's _meta carries a next_cursor to pass back as cursor (default: 0, all files)")),
+		mcp.WithString("cursor", mcp.Description("The next_cursor of the previous page, with the same pattern and page_size")),
+		mcp.WithString("visibility", mcp.Description("Symbols to include: 'all', or 'public' for exported Go identifiers, exported JS/TS declarations, public Java members, Python names without a leading underscore, public C# and PHP members and Rust 'pub' items (default: 'all')")),
+	)
+
+	extractContentTool := mcp.NewTool(
+		"extract_symbols_from_content",
+		mcp.WithDescription("Extract a symbol outline from source code passed inline, such as an unsaved editor buffer, a diff hunk or generated code, without writing it to a file"),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Source code to outline")),
+		mcp.WithString("language", mcp.Description("Language of content: "+contentLanguages+"; required unless path has a supported extension")),
+		mcp.WithString("path", mcp.Description("Path the content is reported as, e.g. the file an editor buffer belongs to; its extension selects the language when none is given (default: '<content>')")),
+		mcp.WithString("detail", mcp.Description("Level of detail: 'minimal', 'standard' or 'full' (default: 'standard')")),
+		mcp.WithString("name", mcp.Description("Only include symbols whose name matches this value, as selected by name_match")),
+		mcp.WithString("name_match", mcp.Description("How name is matched: 'regex' (default), 'exact', 'glob', or 'fuzzy' for subsequence matching, with results ranked best first")),
+		mcp.WithString("query", mcp.Description("Custom tree-sitter query reported instead of the built-in symbols; it must have an @name capture")),
+		mcp.WithNumber("start_line", mcp.Description("Only include symbols intersecting lines start_line to end_line")),
+		mcp.WithNumber("end_line", mcp.Description("Last line of the range given by start_line (default: end of content)")),
+		mcp.WithNumber("max_signature_length", mcp.Description("Truncate signatures longer than this many characters at a token boundary (default: 300; 0 means no limit)")),
+		mcp.WithNumber("depth", mcp.Description("Levels of nested symbols to show, e.g. 1 for top-level declarations only (default: 0, all levels)")),
+		mcp.WithString("format", mcp.Description("Output format, as for extract_symbols: 'markdown' (default), 'json', 'ndjson', 'ctags', 'sarif', 'csv', 'tsv', 'dot', 'tree', 'compact', 'html' or 'quickfix'")),
+		mcp.WithString("visibility", mcp.Description("Symbols to include: 'all', or 'public' for the exported or public symbols of the language (default: 'all')")),
+	)
+
+	findReferencesTool := mcp.NewTool(
+		"find_references",
+		mcp.WithDescription("Find the identifier usages of a symbol name in source files, grouped by file and by the function, method or type enclosing each usage; declarations of the name, comments and string literals are not usages"),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Identifier to find, e.g. 'NewServer'; matched exactly against identifiers, so 'Server' does not match 'NewServer'")),
+		withPatterns("pattern", mcp.Required(), mcp.Description("Absolute path glob pattern of the files to search (e.g., '/path/to/project/**/*.go'); a leading ~ and $VARS are expanded. An array of patterns, or a comma-separated list, covers the files of all of them")),
+		mcp.WithArray("exclude", mcp.Items(map[string]any{"type": "string"}), mcp.Description("Gitignore-style negation patterns whose matches are subtracted, e.g. ['!**/*.d.ts']")),
+		mcp.WithBoolean("hidden", mcp.Description("Include dotfiles and dot-directories, which are skipped by default (default: false)")),
+		mcp.WithBoolean("include_generated", mcp.Description("Include generated files, which are skipped by default (default: false)")),
+		mcp.WithBoolean("include_nested_modules", mcp.Description("Include nested Go modules and vendor directories, which are skipped by default (default: false)")),
+		mcp.WithBoolean("no_tests", mcp.Description("Skip test files and files in test directories (default: false)")),
+	)
+
+	findDefinitionTool := mcp.NewTool(
+		"find_definition",
+		mcp.WithDescription("Find where a symbol is defined: the file, line range and signature of every symbol named exactly name, without the rest of the outline"),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Exact symbol name, e.g. 'NewServer'; methods and nested symbols are found by their own name and reported qualified, e.g. 'Server.Start'")),
+		withPatterns("pattern", mcp.Required(), mcp.Description("Absolute path glob pattern of the files to search (e.g., '/path/to/project/**/*.go'); a leading ~ and $VARS are expanded. An array of patterns, or a comma-separated list, covers the files of all of them")),
+		mcp.WithArray("exclude", mcp.Items(map[string]any{"type": "string"}), mcp.Description("Gitignore-style negation patterns whose matches are subtracted, e.g. ['!**/*.d.ts']")),
+		mcp.WithBoolean("hidden", mcp.Description("Include dotfiles and dot-directories, which are skipped by default (default: false)")),
+		mcp.WithBoolean("include_generated", mcp.Description("Include generated files, which are skipped by default (default: false)")),
+		mcp.WithBoolean("include_nested_modules", mcp.Description("Include nested Go modules and vendor directories, which are skipped by default (default: false)")),
+		mcp.WithBoolean("no_tests", mcp.Description("Skip test files and files in test directories (default: false)")),
+		mcp.WithNumber("max_signature_length", mcp.Description("Truncate signatures longer than this many characters at a token boundary (default: 300; 0 means no limit)")),
+	)
+
+	repoMapTool := mcp.NewTool(
+		"repo_map",
+		mcp.WithDescription("Produce a condensed map of a repository within a token budget: the files whose symbols are mentioned by the most other files come first, each with its most mentioned top-level symbols and members, so an agent can orient itself before reading code"),
+		withPatterns("pattern", mcp.Required(), mcp.Description("Absolute path glob pattern of the files to map (e.g., '/path/to/project/**/*.go'); a leading ~ and $VARS are expanded. An array of patterns, or a comma-separated list, covers the files of all of them")),
+		mcp.WithNumber("max_tokens", mcp.Description("Keep the map under about this many tokens, counted as 4 characters each; files that no longer fit are left out and counted (default: 1024)")),
+		mcp.WithNumber("max_chars", mcp.Description("Keep the map under this many characters; the smaller of max_chars and max_tokens applies")),
+		mcp.WithArray("exclude", mcp.Items(map[string]any{"type": "string"}), mcp.Description("Gitignore-style negation patterns whose matches are subtracted, e.g. ['!**/*.d.ts']")),
+		mcp.WithBoolean("hidden", mcp.Description("Include dotfiles and dot-directories, which are skipped by default (default: false)")),
+		mcp.WithBoolean("include_generated", mcp.Description("Include generated files, which are skipped by default (default: false)")),
+		mcp.WithBoolean("include_nested_modules", mcp.Description("Include nested Go modules and vendor directories, which are skipped by default (default: false)")),
+		mcp.WithBoolean("no_tests", mcp.Description("Skip test files and files in test directories (default: false)")),
+		mcp.WithNumber("max_signature_length", mcp.Description("Truncate signatures longer than this many characters at a token boundary (default: 120; 0 means no limit)")),
+	)
+
+	listFilesTool := mcp.NewTool(
+		"list_files",
+		mcp.WithDescription("List the files a pattern matches, with the language and size of each and totals per language, without parsing them, so an agent can estimate the cost of an extraction and refine the pattern first"),
+		withPatterns("pattern", mcp.Required(), mcp.Description("Absolute path glob pattern of the files to list (e.g., '/path/to/project/**/*.go'); a leading ~ and $VARS are expanded. An array of patterns, or a comma-separated list, covers the files of all of them")),
+		mcp.WithArray("exclude", mcp.Items(map[string]any{"type": "string"}), mcp.Description("Gitignore-style negation patterns whose matches are subtracted, e.g. ['!**/*.d.ts']")),
+		mcp.WithBoolean("hidden", mcp.Description("Include dotfiles and dot-directories, which are skipped by default (default: false)")),
+		mcp.WithBoolean("include_generated", mcp.Description("Include generated files, which are skipped by default (default: false)")),
+		mcp.WithBoolean("include_nested_modules", mcp.Description("Include nested Go modules and vendor directories, which are skipped by default (default: false)")),
+		mcp.WithBoolean("no_tests", mcp.Description("Skip test files and files in test directories (default: false)")),
+		mcp.WithNumber("page_size", mcp.Description("List at most this many of the matched files per call; the totals still cover every file, and when more remain the result's _meta carries a next_cursor to pass back as cursor (default: 0, all files)")),
+		mcp.WithString("cursor", mcp.Description("The next_cursor of the previous page, with the same pattern and page_size")),
+	)
+
+	astDumpTool := mcp.NewTool(
+		"ast_dump",
+		mcp.WithDescription("Return the Tree-sitter syntax tree of one file as an S-expression of named nodes with their field names, line:column ranges and leaf text, for writing custom queries or finding out why a symbol isn't extracted"),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Absolute path of the file to parse; a leading ~ and $VARS are expanded")),
+		mcp.WithNumber("depth", mcp.Description("Show at most this many levels of nodes, counting the root as 1, and elide deeper ones with … (default: 0, all levels)")),
+		mcp.WithNumber("start_line", mcp.Description("Only show nodes ending on or after this 1-based line")),
+		mcp.WithNumber("end_line", mcp.Description("Only show nodes starting on or before this 1-based line")),
+		mcp.WithNumber("max_tokens", mcp.Description("Cut the tree off at about this many tokens, counted as 4 characters each")),
+		mcp.WithNumber("max_chars", mcp.Description("Cut the tree off at this many characters; the smaller of max_chars and max_tokens applies")),
+	)
+
+	tracker := newRequestTracker(*maxConcurrent)
+	handlers := &toolHandlers{limits: ExtractLimits{MaxFiles: *maxFiles, MaxBytes: *maxBytes}, roots: roots}
+	if *cacheFiles > 0 {
+		handlers.cache = NewSymbolCache(*cacheFiles)
+	}
+	mcpServer.AddTool(extractSymbolsTool, tracker.wrap(handlers.extractSymbols))
+	mcpServer.AddTool(extractContentTool, tracker.wrap(handlers.extractSymbolsFromContent))
+	mcpServer.AddTool(findReferencesTool, tracker.wrap(handlers.findReferences))
+	mcpServer.AddTool(findDefinitionTool, tracker.wrap(handlers.findDefinition))
+	mcpServer.AddTool(repoMapTool, tracker.wrap(handlers.repoMap))
+	mcpServer.AddTool(listFilesTool, tracker.wrap(handlers.listFiles))
+	mcpServer.AddTool(astDumpTool, tracker.wrap(handlers.astDump))
+	mcpServer.AddResourceTemplate(outlineTemplate, tracker.wrapResource(handlers.readOutline))
+	mcpServer.AddPrompt(explorePrompt, exploreRepository)
+	mcpServer.AddPrompt(summarizePrompt, summarizeModule)
+
+	subs, err := newOutlineSubscriptions(tracker,
+		func(ctx context.Context, uri string) (string, error) {
+			return handlers.outlineText(ctx, uri, FormatCompact)
+		},
+		func(sessionID, uri string) {
+			// Sessions that are not listening for notifications miss the update
+			_ = mcpServer.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+		},
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer subs.Close()
+	// Subscriptions last as long as their session listens for notifications
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		subs.sessionRegistered(session.SessionID())
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		subs.sessionUnregistered(session.SessionID())
+	})
+
+	// Start server
+	if *httpAddr != "" {
+		var origins []string
+		if *allowedOrigins != "" {
+			origins = strings.Split(*allowedOrigins, ",")
+			for i := range origins {
+				origins[i] = strings.TrimSpace(origins[i])
+			}
+		}
+		err := serveHTTP(mcpServer, tracker, subs, shutdownGracePeriod, HTTPOptions{
+			Addr:           *httpAddr,
+			Path:           *httpPath,
+			SessionSecret:  *sessionSecret,
+			Stateless:      *stateless,
+			AllowedOrigins: origins,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := serveStdio(mcpServer, tracker, subs, shutdownGracePeriod); err != nil {
+		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// toolHandlers implements the MCP tools, applying the server's limits to every call
+type toolHandlers struct {
+	limits ExtractLimits
+	// roots are the directories the server may read; empty means any
+	roots []string
+	// cache is shared by all tool calls; nil disables it
+	cache *SymbolCache
+}
+
+func (h *toolHandlers) extractSymbols(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Inline content is outlined instead of files, with pattern naming it
+	content, inline := request.GetArguments()["content"].(string)
+	pattern := ""
+	if _, ok := request.GetArguments()["pattern"]; ok {
+		var err error
+		if pattern, err = requirePattern(request); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if pattern == "" && !inline {
+		return mcp.NewToolResultError("pattern argument is required"), nil
+	}
+	if inline && len(SplitPatterns(pattern)) > 1 {
+		return mcp.NewToolResultError("content is reported as a single path, not a list of patterns"), nil
+	}
+
+	detail := "standard"
+	if d := request.GetString("detail", ""); d != "" {
+		detail = d
+	}
+
+	mode := request.GetString("mode", "symbols")
+	coverage := request.GetString("coverage", "")
+	sourceMaps := request.GetBool("source_maps", false)
+	includeNestedModules := request.GetBool("include_nested_modules", false)
+	noTests := request.GetBool("no_tests", false)
+	includeGenerated := request.GetBool("include_generated", false)
+
+	groupByPackage, err := parseGroupBy(request.GetString("group_by", "file"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	publicOnly, err := parseVisibility(request.GetString("visibility", "all"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	includeRegex, err := compilePathRegex("path_regex", request.GetString("path_regex", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	excludeRegex, err := compilePathRegex("path_exclude_regex", request.GetString("path_exclude_regex", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	after, before, err := parseModifiedRange(request.GetString("modified_within", ""),
+		request.GetString("modified_after", ""), request.GetString("modified_before", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	format, err := parseOutputFormat(request.GetString("format", FormatMarkdown), mode)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	maxChars, err := parseOutputBudget(request.GetInt("max_chars", 0), request.GetInt("max_tokens", 0))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if maxChars > 0 && isStructuredFormat(format) {
+		return mcp.NewToolResultError(fmt.Sprintf("max_chars and max_tokens cannot be combined with format %s", format)), nil
+	}
+	// Collapsing files to their top-level symbols comes before cutting any off
+	summarize := request.GetInt("summarize", 0)
+	if maxChars > 0 && (summarize == 0 || summarize > maxChars) {
+		summarize = maxChars
+	}
+
+	if inline && pattern != "" {
+		// The pattern only names the content, which is not read from it
+		pattern, err = resolvePattern(pattern)
+	} else if pattern != "" {
+		pattern, err = h.resolvePattern(pattern)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	excludes, err := expandExcludes(request.GetStringSlice("exclude", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if coverage != "" && !filepath.IsAbs(coverage) {
+		return mcp.NewToolResultError(fmt.Sprintf("coverage must be an absolute path, got: %s", coverage)), nil
+	}
+	if coverage != "" {
+		if err := h.checkRoots(coverage); err != nil {
+			return mcp.NewToolResultError("coverage " + err.Error()), nil
+		}
+	}
+
+	opts := ExtractOptions{
+		Limits:     h.limits,
+		Cache:      h.cache,
+		Detail:     ParseDetailLevel(detail),
+		Coverage:   coverage,
+		SourceMaps: sourceMaps,
+		Discovery: DiscoveryOptions{
+			IncludeNestedModules: includeNestedModules,
+			ExcludeTests:         noTests,
+			IncludeGenerated:     includeGenerated,
+			Hidden:               request.GetBool("hidden", false),
+			PathRegex:            includeRegex,
+			PathExcludeRegex:     excludeRegex,
+			Exclude:              excludes,
+			Roots:                h.roots,
+			ModifiedAfter:        after,
+			ModifiedBefore:       before,
+		},
+		GroupByPackage:     groupByPackage,
+		PublicOnly:         publicOnly,
+		Depth:              request.GetInt("depth", 0),
+		MaxSymbolsPerFile:  request.GetInt("max_symbols_per_file", 0),
+		MaxSignatureLength: request.GetInt("max_signature_length", defaultMaxSignatureLength),
+		Summarize:          summarize,
+		MinLines:           request.GetInt("min_lines", 0),
+		AnnotatedWith:      request.GetString("annotated_with", ""),
+		Deprecated:         request.GetString("deprecated", DeprecatedInclude),
+		Query:              request.GetString("query", ""),
+		Name:               request.GetString("name", ""),
+		NameMatch:          request.GetString("name_match", "regex"),
+		Receiver:           request.GetString("receiver", ""),
+		Lines:              LineRange{Start: uint32(max(request.GetInt("start_line", 0), 0)), End: uint32(max(request.GetInt("end_line", 0), 0))},
+		Page:               Page{Cursor: request.GetString("cursor", ""), Size: request.GetInt("page_size", 0)},
+		Progress:           toolProgress(ctx, request),
+		Log:                toolLog(ctx),
+		Format:             format,
+		Structured:         true,
+	}
+
+	var result *ExtractionResult
+	if inline {
+		if mode != "" && mode != "symbols" {
+			return mcp.NewToolResultError("content is only supported in symbols mode"), nil
+		}
+		result, err = ExtractContentContext(ctx, pattern, []byte(content), request.GetString("language", ""), opts)
+	} else {
+		// Extract symbols from files matching the pattern
+		result, err = extract(ctx, pattern, mode, opts)
+	}
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return newLimitToolResult(limitErr), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to extract symbols: %v", err)), nil
+	}
+	result.Output = TruncateOutput(result.Output, maxChars)
+	// Strings mode lists no symbols, but a tool with an output schema answers
+	// every successful call with structured content
+	if result.Structured == nil && result.Status != StatusNoFiles {
+		result.Structured = result.newJSONOutline(nil, nil, nil)
+	}
+
+	return newExtractionToolResult(result), nil
+}
+
+// requirePattern returns the pattern argument of a tool call, which is one glob or
+// an array of them, as a pattern list
+func requirePattern(request mcp.CallToolRequest) (string, error) {
+	if _, ok := request.GetArguments()["pattern"].(string); ok {
+		return request.RequireString("pattern")
+	}
+	patterns, err := request.RequireStringSlice("pattern")
+	if err != nil {
+		return "", err
+	}
+	if len(patterns) == 0 {
+		return "", fmt.Errorf("pattern must contain at least one pattern")
+	}
+	return strings.Join(patterns, ","), nil
+}
+
+// withPatterns adds a parameter taking one glob pattern or an array of them to a
+// tool's schema, like mcp.WithString
+func withPatterns(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		schema := map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "minItems": 1},
+			},
+		}
+		for _, opt := range opts {
+			opt(schema)
+		}
+		if required, ok := schema["required"].(bool); ok && required {
+			delete(schema, "required")
+			t.InputSchema.Required = append(t.InputSchema.Required, name)
+		}
+		t.InputSchema.Properties[name] = schema
+	}
+}
+
+// parseOutputBudget returns the most characters a response may hold given a
+// character and a token budget, or 0 when neither is set. Tokens are counted as
+// about 4 characters, which holds for English text and most source code.
+func parseOutputBudget(maxChars, maxTokens int) (int, error) {
+	if maxChars < 0 || maxTokens < 0 {
+		return 0, fmt.Errorf("max_chars and max_tokens must not be negative")
+	}
+	budget := maxChars
+	if maxTokens > 0 && (budget == 0 || maxTokens*4 < budget) {
+		budget = maxTokens * 4
+	}
+	return budget, nil
+}
+
+// extractSymbolsFromContent outlines source code passed inline rather than read
+// from files, taking the options of extract_symbols that apply to a single buffer
+func (h *toolHandlers) extractSymbolsFromContent(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	content, err := request.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	publicOnly, err := parseVisibility(request.GetString("visibility", "all"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	format, err := parseOutputFormat(request.GetString("format", FormatMarkdown), "symbols")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	opts := ExtractOptions{
+		Limits:             h.limits,
+		Detail:             ParseDetailLevel(request.GetString("detail", "standard")),
+		PublicOnly:         publicOnly,
+		Depth:              request.GetInt("depth", 0),
+		MaxSignatureLength: request.GetInt("max_signature_length", defaultMaxSignatureLength),
+		Query:              request.GetString("query", ""),
+		Name:               request.GetString("name", ""),
+		NameMatch:          request.GetString("name_match", "regex"),
+		Lines:              LineRange{Start: uint32(max(request.GetInt("start_line", 0), 0)), End: uint32(max(request.GetInt("end_line", 0), 0))},
+		Format:             format,
+		Log:                toolLog(ctx),
+	}
+
+	result, err := ExtractContentContext(ctx, request.GetString("path", ""), []byte(content), request.GetString("language", ""), opts)
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return newLimitToolResult(limitErr), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to extract symbols: %v", err)), nil
+	}
+
+	return newExtractionToolResult(result), nil
+}
+
+// findReferences lists the usages of an identifier in the files matching a pattern
+func (h *toolHandlers) findReferences(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pattern, err := requirePattern(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pattern, err = h.resolvePattern(pattern)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	excludes, err := expandExcludes(request.GetStringSlice("exclude", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := FindReferencesContext(ctx, name, pattern, ExtractOptions{
+		Limits: h.limits,
+		Cache:  h.cache,
+		Detail: Standard,
+		Discovery: DiscoveryOptions{
+			IncludeNestedModules: request.GetBool("include_nested_modules", false),
+			ExcludeTests:         request.GetBool("no_tests", false),
+			IncludeGenerated:     request.GetBool("include_generated", false),
+			Hidden:               request.GetBool("hidden", false),
+			Exclude:              excludes,
+			Roots:                h.roots,
+		},
+		Progress: toolProgress(ctx, request),
+		Log:      toolLog(ctx),
+	})
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return newLimitToolResult(limitErr), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to find references: %v", err)), nil
+	}
+
+	return newExtractionToolResult(result), nil
+}
+
+// findDefinition lists the symbols named exactly name in the files matching a pattern
+func (h *toolHandlers) findDefinition(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pattern, err := requirePattern(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pattern, err = h.resolvePattern(pattern)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	excludes, err := expandExcludes(request.GetStringSlice("exclude", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := FindDefinitionsContext(ctx, name, pattern, ExtractOptions{
+		Limits: h.limits,
+		Cache:  h.cache,
+		Detail: Standard,
+		Discovery: DiscoveryOptions{
+			IncludeNestedModules: request.GetBool("include_nested_modules", false),
+			ExcludeTests:         request.GetBool("no_tests", false),
+			IncludeGenerated:     request.GetBool("include_generated", false),
+			Hidden:               request.GetBool("hidden", false),
+			Exclude:              excludes,
+			Roots:                h.roots,
+		},
+		MaxSignatureLength: request.GetInt("max_signature_length", defaultMaxSignatureLength),
+		Progress:           toolProgress(ctx, request),
+		Log:                toolLog(ctx),
+	})
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return newLimitToolResult(limitErr), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to find definitions: %v", err)), nil
+	}
+
+	return newExtractionToolResult(result), nil
+}
+
+// repoMap maps the files matching a pattern, most mentioned first, within a budget
+func (h *toolHandlers) repoMap(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pattern, err := requirePattern(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pattern, err = h.resolvePattern(pattern)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	maxChars, maxTokens := request.GetInt("max_chars", 0), request.GetInt("max_tokens", 0)
+	if maxChars == 0 && maxTokens == 0 {
+		maxTokens = defaultRepoMapTokens
+	}
+	budget, err := parseOutputBudget(maxChars, maxTokens)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	excludes, err := expandExcludes(request.GetStringSlice("exclude", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := RepoMapContext(ctx, pattern, budget, ExtractOptions{
+		Limits: h.limits,
+		Cache:  h.cache,
+		Detail: Standard,
+		Discovery: DiscoveryOptions{
+			IncludeNestedModules: request.GetBool("include_nested_modules", false),
+			ExcludeTests:         request.GetBool("no_tests", false),
+			IncludeGenerated:     request.GetBool("include_generated", false),
+			Hidden:               request.GetBool("hidden", false),
+			Exclude:              excludes,
+			Roots:                h.roots,
+		},
+		MaxSignatureLength: request.GetInt("max_signature_length", defaultRepoMapSignatureLength),
+		Progress:           toolProgress(ctx, request),
+		Log:                toolLog(ctx),
+	})
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return newLimitToolResult(limitErr), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to map repository: %v", err)), nil
+	}
+
+	return newExtractionToolResult(result), nil
+}
+
+// listFiles lists the files matching a pattern without parsing them
+func (h *toolHandlers) listFiles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pattern, err := requirePattern(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pattern, err = h.resolvePattern(pattern)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	excludes, err := expandExcludes(request.GetStringSlice("exclude", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := ListFilesContext(ctx, pattern, ExtractOptions{
+		Limits: h.limits,
+		Discovery: DiscoveryOptions{
+			IncludeNestedModules: request.GetBool("include_nested_modules", false),
+			ExcludeTests:         request.GetBool("no_tests", false),
+			IncludeGenerated:     request.GetBool("include_generated", false),
+			Hidden:               request.GetBool("hidden", false),
+			Exclude:              excludes,
+			Roots:                h.roots,
+		},
+		Page: Page{Cursor: request.GetString("cursor", ""), Size: request.GetInt("page_size", 0)},
+		Log:  toolLog(ctx),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list files: %v", err)), nil
+	}
+
+	return newExtractionToolResult(result), nil
+}
+
+// astDump returns the syntax tree of one file
+func (h *toolHandlers) astDump(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	path, err = ExpandPath(path)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !filepath.IsAbs(path) {
+		return mcp.NewToolResultError(fmt.Sprintf("path must be an absolute path, got: %s", path)), nil
+	}
+	if err := h.checkRoots(path); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	depth := request.GetInt("depth", 0)
+	if depth < 0 {
+		return mcp.NewToolResultError("depth must not be negative"), nil
+	}
+	lines := LineRange{Start: uint32(max(request.GetInt("start_line", 0), 0)), End: uint32(max(request.GetInt("end_line", 0), 0))}
+	if lines.Start > 0 && lines.End > 0 && lines.End < lines.Start {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid line range %d-%d", lines.Start, lines.End)), nil
+	}
+	maxChars, err := parseOutputBudget(request.GetInt("max_chars", 0), request.GetInt("max_tokens", 0))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is a directory, not a file", path)), nil
+	}
+	var limitErr *LimitError
+	if err := h.limits.check(1, info.Size()); errors.As(err, &limitErr) {
+		return newLimitToolResult(limitErr), nil
+	}
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+	output, err := extractor.DumpAST(path, depth, lines)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to dump syntax tree: %v", err)), nil
+	}
+
+	return newExtractionToolResult(&ExtractionResult{Status: StatusOK, Files: 1, Output: TruncateOutput(output, maxChars)}), nil
+}
+
+// newExtractionToolResult converts an extraction result to a tool result. The outline
+// is returned as text, while the status, counts and warnings are attached as metadata
+// so clients can tell an empty result from a real outline. A pattern that matches no
+// files is reported as an error.
+func newExtractionToolResult(result *ExtractionResult) *mcp.CallToolResult {
+	text := result.Output
+	if len(result.Warnings) > 0 && result.Format == "" {
+		text += "\n## Warnings\n\n- " + strings.Join(result.Warnings, "\n- ") + "\n"
+	}
+	if result.NextCursor != "" && result.Format == "" {
+		text += fmt.Sprintf("\nMore of the %d matched files remain; call again with cursor %q for the next page.\n", result.Files, result.NextCursor)
+	}
+
+	toolResult := mcp.NewToolResultText(text)
+	toolResult.IsError = result.Status == StatusNoFiles
+	if result.Structured != nil {
+		toolResult.StructuredContent = result.Structured
+	}
+	toolResult.Meta = map[string]any{
+		"schema_version": SchemaVersion,
+		"status":         result.Status,
+		"files":          result.Files,
+		"symbols":        result.Symbols,
+		"warnings":       result.Warnings,
+	}
+	if result.NextCursor != "" {
+		toolResult.Meta["next_cursor"] = result.NextCursor
+	}
+	return toolResult
+}
+
+// newLimitToolResult converts a limit error to a tool error whose metadata names the
+// exceeded limit, so clients can narrow the pattern instead of retrying it
+func newLimitToolResult(err *LimitError) *mcp.CallToolResult {
+	toolResult := mcp.NewToolResultError(err.Error())
+	toolResult.Meta = map[string]any{
+		"schema_version": SchemaVersion,
+		"status":         StatusLimitExceeded,
+		"limit":          err.Limit,
+		"max":            err.Max,
+		"actual":         err.Actual,
+	}
+	return toolResult
+}
+
+// extract runs the extraction mode requested by the caller
+func extract(ctx context.Context, pattern, mode string, opts ExtractOptions) (*ExtractionResult, error) {
+	switch mode {
+	case "", "symbols":
+		return ExtractSymbolsContext(ctx, pattern, opts)
+	case "strings":
+		return ExtractStringsContext(ctx, pattern, opts)
+	default:
+		return nil, fmt.Errorf("unknown mode: %s", mode)
+	}
+}
+
+// parseGroupBy converts a group_by value to whether output is grouped by package
+func parseGroupBy(groupBy string) (bool, error) {
+	switch groupBy {
+	case "", "file":
+		return false, nil
+	case "package":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown group_by value: %s", groupBy)
+	}
+}
+
+// parseOutputFormat validates an output format for an extraction mode; only
+// symbol outlines can be written in the structured formats
+func parseOutputFormat(format, mode string) (string, error) {
+	format, err := parseFormat(format)
+	if err != nil {
+		return "", err
+	}
+	if isStructuredFormat(format) && mode != "" && mode != "symbols" {
+		return "", fmt.Errorf("format %s is only supported in symbols mode", format)
+	}
+	return format, nil
+}
+
+// parseVisibility converts a visibility value to whether only public symbols are kept
+func parseVisibility(visibility string) (bool, error) {
+	switch visibility {
+	case "", "all":
+		return false, nil
+	case "public":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown visibility value: %s", visibility)
+	}
+}
+
+// compilePathRegex compiles an optional path filter, returning nil when it is empty
+func compilePathRegex(name, expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return re, nil
+}
+
+// parseModifiedRange converts the modification time options to a time range.
+// A modified-within duration and a modified-after date both set the lower bound,
+// and the later of the two wins.
+func parseModifiedRange(within, after, before string) (time.Time, time.Time, error) {
+	var from, to time.Time
+
+	if within != "" {
+		age, err := parseAge(within)
+		if err != nil {
+			return from, to, err
+		}
+		from = time.Now().Add(-age)
+	}
+
+	if after != "" {
+		t, err := parseDate(after)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid modified-after value: %w", err)
+		}
+		if t.After(from) {
+			from = t
+		}
+	}
+
+	if before != "" {
+		t, err := parseDate(before)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid modified-before value: %w", err)
+		}
+		to = t
+	}
+
+	return from, to, nil
+}
+
+// parseAge parses a duration that may also use d (days) and w (weeks) units
+func parseAge(value string) (time.Duration, error) {
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		n, err := strconv.ParseFloat(value[:len(value)-1], 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid modified-within value: %s", value)
+		}
+		return time.Duration(n * float64(unit)), nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid modified-within value: %s", value)
+	}
+	return age, nil
+}
+
+// parseDate parses a YYYY-MM-DD date in local time or an RFC 3339 timestamp
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
 }
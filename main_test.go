@@ -0,0 +1,393 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNewExtractionToolResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *ExtractionResult
+		isError bool
+		text    string
+	}{
+		{
+			name:    "ok",
+			result:  &ExtractionResult{Status: StatusOK, Files: 1, Symbols: 2, Output: "# Symbol Outline\n"},
+			isError: false,
+			text:    "# Symbol Outline\n",
+		},
+		{
+			name:    "no files",
+			result:  &ExtractionResult{Status: StatusNoFiles, Output: "No files found matching pattern: /x/*.go"},
+			isError: true,
+			text:    "No files found",
+		},
+		{
+			name: "warnings",
+			result: &ExtractionResult{
+				Status:   StatusNoSymbols,
+				Files:    1,
+				Warnings: []string{"skipped /x/a.ipynb: invalid notebook"},
+				Output:   "No symbols found",
+			},
+			isError: false,
+			text:    "## Warnings\n\n- skipped /x/a.ipynb: invalid notebook\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolResult := newExtractionToolResult(tt.result)
+
+			if toolResult.IsError != tt.isError {
+				t.Errorf("IsError = %v, want %v", toolResult.IsError, tt.isError)
+			}
+			if status := toolResult.Meta["status"]; status != tt.result.Status {
+				t.Errorf("Meta status = %v, want %s", status, tt.result.Status)
+			}
+
+			text, ok := toolResult.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("expected text content, got %T", toolResult.Content[0])
+			}
+			if !strings.Contains(text.Text, tt.text) {
+				t.Errorf("text = %q, want it to contain %q", text.Text, tt.text)
+			}
+		})
+	}
+}
+
+func TestToolHandlers_Limits(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("package lib\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handlers := &toolHandlers{limits: ExtractLimits{MaxFiles: 1}}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(tempDir, "*.go")}
+
+	result, err := handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || result.Meta["status"] != StatusLimitExceeded || result.Meta["limit"] != "max_files" || result.Meta["actual"] != int64(2) {
+		t.Errorf("expected a structured limit_exceeded error, got %+v", result)
+	}
+}
+
+func TestToolHandlers_Exclude(t *testing.T) {
+	tempDir := t.TempDir()
+	for name, code := range map[string]string{
+		"main.go":      "package main\n\nfunc Serve() {}\n",
+		"gen/types.go": "package gen\n\nfunc Generated() {}\n",
+	} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("GLYPH_TEST_GENERATED", filepath.Join(tempDir, "gen"))
+
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{
+		"pattern": filepath.Join(tempDir, "**", "*.go"),
+		"exclude": []any{"$GLYPH_TEST_GENERATED/**"},
+	}
+	result, err := handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || result.Meta["files"] != 1 || strings.Contains(text, "Generated") {
+		t.Errorf("expected the excluded directory to be left out, got %v: %q", result.Meta, text)
+	}
+
+	request.Params.Arguments = map[string]any{
+		"pattern": filepath.Join(tempDir, "**", "*.go"),
+		"exclude": []any{"$GLYPH_TEST_UNDEFINED/**"},
+	}
+	result, err = handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Errorf("expected an error for an undefined variable, got %v", result.Content)
+	}
+}
+
+func TestToolHandlers_Content(t *testing.T) {
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{
+		"content":  "def handler(event):\n    pass\n",
+		"language": "python",
+	}
+
+	result, err := handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || result.Meta["status"] != StatusOK || !strings.Contains(text, "handler") {
+		t.Errorf("expected an outline of the content, got %+v", result)
+	}
+
+	request.Params.Arguments = map[string]any{"language": "python"}
+	result, err = handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("expected an error without pattern or content")
+	}
+}
+
+func TestToolHandlers_StructuredContent(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "shapes.py"), []byte("class Shape:\n    def area(self):\n        return 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(tempDir, "*.py"), "depth": 1}
+
+	result, err := handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The Markdown text comes with the json format's document, which lists every
+	// selected symbol whatever the display options
+	doc, ok := result.StructuredContent.(*jsonOutline)
+	if !ok {
+		t.Fatalf("StructuredContent = %T, want the outline document", result.StructuredContent)
+	}
+	if doc.Status != StatusOK || len(doc.Outline) != 1 || doc.Outline[0].Path != filepath.Join(tempDir, "shapes.py") {
+		t.Fatalf("document = %+v, want the outline of shapes.py", doc)
+	}
+	if !strings.Contains(result.Content[0].(mcp.TextContent).Text, "# ") {
+		t.Errorf("Expected the Markdown outline as text, got %q", result.Content[0].(mcp.TextContent).Text)
+	}
+	shape := doc.Outline[0].Symbols[len(doc.Outline[0].Symbols)-1]
+	if shape.Name != "Shape" || len(shape.Children) != 1 || shape.Children[0].Name != "area" {
+		t.Errorf("Expected Shape with its method, got %+v", shape)
+	}
+
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(tempDir, "*.py"), "mode": "strings"}
+	result, err = handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc, ok := result.StructuredContent.(*jsonOutline); !ok || len(doc.Outline) != 0 {
+		t.Errorf("Expected an empty outline document in strings mode, got %+v", result.StructuredContent)
+	}
+
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(tempDir, "*.rs")}
+	result, err = handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || result.StructuredContent != nil {
+		t.Errorf("Expected an error without structured content when no files match, got %+v", result)
+	}
+}
+
+func TestToolHandlers_PatternList(t *testing.T) {
+	tempDir := t.TempDir()
+	for name, code := range map[string]string{
+		"cmd/main.go": "package main\n\nfunc Serve() {}\n",
+		"web/app.ts":  "export function render() {}\n",
+	} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"pattern": []any{
+		filepath.Join(tempDir, "cmd", "**", "*.go"),
+		filepath.Join(tempDir, "web", "**", "*.ts"),
+		filepath.Join(tempDir, "**", "*.go"),
+	}}
+	result, err := handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || result.Meta["files"] != 2 || !strings.Contains(text, "Serve") || !strings.Contains(text, "render") {
+		t.Errorf("expected both files once, got %v: %q", result.Meta, text)
+	}
+
+	for _, pattern := range []any{[]any{}, []any{filepath.Join(tempDir, "*.go"), 3}} {
+		request.Params.Arguments = map[string]any{"pattern": pattern}
+		result, err := handlers.extractSymbols(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.IsError {
+			t.Errorf("expected an error for pattern %v", pattern)
+		}
+	}
+}
+
+func TestToolHandlers_ExtractSymbolsFromContent(t *testing.T) {
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{
+		"content": "package svc\n\nfunc Handle() error { return nil }\n",
+		"path":    "/work/svc/handler.go",
+		"format":  "compact",
+	}
+
+	result, err := handlers.extractSymbolsFromContent(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || result.Meta["status"] != StatusOK || !strings.Contains(text, "handler.go:3 func Handle() error") {
+		t.Errorf("expected an outline of the content named by path, got %q", text)
+	}
+
+	request.Params.Arguments = map[string]any{"content": "SELECT 1", "language": "cobol"}
+	result, err = handlers.extractSymbolsFromContent(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("expected an error for an unsupported language")
+	}
+
+	request.Params.Arguments = map[string]any{"language": "python"}
+	result, err = handlers.extractSymbolsFromContent(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("expected an error without content")
+	}
+}
+
+func TestToolHandlers_MaxChars(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.py", "b.py", "c.py"} {
+		content := "class Service:\n"
+		for _, method := range []string{"start", "stop", "restart", "status", "reload", "drain"} {
+			content += "    def " + method + "(self):\n        pass\n\n"
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(tempDir, "*.py"), "max_tokens": 100}
+
+	result, err := handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "Collapsed") || !strings.Contains(text, "output truncated at 400 bytes") || !strings.Contains(text, "symbols omitted from") {
+		t.Errorf("expected files collapsed, then cut with a summary:\n%s", text)
+	}
+
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(tempDir, "*.py"), "max_chars": 100, "format": "json"}
+	result, err = handlers.extractSymbols(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("expected an error combining max_chars with a structured format")
+	}
+}
+
+func TestParsePatternArgs(t *testing.T) {
+	t.Setenv("GLYPH_SRC", "/src")
+
+	tests := []struct {
+		name      string
+		args      []string
+		pattern   string
+		negations []string
+		wantErr   bool
+	}{
+		{"one pattern", []string{"/repo/**/*.go"}, "/repo/**/*.go", nil, false},
+		{"several patterns", []string{"/repo/**/*.go", "$GLYPH_SRC/**/*.ts"}, "/repo/**/*.go,/src/**/*.ts", nil, false},
+		{"negations anywhere", []string{"/repo/**/*.ts", "!**/*.d.ts", "/web/**/*.ts"}, "/repo/**/*.ts,/web/**/*.ts", []string{"!**/*.d.ts"}, false},
+		{"only negations", []string{"!**/*.d.ts"}, "", []string{"!**/*.d.ts"}, false},
+		{"relative pattern", []string{"/repo/**/*.go", "web/*.ts"}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, negations, err := parsePatternArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePatternArgs error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if pattern != tt.pattern || strings.Join(negations, " ") != strings.Join(tt.negations, " ") {
+				t.Errorf("parsePatternArgs = %q, %v, want %q, %v", pattern, negations, tt.pattern, tt.negations)
+			}
+		})
+	}
+}
+
+func TestDiscoveryFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		negations []string
+		want      DiscoveryOptions
+		jobs      int
+		wantErr   bool
+	}{
+		{"defaults", nil, nil, DiscoveryOptions{}, 3, false},
+		{"every flag", []string{"-include-nested-modules", "-no-tests", "-hidden", "-include-generated", "-jobs=2"}, nil,
+			DiscoveryOptions{IncludeNestedModules: true, ExcludeTests: true, Hidden: true, IncludeGenerated: true}, 2, false},
+		{"excludes after negations", []string{"-exclude", "**/node_modules/**", "-exclude", "!**/*.d.ts"}, []string{"!**/gen/**"},
+			DiscoveryOptions{Exclude: []string{"!**/gen/**", "!**/node_modules/**", "!**/*.d.ts"}}, 3, false},
+		{"no jobs", []string{"-jobs=0"}, nil, DiscoveryOptions{}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			discovery := addDiscoveryFlags(flags)
+			flags.Set("jobs", "3")
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			opts, jobs, err := discovery.options(tt.negations)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("options error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if fmt.Sprintf("%+v", opts) != fmt.Sprintf("%+v", tt.want) || jobs != tt.jobs {
+				t.Errorf("options = %+v, %d; want %+v, %d", opts, jobs, tt.want, tt.jobs)
+			}
+		})
+	}
+}
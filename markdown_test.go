@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestMarkdownHeadingOutline(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/md_basic.md.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	expected := map[string]string{
+		"Title":       "# Title",
+		"Install":     "## Install",
+		"From source": "### From source",
+		"Usage":       "## Usage",
+		"Setext":      "Setext",
+	}
+
+	found := make(map[string]string)
+	for _, sym := range symbols {
+		if sym.Kind != "heading" {
+			t.Errorf("%s: Kind = %q, want heading", sym.Name, sym.Kind)
+		}
+		found[sym.Name] = sym.Signature
+	}
+	for name, signature := range expected {
+		if got, ok := found[name]; !ok {
+			t.Errorf("heading %s not extracted", name)
+		} else if got != signature {
+			t.Errorf("%s: Signature = %q, want %q", name, got, signature)
+		}
+	}
+	// Lines inside fenced code blocks are not headings
+	if len(symbols) != len(expected) {
+		t.Errorf("extracted %d headings, want %d", len(symbols), len(expected))
+	}
+
+	// Headings nest under the heading whose section contains them
+	roots := BuildHierarchy(symbols)
+	if len(roots) != 1 || roots[0].Symbol.Name != "Title" {
+		t.Fatalf("roots = %v, want Title", roots)
+	}
+	sections := roots[0].Children
+	if len(sections) != 2 || sections[0].Symbol.Name != "Install" || sections[1].Symbol.Name != "Usage" {
+		t.Fatalf("Title children = %v, want Install and Usage", sections)
+	}
+	if len(sections[0].Children) != 1 || sections[0].Children[0].Symbol.Name != "From source" {
+		t.Errorf("Install children = %v, want From source", sections[0].Children)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Name matching modes for symbol name filters
+const (
+	NameMatchRegex = "regex"
+	NameMatchExact = "exact"
+	NameMatchGlob  = "glob"
+	NameMatchFuzzy = "fuzzy"
+)
+
+// RankedSymbol is a symbol matched by a fuzzy name query, with its score
+type RankedSymbol struct {
+	Symbol Symbol
+	Score  int
+}
+
+// filterByName keeps symbols whose name matches a regular expression, exact name or
+// glob such as "New*" or "*Handler"
+func filterByName(symbols []Symbol, name, mode string) ([]Symbol, error) {
+	var match func(string) bool
+	switch mode {
+	case "", NameMatchRegex:
+		re, err := regexp.Compile(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern: %w", err)
+		}
+		match = re.MatchString
+	case NameMatchExact:
+		match = func(s string) bool { return s == name }
+	case NameMatchGlob:
+		if _, err := path.Match(name, ""); err != nil {
+			return nil, fmt.Errorf("invalid name glob: %w", err)
+		}
+		match = func(s string) bool {
+			matched, _ := path.Match(name, s)
+			return matched
+		}
+	default:
+		return nil, fmt.Errorf("unknown name match mode: %s", mode)
+	}
+
+	var matched []Symbol
+	for _, sym := range symbols {
+		if match(sym.Name) {
+			matched = append(matched, sym)
+		}
+	}
+	return matched, nil
+}
+
+// RankByFuzzyName keeps the symbols whose name contains the query as a
+// case-insensitive subsequence, best matches first
+func RankByFuzzyName(symbols []Symbol, query string) []RankedSymbol {
+	var ranked []RankedSymbol
+	for _, sym := range symbols {
+		if score, ok := fuzzyScore(sym.Name, query); ok {
+			ranked = append(ranked, RankedSymbol{Symbol: sym, Score: score})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return utf8.RuneCountInString(ranked[i].Symbol.Name) < utf8.RuneCountInString(ranked[j].Symbol.Name)
+	})
+	return ranked
+}
+
+// fuzzyScore scores how well a query matches a name as a subsequence. Matches at
+// the start of the name, at word boundaries ("usrSvc" hitting the S of UserService)
+// and runs of consecutive characters score higher; gaps and extra length score lower.
+func fuzzyScore(name, query string) (int, bool) {
+	nameRunes := []rune(name)
+	queryRunes := []rune(query)
+	if len(queryRunes) == 0 {
+		return 0, false
+	}
+
+	score := 0
+	prev := -1
+	for _, q := range queryRunes {
+		j := prev + 1
+		for j < len(nameRunes) && unicode.ToLower(nameRunes[j]) != unicode.ToLower(q) {
+			j++
+		}
+		if j == len(nameRunes) {
+			return 0, false
+		}
+
+		score++
+		switch {
+		case j == 0:
+			score += 8
+		case isWordBoundary(nameRunes, j):
+			score += 6
+		}
+		if j == prev+1 && prev >= 0 {
+			score += 4
+		}
+		if nameRunes[j] == q {
+			score++
+		}
+		score -= min(j-prev-1, 3)
+		prev = j
+	}
+
+	score -= (len(nameRunes) - len(queryRunes)) / 4
+	return score, true
+}
+
+// isWordBoundary reports whether the rune at i starts a word in a camelCase,
+// snake_case or dotted name
+func isWordBoundary(name []rune, i int) bool {
+	prev, cur := name[i-1], name[i]
+	switch {
+	case strings.ContainsRune("_-.$", prev):
+		return true
+	case unicode.IsUpper(cur) && !unicode.IsUpper(prev):
+		return true
+	case unicode.IsDigit(cur) && !unicode.IsDigit(prev):
+		return true
+	}
+	return false
+}
+
+// FormatRankedSymbols formats fuzzy matches as a ranked list, best match first
+func FormatRankedSymbols(ranked []RankedSymbol, query string) string {
+	if len(ranked) == 0 {
+		return "No symbols found"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Symbols matching %q\n\n", query))
+	for _, r := range ranked {
+		sb.WriteString(fmt.Sprintf("- %s: %s (%s:%d)%s\n",
+			r.Symbol.Kind, r.Symbol.Name, r.Symbol.FilePath, r.Symbol.StartLine, formatAnnotations(r.Symbol)))
+	}
+	return sb.String()
+}
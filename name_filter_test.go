@@ -0,0 +1,111 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		matches bool
+	}{
+		{"UserService", "usrSvc", true},
+		{"UserService", "us", true},
+		{"UserService", "svcusr", false},
+		{"get_user_by_id", "gubi", true},
+		{"Server", "", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.name, tt.query); ok != tt.matches {
+			t.Errorf("fuzzyScore(%q, %q) matched = %v, want %v", tt.name, tt.query, ok, tt.matches)
+		}
+	}
+}
+
+func TestRankByFuzzyName(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "useResourceVector", Kind: "func"},
+		{Name: "UserService", Kind: "class"},
+		{Name: "parseUserServiceConfig", Kind: "func"},
+		{Name: "Router", Kind: "class"},
+	}
+
+	ranked := RankByFuzzyName(symbols, "usrSvc")
+
+	var names []string
+	for _, r := range ranked {
+		names = append(names, r.Symbol.Name)
+	}
+	if len(names) != 3 || names[0] != "UserService" {
+		t.Errorf("ranked = %v, want UserService first and Router left out", names)
+	}
+}
+
+func TestExtractSymbols_NameFilter(t *testing.T) {
+	pattern, err := filepath.Abs("testdata/go_basic.go.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		opts        ExtractOptions
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "regex",
+			opts:        ExtractOptions{Detail: Minimal, Name: "^(Start|Stop)$"},
+			contains:    []string{"method: Start", "method: Stop"},
+			notContains: []string{"func: main"},
+		},
+		{
+			name:        "exact",
+			opts:        ExtractOptions{Detail: Minimal, Name: "Start", NameMatch: NameMatchExact},
+			contains:    []string{"method: Start"},
+			notContains: []string{"method: Stop"},
+		},
+		{
+			name:        "glob",
+			opts:        ExtractOptions{Detail: Minimal, Name: "St*", NameMatch: NameMatchGlob},
+			contains:    []string{"method: Start", "method: Stop"},
+			notContains: []string{"method: GetConfig", "func: NewServer"},
+		},
+		{
+			name:        "fuzzy",
+			opts:        ExtractOptions{Detail: Minimal, Name: "gtcfg", NameMatch: NameMatchFuzzy},
+			contains:    []string{`# Symbols matching "gtcfg"`, "method: GetConfig (" + pattern + ":91)"},
+			notContains: []string{"func: main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExtractSymbols(pattern, tt.opts)
+			if err != nil {
+				t.Fatalf("ExtractSymbols error = %v", err)
+			}
+			for _, expected := range tt.contains {
+				if !strings.Contains(result, expected) {
+					t.Errorf("expected %q in output:\n%s", expected, result)
+				}
+			}
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(result, unexpected) {
+					t.Errorf("did not expect %q in output:\n%s", unexpected, result)
+				}
+			}
+		})
+	}
+
+	if _, err := ExtractSymbols(pattern, ExtractOptions{Detail: Minimal, Name: "("}); err == nil {
+		t.Errorf("expected an error for an invalid name pattern")
+	}
+	if _, err := ExtractSymbols(pattern, ExtractOptions{Detail: Minimal, Name: "[", NameMatch: NameMatchGlob}); err == nil {
+		t.Errorf("expected an error for an invalid name glob")
+	}
+}
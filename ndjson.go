@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ndjsonSymbol is a line of the ndjson format: a symbol with the ID of the symbol
+// it is declared in
+type ndjsonSymbol struct {
+	Symbol
+	Parent string `json:"parent,omitempty"`
+	// Score ranks fuzzy name matches, best first
+	Score int `json:"score,omitempty"`
+}
+
+// formatNDJSON writes one JSON object per symbol and line, in the order they are
+// given, so scripts can stream an outline through jq or grep a line at a time. The
+// nesting of the json format is kept as the ID of each symbol's parent; scores are
+// keyed by symbol ID.
+func formatNDJSON(symbols []Symbol, scores map[string]int) (string, error) {
+	var files []string
+	fileSymbols := make(map[string][]Symbol)
+	for _, sym := range symbols {
+		if _, ok := fileSymbols[sym.FilePath]; !ok {
+			files = append(files, sym.FilePath)
+		}
+		fileSymbols[sym.FilePath] = append(fileSymbols[sym.FilePath], sym)
+	}
+	parents := make(map[string]string)
+	for _, file := range files {
+		collectParents(BuildHierarchy(fileSymbols[file]), "", parents)
+	}
+
+	var sb strings.Builder
+	for _, sym := range symbols {
+		line, err := json.Marshal(ndjsonSymbol{Symbol: sym, Parent: parents[sym.ID], Score: scores[sym.ID]})
+		if err != nil {
+			return "", err
+		}
+		sb.Write(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+// collectParents records the ID of the parent of every symbol in a hierarchy
+func collectParents(nodes []*SymbolNode, parent string, parents map[string]string) {
+	for _, node := range nodes {
+		if parent != "" {
+			parents[node.Symbol.ID] = parent
+		}
+		collectParents(node.Children, node.Symbol.ID, parents)
+	}
+}
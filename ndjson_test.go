@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestFormatNDJSON(t *testing.T) {
+	symbols := []Symbol{
+		{ID: "w1", Name: "Widget", Kind: "class", StartLine: 3, EndLine: 9, FilePath: "/proj/widget.ts", Public: true},
+		{ID: "r1", Name: "render", Kind: "method", StartLine: 4, EndLine: 6, Signature: "render(): string", FilePath: "/proj/widget.ts"},
+		{ID: "m1", Name: "main", Kind: "func", StartLine: 1, EndLine: 2, FilePath: "/proj/main.go"},
+	}
+
+	got, err := formatNDJSON(symbols, map[string]int{"m1": 7})
+	if err != nil {
+		t.Fatalf("formatNDJSON: %v", err)
+	}
+	want := `{"id":"w1","name":"Widget","kind":"class","start_line":3,"end_line":9,"start_column":0,"end_column":0,"file":"/proj/widget.ts","public":true}` + "\n" +
+		`{"id":"r1","name":"render","kind":"method","start_line":4,"end_line":6,"start_column":0,"end_column":0,"signature":"render(): string","file":"/proj/widget.ts","public":false,"parent":"w1"}` + "\n" +
+		`{"id":"m1","name":"main","kind":"func","start_line":1,"end_line":2,"start_column":0,"end_column":0,"file":"/proj/main.go","public":false,"score":7}` + "\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if got, err := formatNDJSON(nil, nil); err != nil || got != "" {
+		t.Errorf("formatNDJSON(nil) = %q, %v, want no lines", got, err)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// notebook is the subset of the Jupyter nbformat 4 document used for extraction
+type notebook struct {
+	Cells    []notebookCell   `json:"cells"`
+	Metadata notebookMetadata `json:"metadata"`
+}
+
+// notebookMetadata records the language of the notebook's kernel
+type notebookMetadata struct {
+	LanguageInfo struct {
+		Name string `json:"name"`
+	} `json:"language_info"`
+	Kernelspec struct {
+		Language string `json:"language"`
+	} `json:"kernelspec"`
+}
+
+// notebookCell is a single notebook cell
+type notebookCell struct {
+	CellType       string          `json:"cell_type"`
+	Source         json.RawMessage `json:"source"`
+	ExecutionCount *int            `json:"execution_count"`
+}
+
+// IsNotebookFile reports whether a file is a Jupyter notebook
+func IsNotebookFile(filePath string) bool {
+	return strings.ToLower(filepath.Ext(filePath)) == ".ipynb"
+}
+
+// ExtractFromNotebook extracts symbols from every code cell of a Jupyter notebook.
+// Cells are parsed in the language of the notebook's kernel, Python by default,
+// unless a cell magic such as %%bash or %%javascript selects another language.
+func (e *SymbolExtractor) ExtractFromNotebook(filePath string, detailLevel DetailLevel) ([]Symbol, error) {
+	content, err := ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return e.extractNotebookContent(filePath, content, detailLevel)
+}
+
+// extractNotebookContent extracts symbols from the code cells of notebook content
+// read from filePath
+func (e *SymbolExtractor) extractNotebookContent(filePath string, content []byte, detailLevel DetailLevel) ([]Symbol, error) {
+	e.content = content
+
+	var nb notebook
+	if err := json.Unmarshal(content, &nb); err != nil {
+		return nil, fmt.Errorf("invalid notebook %s: %w", filePath, err)
+	}
+
+	kernelLanguage, err := nb.languageQueries()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	var allSymbols []Symbol
+
+	for i, cell := range nb.Cells {
+		if cell.CellType != "code" {
+			continue
+		}
+
+		source, err := cellSource(cell.Source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source in cell %d of %s: %w", i+1, filePath, err)
+		}
+
+		langQueries, source := cellLanguage(source, kernelLanguage)
+		if langQueries == nil {
+			// Cells in languages without a grammar, such as %%latex, have no symbols
+			continue
+		}
+		if langQueries == pythonLanguageQueries {
+			source = commentOutMagics(source)
+		}
+
+		code := []byte(source)
+		tree, err := e.parse(code, langQueries)
+		if err != nil {
+			return nil, err
+		}
+
+		symbols, err := e.extractSymbolsFromTree(tree, code, filePath, langQueries, detailLevel)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range symbols {
+			symbols[j].Cell = &NotebookCell{
+				Index:          i + 1,
+				ExecutionCount: cell.ExecutionCount,
+			}
+		}
+		allSymbols = append(allSymbols, symbols...)
+	}
+
+	return allSymbols, nil
+}
+
+// languageQueries returns the queries for the language of the notebook's kernel,
+// defaulting to Python for notebooks that do not record one
+func (nb *notebook) languageQueries() (*LanguageQueries, error) {
+	language := nb.Metadata.LanguageInfo.Name
+	if language == "" {
+		language = nb.Metadata.Kernelspec.Language
+	}
+	if language == "" {
+		return pythonLanguageQueries, nil
+	}
+	langQueries := GetLanguageQueriesForName(language)
+	if langQueries == nil {
+		return nil, fmt.Errorf("unsupported notebook language: %s", language)
+	}
+	return langQueries, nil
+}
+
+// cellLanguage returns the language of a code cell and its source. A cell magic
+// on the first line, such as %%bash, selects the language of the cell and is blanked
+// so line numbers are kept; it returns nil queries for a language without a grammar.
+// Other cell magics, such as %%time, run the cell in the kernel language.
+func cellLanguage(source string, kernelLanguage *LanguageQueries) (*LanguageQueries, string) {
+	firstLine, rest, _ := strings.Cut(source, "\n")
+	fields := strings.Fields(firstLine)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "%%") {
+		return kernelLanguage, source
+	}
+
+	switch magic := strings.TrimPrefix(fields[0], "%%"); magic {
+	case "bash", "sh", "javascript", "js", "html", "markdown":
+		return GetLanguageQueriesForName(magic), "\n" + rest
+	case "python", "python3":
+		return pythonLanguageQueries, "\n" + rest
+	case "script":
+		if len(fields) < 2 {
+			return kernelLanguage, source
+		}
+		return cellLanguage("%%"+filepath.Base(fields[1])+"\n"+rest, kernelLanguage)
+	case "latex", "svg", "writefile", "perl", "ruby":
+		return nil, source
+	default:
+		return kernelLanguage, source
+	}
+}
+
+// cellSource decodes a cell source, which nbformat stores as a string or a list of lines
+func cellSource(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	var lines []string
+	if err := json.Unmarshal(raw, &lines); err == nil {
+		return strings.Join(lines, ""), nil
+	}
+
+	var source string
+	if err := json.Unmarshal(raw, &source); err != nil {
+		return "", err
+	}
+	return source, nil
+}
+
+// commentOutMagics turns IPython magics and shell escapes into comments so the
+// cell parses as Python, keeping line numbers intact
+func commentOutMagics(source string) string {
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "%") || strings.HasPrefix(trimmed, "!") {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
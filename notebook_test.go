@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSymbolExtractor_ExtractFromNotebook(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "analysis.ipynb")
+
+	notebookJSON := `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "metadata": {},
+   "source": ["# Analysis\n"]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 3,
+   "metadata": {},
+   "outputs": [],
+   "source": ["%matplotlib inline\n", "import pandas as pd\n", "\n", "THRESHOLD = 0.5\n"]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": null,
+   "metadata": {},
+   "outputs": [],
+   "source": "class Model:\n    def fit(self, data):\n        return data\n\ndef load(path):\n    return pd.read_csv(path)\n"
+  }
+ ],
+ "metadata": {},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`
+
+	if err := os.WriteFile(testFile, []byte(notebookJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	extractor := NewSymbolExtractor()
+	symbols, err := extractor.ExtractFromFile(testFile, Standard)
+	if err != nil {
+		t.Fatalf("ExtractFromFile error = %v", err)
+	}
+
+	expected := map[string]struct {
+		cell      int
+		line      uint32
+		execution int
+	}{
+		"THRESHOLD": {cell: 2, line: 4, execution: 3},
+		"Model":     {cell: 3, line: 1, execution: -1},
+		"fit":       {cell: 3, line: 2, execution: -1},
+		"load":      {cell: 3, line: 5, execution: -1},
+	}
+
+	found := make(map[string]bool)
+	for _, sym := range symbols {
+		want, ok := expected[sym.Name]
+		if !ok {
+			continue
+		}
+		found[sym.Name] = true
+
+		if sym.Cell == nil {
+			t.Errorf("Expected %s to have notebook cell metadata", sym.Name)
+			continue
+		}
+		if sym.Cell.Index != want.cell || sym.StartLine != want.line {
+			t.Errorf("Expected %s at cell %d line %d, got cell %d line %d",
+				sym.Name, want.cell, want.line, sym.Cell.Index, sym.StartLine)
+		}
+		if want.execution < 0 && sym.Cell.ExecutionCount != nil {
+			t.Errorf("Expected %s to have no execution count", sym.Name)
+		}
+		if want.execution >= 0 && (sym.Cell.ExecutionCount == nil || *sym.Cell.ExecutionCount != want.execution) {
+			t.Errorf("Expected %s to have execution count %d", sym.Name, want.execution)
+		}
+	}
+
+	for name := range expected {
+		if !found[name] {
+			t.Errorf("Expected symbol %s not found", name)
+		}
+	}
+
+	result := FormatSymbols(symbols, Minimal)
+	if !strings.Contains(result, "var: THRESHOLD (line 4) [cell 2, execution 3]") {
+		t.Errorf("Expected cell annotation in output.\nResult:\n%s", result)
+	}
+}
+
+func TestSymbolExtractor_ExtractFromNotebookLanguages(t *testing.T) {
+	dir := t.TempDir()
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	// A JavaScript kernel with a %%bash cell and a %%time cell in the kernel language
+	jsNotebook := filepath.Join(dir, "script.ipynb")
+	if err := os.WriteFile(jsNotebook, []byte(`{
+ "cells": [
+  {"cell_type": "code", "execution_count": 1, "metadata": {}, "outputs": [], "source": "function plot(data) {\n  return data;\n}\n"},
+  {"cell_type": "code", "execution_count": 2, "metadata": {}, "outputs": [], "source": "%%bash\n\nsetup() {\n  echo ok\n}\n"},
+  {"cell_type": "code", "execution_count": 3, "metadata": {}, "outputs": [], "source": "%%time\nclass Chart {}\n"},
+  {"cell_type": "code", "execution_count": 4, "metadata": {}, "outputs": [], "source": "%%latex\n\\\\frac{a}{b}\n"}
+ ],
+ "metadata": {"kernelspec": {"language": "javascript", "name": "deno"}, "language_info": {"name": "javascript"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	symbols, err := extractor.ExtractFromFile(jsNotebook, Standard)
+	if err != nil {
+		t.Fatalf("ExtractFromFile error = %v", err)
+	}
+
+	expected := map[string]struct {
+		cell int
+		line uint32
+	}{
+		"func plot":   {cell: 1, line: 1},
+		"func setup":  {cell: 2, line: 3},
+		"class Chart": {cell: 3, line: 2},
+	}
+	if len(symbols) != len(expected) {
+		t.Errorf("extracted %d symbols, want %d: %+v", len(symbols), len(expected), symbols)
+	}
+	for _, sym := range symbols {
+		want, ok := expected[sym.Kind+" "+sym.Name]
+		if !ok {
+			t.Errorf("unexpected symbol %s %s", sym.Kind, sym.Name)
+			continue
+		}
+		if sym.Cell == nil || sym.Cell.Index != want.cell || sym.StartLine != want.line {
+			t.Errorf("%s at cell %v line %d, want cell %d line %d", sym.Name, sym.Cell, sym.StartLine, want.cell, want.line)
+		}
+	}
+
+	// Kernels without a grammar are reported rather than parsed as Python
+	rNotebook := filepath.Join(dir, "stats.ipynb")
+	if err := os.WriteFile(rNotebook, []byte(`{"cells": [], "metadata": {"language_info": {"name": "R"}}, "nbformat": 4, "nbformat_minor": 5}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := extractor.ExtractFromFile(rNotebook, Standard); err == nil || !strings.Contains(err.Error(), "unsupported notebook language: R") {
+		t.Errorf("ExtractFromFile error = %v, want unsupported notebook language", err)
+	}
+}
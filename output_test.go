@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "outline.md")
+
+	if err := writeOutputFile(path, "first\n", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeOutputFile(path, "second\n", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeOutputFile(path, "third\n", true); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "second\nthird\n" {
+		t.Errorf("content = %q, want the replaced output with the appended one", content)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want the replaced file's 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no temporary files left, got %v", entries)
+	}
+
+	if err := writeOutputFile(filepath.Join(dir, "missing", "outline.md"), "x", false); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
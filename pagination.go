@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cursorPrefix versions the content of pagination cursors
+const cursorPrefix = "files:"
+
+// Page selects a page of the files matched by a pattern
+type Page struct {
+	// Cursor is the NextCursor of the previous page, or empty for the first page
+	Cursor string
+	// Size is the number of files per page; 0 disables pagination
+	Size int
+}
+
+// paginate returns the files of a page and the cursor of the page after it, which
+// is empty on the last page. Cursors are opaque to clients and hold the offset of
+// the next file, so they stay valid as long as the same files match the pattern.
+func paginate(files []string, page Page) ([]string, string, error) {
+	if page.Size < 0 {
+		return nil, "", fmt.Errorf("invalid page size %d", page.Size)
+	}
+	if page.Size == 0 {
+		if page.Cursor != "" {
+			return nil, "", fmt.Errorf("cursor requires a page size")
+		}
+		return files, "", nil
+	}
+
+	offset, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if offset > 0 && offset >= len(files) {
+		return nil, "", fmt.Errorf("cursor is past the last of %d files; the files matching the pattern have changed", len(files))
+	}
+
+	end := min(offset+page.Size, len(files))
+	next := ""
+	if end < len(files) {
+		next = encodeCursor(end)
+	}
+	return files[offset:end], next, nil
+}
+
+// encodeCursor returns the cursor of the page starting at a file offset
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the file offset of a cursor; the empty cursor starts at 0
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(data), cursorPrefix) {
+		return 0, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+	return offset, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	files := []string{"a.go", "b.go", "c.go", "d.go", "e.go"}
+
+	var pages [][]string
+	page := Page{Size: 2}
+	for {
+		got, next, err := paginate(files, page)
+		if err != nil {
+			t.Fatalf("paginate(%+v): %v", page, err)
+		}
+		pages = append(pages, got)
+		if next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+	want := [][]string{{"a.go", "b.go"}, {"c.go", "d.go"}, {"e.go"}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+
+	if got, next, err := paginate(files, Page{}); err != nil || len(got) != len(files) || next != "" {
+		t.Errorf("without a page size expected every file and no cursor, got %v, %q, %v", got, next, err)
+	}
+
+	for _, page := range []Page{
+		{Size: 2, Cursor: "not a cursor"},
+		{Size: 2, Cursor: encodeCursor(5)},
+		{Size: -1},
+		{Cursor: encodeCursor(2)},
+	} {
+		if _, _, err := paginate(files, page); err == nil {
+			t.Errorf("paginate(%+v): expected an error", page)
+		}
+	}
+}
+
+func TestExtractSymbols_Page(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		content := "package lib\n\nfunc " + strings.ToUpper(name[:1]) + "() {}\n"
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pattern := filepath.Join(tempDir, "*.go")
+
+	first, err := ExtractSymbolsResult(pattern, ExtractOptions{Detail: Standard, Page: Page{Size: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Files != 3 || first.NextCursor == "" || !strings.Contains(first.Output, "func A()") || strings.Contains(first.Output, "func C()") {
+		t.Errorf("first page = %+v", first)
+	}
+
+	last, err := ExtractSymbolsResult(pattern, ExtractOptions{Detail: Standard, Page: Page{Size: 2, Cursor: first.NextCursor}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.NextCursor != "" || !strings.Contains(last.Output, "func C()") || strings.Contains(last.Output, "func A()") {
+		t.Errorf("last page = %+v", last)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+func TestPHPSymbolExtraction(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/php_basic.php.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	symbolsByKind := make(map[string][]string)
+	for _, symbol := range symbols {
+		symbolsByKind[symbol.Kind] = append(symbolsByKind[symbol.Kind], symbol.Name)
+	}
+
+	expected := map[string][]string{
+		"namespace": {`App\Http`},
+		"class":     {"Controller", "UserController"},
+		"trait":     {"Loggable"},
+		"interface": {"Repository"},
+		"enum":      {"Suit"},
+		"method":    {"find", "log", "__construct", "helper", "handle", "label"},
+		"func":      {"helper"},
+		"const":     {"VERSION", "DEFAULT_LIMIT"},
+	}
+	for kind, names := range expected {
+		for _, name := range names {
+			if !contains(symbolsByKind[kind], name) {
+				t.Errorf("Expected %s symbol '%s' not found. Found: %v", kind, name, symbolsByKind[kind])
+			}
+		}
+	}
+}
+
+func TestPHPSignaturesAndVisibility(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/php_basic.php.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	tests := []struct {
+		kind      string
+		name      string
+		signature string
+		public    bool
+	}{
+		{"class", "Controller", "abstract class Controller implements Repository", true},
+		{"method", "find", "public function find(int $id): ?array", true},
+		{"method", "helper", "private static function helper(array $items = []): int", false},
+		{"method", "handle", "abstract protected function handle()", false},
+		{"method", "__construct", "public function __construct(private LoggerInterface $logger)", true},
+		{"func", "helper", `function helper(string $name = "x"): string`, true},
+		{"const", "VERSION", "const VERSION", true},
+		{"enum", "Suit", "enum Suit: string", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind+" "+tt.name, func(t *testing.T) {
+			for _, sym := range symbols {
+				if sym.Kind != tt.kind || sym.Name != tt.name || sym.Signature != tt.signature {
+					continue
+				}
+				if sym.Public != tt.public {
+					t.Errorf("Public = %v, want %v", sym.Public, tt.public)
+				}
+				return
+			}
+			t.Errorf("no %s %s with signature %q", tt.kind, tt.name, tt.signature)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// progressInterval is the least time between two progress notifications of a
+// tool call, so huge patterns don't flood the client
+const progressInterval = 250 * time.Millisecond
+
+// toolProgress returns an ExtractOptions.Progress callback that sends MCP progress
+// notifications for a tool call, or nil when the client did not ask for progress
+// by giving the call a progress token
+func toolProgress(ctx context.Context, request mcp.CallToolRequest) func(done, total int) {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil
+	}
+	return newProgressReporter(request.Params.Meta.ProgressToken, func(params map[string]any) {
+		// Progress is best effort: a notification the client can't take is dropped
+		_ = mcpServer.SendNotificationToClient(ctx, "notifications/progress", params)
+	})
+}
+
+// newProgressReporter returns a callback that sends the progress of an extraction
+// through send at most once per progressInterval. The first and last files are
+// always reported.
+func newProgressReporter(token mcp.ProgressToken, send func(params map[string]any)) func(done, total int) {
+	var last time.Time
+	return func(done, total int) {
+		if now := time.Now(); done == 0 || done == total || now.Sub(last) >= progressInterval {
+			last = now
+			send(map[string]any{
+				"progressToken": token,
+				"progress":      done,
+				"total":         total,
+				"message":       fmt.Sprintf("%d of %d files processed", done, total),
+			})
+		}
+	}
+}
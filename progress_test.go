@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewProgressReporter(t *testing.T) {
+	var sent []map[string]any
+	report := newProgressReporter("tok", func(params map[string]any) { sent = append(sent, params) })
+
+	for done := 0; done <= 100; done++ {
+		report(done, 100)
+	}
+
+	// Calls in quick succession collapse to the first and the last
+	if len(sent) != 2 {
+		t.Fatalf("sent %d notifications, want 2: %v", len(sent), sent)
+	}
+	last := sent[1]
+	if last["progressToken"] != "tok" || last["progress"] != 100 || last["total"] != 100 || last["message"] != "100 of 100 files processed" {
+		t.Errorf("last notification = %v", last)
+	}
+}
+
+func TestExtractSymbols_Progress(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("package lib\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var done []int
+	opts := ExtractOptions{Detail: Standard, Progress: func(n, total int) {
+		if total != 3 {
+			t.Errorf("total = %d, want 3", total)
+		}
+		done = append(done, n)
+	}}
+	if _, err := ExtractSymbolsResult(filepath.Join(tempDir, "*.go"), opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 4 || done[0] != 0 || done[3] != 3 {
+		t.Errorf("progress = %v, want 0 through 3", done)
+	}
+}
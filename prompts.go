@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// explorePrompt asks for a tour of a repository, starting from its top-level outline
+var explorePrompt = mcp.NewPrompt("explore_repository",
+	mcp.WithPromptDescription("Explore a repository: outline its top-level declarations, then drill into the most important packages"),
+	mcp.WithArgument("path", mcp.ArgumentDescription("Absolute path of the repository root"), mcp.RequiredArgument()),
+	mcp.WithArgument("focus", mcp.ArgumentDescription("Optional area or question to focus the exploration on, e.g. 'request handling'")),
+)
+
+// summarizePrompt asks for a summary of one module's public API
+var summarizePrompt = mcp.NewPrompt("summarize_module",
+	mcp.WithPromptDescription("Summarize a module or package: its public API, main types and how they fit together"),
+	mcp.WithArgument("path", mcp.ArgumentDescription("Absolute path of the module or package directory"), mcp.RequiredArgument()),
+)
+
+// exploreRepository composes the explore_repository prompt. A minimal outline of
+// top-level declarations, capped to fit a context window, comes first, so the
+// model can choose which directories deserve a standard-detail look.
+func exploreRepository(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	root, err := promptPath(request)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := promptCall(map[string]any{
+		"pattern":   filepath.Join(root, "**", "*"),
+		"detail":    "minimal",
+		"depth":     1,
+		"no_tests":  true,
+		"max_chars": 40000,
+	})
+	drillDown := promptCall(map[string]any{
+		"pattern":    filepath.Join(root, "<directory>", "**", "*"),
+		"detail":     "standard",
+		"visibility": "public",
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Explore the repository at %s and explain how it is organized.\n\n", root)
+	fmt.Fprintf(&sb, "1. Call extract_symbols with %s for an overview of its top-level declarations.\n", overview)
+	fmt.Fprintf(&sb, "2. Pick the few directories that look central and call extract_symbols with %s for each, to see their public API with signatures.\n", drillDown)
+	sb.WriteString("3. Describe the main components, what each is responsible for and how they depend on each other, citing files and symbols.\n")
+	if focus := strings.TrimSpace(request.Params.Arguments["focus"]); focus != "" {
+		fmt.Fprintf(&sb, "\nFocus on: %s. Use the name parameter of extract_symbols to find the relevant symbols.\n", focus)
+	}
+
+	return mcp.NewGetPromptResult("Explore "+root, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(sb.String())),
+	}), nil
+}
+
+// summarizeModule composes the summarize_module prompt, which outlines the public
+// symbols of a directory at standard detail
+func summarizeModule(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	dir, err := promptPath(request)
+	if err != nil {
+		return nil, err
+	}
+
+	outline := promptCall(map[string]any{
+		"pattern":    filepath.Join(dir, "**", "*"),
+		"detail":     "standard",
+		"visibility": "public",
+		"no_tests":   true,
+		"summarize":  60000,
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Summarize the module at %s.\n\n", dir)
+	fmt.Fprintf(&sb, "Call extract_symbols with %s to outline its public API. Then describe in a few paragraphs what the module is for, its main types and entry points and how they are meant to be used together, and list anything marked deprecated.\n", outline)
+
+	return mcp.NewGetPromptResult("Summarize "+dir, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(sb.String())),
+	}), nil
+}
+
+// promptPath returns the absolute path argument of a prompt, expanded as
+// extract_symbols expands patterns
+func promptPath(request mcp.GetPromptRequest) (string, error) {
+	path := strings.TrimSpace(request.Params.Arguments["path"])
+	if path == "" {
+		return "", fmt.Errorf("path argument is required")
+	}
+	path, err := resolvePattern(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(path), nil
+}
+
+// promptCall formats the arguments of a suggested extract_symbols call
+func promptCall(arguments map[string]any) string {
+	data, _ := json.Marshal(arguments)
+	return "`" + string(data) + "`"
+}
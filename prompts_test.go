@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPrompts(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   func(context.Context, mcp.GetPromptRequest) (*mcp.GetPromptResult, error)
+		arguments map[string]string
+		want      []string
+	}{
+		{
+			name:      "explore",
+			handler:   exploreRepository,
+			arguments: map[string]string{"path": "/work/app/", "focus": "request handling"},
+			want: []string{
+				"repository at /work/app",
+				`"detail":"minimal"`,
+				`"pattern":"/work/app/**/*"`,
+				`"max_chars":40000`,
+				`"visibility":"public"`,
+				"Focus on: request handling.",
+			},
+		},
+		{
+			name:      "summarize",
+			handler:   summarizeModule,
+			arguments: map[string]string{"path": "/work/app/internal/store"},
+			want: []string{
+				"module at /work/app/internal/store",
+				`"detail":"standard"`,
+				`"pattern":"/work/app/internal/store/**/*"`,
+				`"visibility":"public"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.GetPromptRequest
+			request.Params.Arguments = tt.arguments
+			result, err := tt.handler(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Messages[0].Content.(mcp.TextContent).Text
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("prompt does not contain %q:\n%s", want, text)
+				}
+			}
+
+			request.Params.Arguments = map[string]string{"path": "relative/dir"}
+			if _, err := tt.handler(context.Background(), request); err == nil {
+				t.Error("expected an error for a relative path")
+			}
+		})
+	}
+}
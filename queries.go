@@ -1,23 +1,130 @@
 package main
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 
 	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/bash"
+	"github.com/smacker/go-tree-sitter/csharp"
+	"github.com/smacker/go-tree-sitter/css"
+	"github.com/smacker/go-tree-sitter/elixir"
 	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/hcl"
+	"github.com/smacker/go-tree-sitter/html"
 	"github.com/smacker/go-tree-sitter/java"
 	"github.com/smacker/go-tree-sitter/javascript"
+	markdown "github.com/smacker/go-tree-sitter/markdown/tree-sitter-markdown"
+	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/rust"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
+	"github.com/smacker/go-tree-sitter/yaml"
 )
 
 // LanguageQueries holds the Tree-sitter queries for a specific language
 type LanguageQueries struct {
+	Name     string
 	Language *sitter.Language
 	Queries  map[string]string
+	// Strings matches the string literal nodes of the language, capturing them as @string
+	Strings string
 }
 
+var (
+	goLanguageQueries = &LanguageQueries{
+		Name:     "go",
+		Language: golang.GetLanguage(),
+		Queries:  goQueries,
+		Strings:  goStringQuery,
+	}
+	javaLanguageQueries = &LanguageQueries{
+		Name:     "java",
+		Language: java.GetLanguage(),
+		Queries:  javaQueries,
+		Strings:  javaStringQuery,
+	}
+	javascriptLanguageQueries = &LanguageQueries{
+		Name:     "javascript",
+		Language: javascript.GetLanguage(),
+		Queries:  javascriptQueries,
+		Strings:  javascriptStringQuery,
+	}
+	typescriptLanguageQueries = &LanguageQueries{
+		Name:     "typescript",
+		Language: typescript.GetLanguage(),
+		Queries:  typescriptQueries,
+		Strings:  javascriptStringQuery,
+	}
+	pythonLanguageQueries = &LanguageQueries{
+		Name:     "python",
+		Language: python.GetLanguage(),
+		Queries:  pythonQueries,
+		Strings:  pythonStringQuery,
+	}
+	csharpLanguageQueries = &LanguageQueries{
+		Name:     "csharp",
+		Language: csharp.GetLanguage(),
+		Queries:  csharpQueries,
+		Strings:  csharpStringQuery,
+	}
+	phpLanguageQueries = &LanguageQueries{
+		Name:     "php",
+		Language: php.GetLanguage(),
+		Queries:  phpQueries,
+		Strings:  phpStringQuery,
+	}
+	elixirLanguageQueries = &LanguageQueries{
+		Name:     "elixir",
+		Language: elixir.GetLanguage(),
+		Queries:  elixirQueries,
+		Strings:  elixirStringQuery,
+	}
+	bashLanguageQueries = &LanguageQueries{
+		Name:     "bash",
+		Language: bash.GetLanguage(),
+		Queries:  bashQueries,
+		Strings:  bashStringQuery,
+	}
+	hclLanguageQueries = &LanguageQueries{
+		Name:     "hcl",
+		Language: hcl.GetLanguage(),
+		Queries:  hclQueries,
+		Strings:  hclStringQuery,
+	}
+	// Markdown documents have no string literals
+	markdownLanguageQueries = &LanguageQueries{
+		Name:     "markdown",
+		Language: markdown.GetLanguage(),
+		Queries:  markdownQueries,
+	}
+	yamlLanguageQueries = &LanguageQueries{
+		Name:     "yaml",
+		Language: yaml.GetLanguage(),
+		Queries:  yamlQueries,
+		Strings:  yamlStringQuery,
+	}
+	cssLanguageQueries = &LanguageQueries{
+		Name:     "css",
+		Language: css.GetLanguage(),
+		Queries:  cssQueries,
+		Strings:  cssStringQuery,
+	}
+	htmlLanguageQueries = &LanguageQueries{
+		Name:     "html",
+		Language: html.GetLanguage(),
+		Queries:  htmlQueries,
+		Strings:  htmlStringQuery,
+	}
+	rustLanguageQueries = &LanguageQueries{
+		Name:     "rust",
+		Language: rust.GetLanguage(),
+		Queries:  rustQueries,
+		Strings:  rustStringQuery,
+	}
+)
+
 // GetLanguageQueries returns the appropriate queries for a given file path
 func GetLanguageQueriesForFile(filePath string) *LanguageQueries {
 	// For test files with .txt extension, check the filename pattern
@@ -30,63 +137,83 @@ func GetLanguageQueriesForFile(filePath string) *LanguageQueries {
 				lang := parts[0]
 				switch lang {
 				case "java":
-					return &LanguageQueries{
-						Language: java.GetLanguage(),
-						Queries:  javaQueries,
-					}
+					return javaLanguageQueries
 				case "go":
-					return &LanguageQueries{
-						Language: golang.GetLanguage(),
-						Queries:  goQueries,
-					}
+					return goLanguageQueries
 				case "js", "javascript":
-					return &LanguageQueries{
-						Language: javascript.GetLanguage(),
-						Queries:  javascriptQueries,
-					}
+					return javascriptLanguageQueries
 				case "ts", "typescript":
-					return &LanguageQueries{
-						Language: typescript.GetLanguage(),
-						Queries:  typescriptQueries,
-					}
+					return typescriptLanguageQueries
 				case "py", "python":
-					return &LanguageQueries{
-						Language: python.GetLanguage(),
-						Queries:  pythonQueries,
-					}
+					return pythonLanguageQueries
+				case "rs", "rust":
+					return rustLanguageQueries
+				case "cs", "csharp":
+					return csharpLanguageQueries
+				case "php":
+					return phpLanguageQueries
+				case "ex", "elixir":
+					return elixirLanguageQueries
+				case "sh", "bash":
+					return bashLanguageQueries
+				case "tf", "hcl":
+					return hclLanguageQueries
+				case "md", "mdx":
+					return markdownLanguageQueries
+				case "yaml", "yml":
+					return yamlLanguageQueries
+				case "css", "scss", "less":
+					return cssLanguageQueries
+				case "html", "htm":
+					return htmlLanguageQueries
 				}
 			}
 		}
 		// Also check for patterns like "something.java.txt"
 		if strings.Contains(filename, ".java.txt") {
-			return &LanguageQueries{
-				Language: java.GetLanguage(),
-				Queries:  javaQueries,
-			}
+			return javaLanguageQueries
 		}
 		if strings.Contains(filename, ".go.txt") {
-			return &LanguageQueries{
-				Language: golang.GetLanguage(),
-				Queries:  goQueries,
-			}
+			return goLanguageQueries
 		}
 		if strings.Contains(filename, ".js.txt") || strings.Contains(filename, ".jsx.txt") {
-			return &LanguageQueries{
-				Language: javascript.GetLanguage(),
-				Queries:  javascriptQueries,
-			}
+			return javascriptLanguageQueries
 		}
 		if strings.Contains(filename, ".ts.txt") || strings.Contains(filename, ".tsx.txt") {
-			return &LanguageQueries{
-				Language: typescript.GetLanguage(),
-				Queries:  typescriptQueries,
-			}
+			return typescriptLanguageQueries
 		}
 		if strings.Contains(filename, ".py.txt") {
-			return &LanguageQueries{
-				Language: python.GetLanguage(),
-				Queries:  pythonQueries,
-			}
+			return pythonLanguageQueries
+		}
+		if strings.Contains(filename, ".rs.txt") {
+			return rustLanguageQueries
+		}
+		if strings.Contains(filename, ".cs.txt") {
+			return csharpLanguageQueries
+		}
+		if strings.Contains(filename, ".php.txt") {
+			return phpLanguageQueries
+		}
+		if strings.Contains(filename, ".ex.txt") || strings.Contains(filename, ".exs.txt") {
+			return elixirLanguageQueries
+		}
+		if strings.Contains(filename, ".sh.txt") || strings.Contains(filename, ".bash.txt") {
+			return bashLanguageQueries
+		}
+		if strings.Contains(filename, ".tf.txt") || strings.Contains(filename, ".hcl.txt") {
+			return hclLanguageQueries
+		}
+		if strings.Contains(filename, ".md.txt") || strings.Contains(filename, ".mdx.txt") {
+			return markdownLanguageQueries
+		}
+		if strings.Contains(filename, ".yaml.txt") || strings.Contains(filename, ".yml.txt") {
+			return yamlLanguageQueries
+		}
+		if strings.Contains(filename, ".css.txt") || strings.Contains(filename, ".scss.txt") || strings.Contains(filename, ".less.txt") {
+			return cssLanguageQueries
+		}
+		if strings.Contains(filename, ".html.txt") || strings.Contains(filename, ".htm.txt") {
+			return htmlLanguageQueries
 		}
 	}
 
@@ -94,30 +221,112 @@ func GetLanguageQueriesForFile(filePath string) *LanguageQueries {
 
 	switch ext {
 	case ".go":
-		return &LanguageQueries{
-			Language: golang.GetLanguage(),
-			Queries:  goQueries,
-		}
+		return goLanguageQueries
 	case ".java":
-		return &LanguageQueries{
-			Language: java.GetLanguage(),
-			Queries:  javaQueries,
-		}
+		return javaLanguageQueries
 	case ".js", ".jsx":
-		return &LanguageQueries{
-			Language: javascript.GetLanguage(),
-			Queries:  javascriptQueries,
-		}
+		return javascriptLanguageQueries
 	case ".py":
-		return &LanguageQueries{
-			Language: python.GetLanguage(),
-			Queries:  pythonQueries,
-		}
+		return pythonLanguageQueries
+	case ".rs":
+		return rustLanguageQueries
+	case ".cs":
+		return csharpLanguageQueries
+	case ".php":
+		return phpLanguageQueries
+	case ".ex", ".exs":
+		return elixirLanguageQueries
+	case ".sh", ".bash":
+		return bashLanguageQueries
+	case ".tf", ".hcl":
+		return hclLanguageQueries
+	case ".md", ".mdx":
+		return markdownLanguageQueries
+	case ".yaml", ".yml":
+		return yamlLanguageQueries
+	case ".css", ".scss", ".less":
+		return cssLanguageQueries
+	case ".html", ".htm":
+		return htmlLanguageQueries
 	case ".ts", ".tsx":
-		return &LanguageQueries{
-			Language: typescript.GetLanguage(),
-			Queries:  typescriptQueries,
+		return typescriptLanguageQueries
+	case "":
+		return shebangLanguageQueries(filePath)
+	default:
+		return nil
+	}
+}
+
+// shebangLanguageQueries returns the queries for an extensionless script whose
+// "#!" line runs bash or sh, such as "#!/usr/bin/env bash"
+func shebangLanguageQueries(filePath string) *LanguageQueries {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	head := make([]byte, 128)
+	n, _ := f.Read(head)
+	line, _, _ := strings.Cut(string(head[:n]), "\n")
+	if !strings.HasPrefix(line, "#!") {
+		return nil
+	}
+
+	fields := strings.Fields(line[2:])
+	if len(fields) > 0 && filepath.Base(fields[0]) == "env" {
+		// Skip env and its options, as in "#!/usr/bin/env -S bash -e"
+		fields = fields[1:]
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+			fields = fields[1:]
 		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	switch filepath.Base(fields[0]) {
+	case "bash", "sh":
+		return bashLanguageQueries
+	default:
+		return nil
+	}
+}
+
+// GetLanguageQueriesForName returns the queries for a language name such as
+// "typescript", or a common alias or extension such as "ts"
+func GetLanguageQueriesForName(name string) *LanguageQueries {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "go", "golang":
+		return goLanguageQueries
+	case "java":
+		return javaLanguageQueries
+	case "javascript", "js", "jsx", "mjs", "cjs":
+		return javascriptLanguageQueries
+	case "typescript", "ts", "tsx":
+		return typescriptLanguageQueries
+	case "python", "py":
+		return pythonLanguageQueries
+	case "rust", "rs":
+		return rustLanguageQueries
+	case "csharp", "c#", "cs":
+		return csharpLanguageQueries
+	case "php":
+		return phpLanguageQueries
+	case "elixir", "ex", "exs":
+		return elixirLanguageQueries
+	case "bash", "sh", "shell":
+		return bashLanguageQueries
+	case "hcl", "terraform", "tf":
+		return hclLanguageQueries
+	case "markdown", "md", "mdx":
+		return markdownLanguageQueries
+	case "yaml", "yml":
+		return yamlLanguageQueries
+	case "css", "scss", "less":
+		return cssLanguageQueries
+	case "html", "htm":
+		return htmlLanguageQueries
 	default:
 		return nil
 	}
@@ -128,30 +337,35 @@ func GetLanguageQueries(lang *sitter.Language) *LanguageQueries {
 	// This is a fallback method - prefer GetLanguageQueriesForFile when possible
 	switch lang {
 	case golang.GetLanguage():
-		return &LanguageQueries{
-			Language: lang,
-			Queries:  goQueries,
-		}
+		return goLanguageQueries
 	case java.GetLanguage():
-		return &LanguageQueries{
-			Language: lang,
-			Queries:  javaQueries,
-		}
+		return javaLanguageQueries
 	case javascript.GetLanguage():
-		return &LanguageQueries{
-			Language: lang,
-			Queries:  javascriptQueries,
-		}
+		return javascriptLanguageQueries
 	case python.GetLanguage():
-		return &LanguageQueries{
-			Language: lang,
-			Queries:  pythonQueries,
-		}
+		return pythonLanguageQueries
 	case typescript.GetLanguage():
-		return &LanguageQueries{
-			Language: lang,
-			Queries:  typescriptQueries,
-		}
+		return typescriptLanguageQueries
+	case rust.GetLanguage():
+		return rustLanguageQueries
+	case csharp.GetLanguage():
+		return csharpLanguageQueries
+	case php.GetLanguage():
+		return phpLanguageQueries
+	case elixir.GetLanguage():
+		return elixirLanguageQueries
+	case bash.GetLanguage():
+		return bashLanguageQueries
+	case hcl.GetLanguage():
+		return hclLanguageQueries
+	case markdown.GetLanguage():
+		return markdownLanguageQueries
+	case yaml.GetLanguage():
+		return yamlLanguageQueries
+	case css.GetLanguage():
+		return cssLanguageQueries
+	case html.GetLanguage():
+		return htmlLanguageQueries
 	default:
 		return nil
 	}
@@ -159,6 +373,11 @@ func GetLanguageQueries(lang *sitter.Language) *LanguageQueries {
 
 // Go language queries
 var goQueries = map[string]string{
+	"packages": `
+		(package_clause
+			(package_identifier) @name
+		) @package
+	`,
 	"functions": `
 		(function_declaration
 			name: (identifier) @name
@@ -272,6 +491,11 @@ var javaQueries = map[string]string{
 			name: (identifier) @name
 		) @annotation
 	`,
+	"packages": `
+		(package_declaration
+			[(identifier) (scoped_identifier)] @name
+		) @package
+	`,
 }
 
 // JavaScript language queries
@@ -295,7 +519,7 @@ var javascriptQueries = map[string]string{
 	"function_expressions": `
 		(variable_declarator
 			name: (identifier) @name
-			value: (function) @func_expr
+			value: (function_expression) @func_expr
 		) @function
 	`,
 	"classes": `
@@ -400,8 +624,595 @@ var typescriptQueries = map[string]string{
 		) @function
 	`,
 	"namespaces": `
-		(module_declaration
+		(internal_module
+			name: (_) @name
+		) @namespace
+	`,
+	"modules": `
+		(module
+			name: [(identifier) (nested_identifier)] @name
+		) @module
+		(module
+			name: (string (string_fragment) @name)
+		) @module
+	`,
+}
+
+// Rust language queries
+var rustQueries = map[string]string{
+	"functions": `
+		[
+			(source_file
+				(function_item
+					name: (identifier) @name
+					parameters: (parameters) @params
+					return_type: (_)? @return_type
+				) @function
+			)
+			(mod_item
+				body: (declaration_list
+					(function_item
+						name: (identifier) @name
+						parameters: (parameters) @params
+						return_type: (_)? @return_type
+					) @function
+				)
+			)
+		]
+	`,
+	"methods": `
+		(impl_item
+			type: (_) @receiver
+			body: (declaration_list
+				(function_item
+					name: (identifier) @name
+					parameters: (parameters) @params
+					return_type: (_)? @return_type
+				) @method
+			)
+		)
+	`,
+	"trait_methods": `
+		(trait_item
+			name: (type_identifier) @receiver
+			body: (declaration_list
+				[
+					(function_item name: (identifier) @name)
+					(function_signature_item name: (identifier) @name)
+				] @method
+			)
+		)
+	`,
+	"structs": `
+		(struct_item
+			name: (type_identifier) @name
+		) @struct
+	`,
+	"unions": `
+		(union_item
+			name: (type_identifier) @name
+		) @struct
+	`,
+	"enums": `
+		(enum_item
+			name: (type_identifier) @name
+		) @enum
+	`,
+	"traits": `
+		(trait_item
+			name: (type_identifier) @name
+		) @interface
+	`,
+	"impls": `
+		(impl_item
+			type: (_) @name
+		) @symbol
+	`,
+	"type_aliases": `
+		(type_item
+			name: (type_identifier) @name
+		) @type
+	`,
+	"constants": `
+		(const_item
+			name: (identifier) @name
+		) @const
+	`,
+	"statics": `
+		(static_item
+			name: (identifier) @name
+		) @var
+	`,
+	"macros": `
+		(macro_definition
+			name: (identifier) @name
+		) @symbol
+	`,
+	"modules": `
+		(mod_item
+			name: (identifier) @name
+		) @module
+	`,
+}
+
+// C# language queries
+var csharpQueries = map[string]string{
+	"namespaces": `
+		[
+			(namespace_declaration
+				name: (_) @name
+			) @namespace
+			(file_scoped_namespace_declaration
+				name: (_) @name
+			) @namespace
+		]
+	`,
+	"classes": `
+		(class_declaration
 			name: (identifier) @name
+		) @class
+	`,
+	"records": `
+		(record_declaration
+			name: (identifier) @name
+		) @record
+	`,
+	"structs": `
+		(struct_declaration
+			name: (identifier) @name
+		) @struct
+	`,
+	"interfaces": `
+		(interface_declaration
+			name: (identifier) @name
+		) @interface
+	`,
+	"enums": `
+		(enum_declaration
+			name: (identifier) @name
+		) @enum
+	`,
+	"delegates": `
+		(delegate_declaration
+			name: (identifier) @name
+		) @type
+	`,
+	"methods": `
+		(method_declaration
+			name: (identifier) @name
+			parameters: (parameter_list) @params
+		) @method
+	`,
+	"constructors": `
+		(constructor_declaration
+			name: (identifier) @name
+			parameters: (parameter_list) @params
+		) @constructor
+	`,
+	"properties": `
+		(property_declaration
+			name: (identifier) @name
+		) @field
+	`,
+	"events": `
+		[
+			(event_declaration
+				name: (identifier) @name
+			) @field
+			(event_field_declaration
+				(variable_declaration
+					(variable_declarator
+						name: (identifier) @name
+					)
+				)
+			) @field
+		]
+	`,
+	"fields": `
+		(field_declaration
+			(variable_declaration
+				(variable_declarator
+					name: (identifier) @name
+				)
+			)
+		) @field
+	`,
+}
+
+// PHP language queries
+var phpQueries = map[string]string{
+	"namespaces": `
+		(namespace_definition
+			name: (namespace_name) @name
 		) @namespace
 	`,
+	"classes": `
+		(class_declaration
+			name: (name) @name
+		) @class
+	`,
+	"traits": `
+		(trait_declaration
+			name: (name) @name
+		) @interface
+	`,
+	"interfaces": `
+		(interface_declaration
+			name: (name) @name
+		) @interface
+	`,
+	"enums": `
+		(enum_declaration
+			name: (name) @name
+		) @enum
+	`,
+	"methods": `
+		(method_declaration
+			name: (name) @name
+			parameters: (formal_parameters) @params
+			return_type: (_)? @return_type
+		) @method
+	`,
+	"functions": `
+		(function_definition
+			name: (name) @name
+			parameters: (formal_parameters) @params
+			return_type: (_)? @return_type
+		) @function
+	`,
+	"constants": `
+		(const_declaration
+			(const_element
+				(name) @name
+			)
+		) @const
+	`,
 }
+
+// Elixir language queries
+var elixirQueries = map[string]string{
+	"modules": `
+		(call
+			target: (identifier) @keyword
+			(arguments (alias) @name)
+			(#eq? @keyword "defmodule")
+		) @module
+	`,
+	"functions": `
+		(call
+			target: (identifier) @keyword
+			(arguments
+				[
+					(call target: (identifier) @name)
+					(identifier) @name
+					(binary_operator
+						left: (call target: (identifier) @name)
+						operator: "when")
+				]
+			)
+			(#match? @keyword "^defp?$")
+		) @function
+	`,
+	"macros": `
+		(call
+			target: (identifier) @keyword
+			(arguments
+				[
+					(call target: (identifier) @name)
+					(identifier) @name
+					(binary_operator
+						left: (call target: (identifier) @name)
+						operator: "when")
+				]
+			)
+			(#match? @keyword "^defmacrop?$")
+		) @function
+	`,
+	"structs": `
+		(call
+			target: (identifier) @module_keyword
+			(arguments (alias) @name)
+			(do_block
+				(call
+					target: (identifier) @keyword
+					(#eq? @keyword "defstruct")
+				) @struct
+			)
+			(#eq? @module_keyword "defmodule")
+		)
+	`,
+	"callbacks": `
+		(unary_operator
+			operand: (call
+				target: (identifier) @attribute
+				(arguments
+					[
+						(binary_operator left: (call target: (identifier) @name))
+						(binary_operator left: (identifier) @name)
+					]
+				)
+			)
+			(#match? @attribute "^(callback|macrocallback)$")
+		) @function
+	`,
+}
+
+// Bash language queries
+var bashQueries = map[string]string{
+	"functions": `
+		(function_definition
+			name: (word) @name
+		) @function
+	`,
+	"exports": `
+		[
+			(declaration_command
+				"export"
+				(variable_assignment
+					name: (variable_name) @name
+				) @var
+			)
+			(declaration_command
+				"export"
+				(variable_name) @name @var
+			)
+		]
+	`,
+	"declared_exports": `
+		(declaration_command
+			"declare"
+			(word) @flag
+			(variable_assignment
+				name: (variable_name) @name
+			) @var
+			(#match? @flag "^-[a-zA-Z]*x")
+		)
+	`,
+}
+
+// Terraform/HCL language queries
+var hclQueries = map[string]string{
+	"resources": `
+		(block
+			(identifier) @keyword
+			.
+			(string_lit) @name
+			(#eq? @keyword "resource")
+		) @symbol
+	`,
+	"data_sources": `
+		(block
+			(identifier) @keyword
+			.
+			(string_lit) @name
+			(#eq? @keyword "data")
+		) @symbol
+	`,
+	"modules": `
+		(block
+			(identifier) @keyword
+			.
+			(string_lit) @name
+			(#eq? @keyword "module")
+		) @symbol
+	`,
+	"variables": `
+		(block
+			(identifier) @keyword
+			.
+			(string_lit) @name
+			(#eq? @keyword "variable")
+		) @symbol
+	`,
+	"outputs": `
+		(block
+			(identifier) @keyword
+			.
+			(string_lit) @name
+			(#eq? @keyword "output")
+		) @symbol
+	`,
+	"providers": `
+		(block
+			(identifier) @keyword
+			.
+			(string_lit) @name
+			(#eq? @keyword "provider")
+		) @symbol
+	`,
+}
+
+// Markdown queries. An ATX heading starts a section running to the next heading of
+// the same or a higher level, so headings nest by their sections; setext headings
+// do not start sections and cover only their own lines.
+var markdownQueries = map[string]string{
+	"headings": `
+		(section
+			(atx_heading
+				heading_content: (inline) @name
+			)
+		) @symbol
+	`,
+	"setext_headings": `
+		(setext_heading
+			heading_content: (paragraph (inline) @name)
+		) @symbol
+	`,
+}
+
+// YAML queries. Kubernetes manifests are named by their kind and metadata.name, and
+// the top-level keys of other documents are listed instead.
+var yamlQueries = map[string]string{
+	"manifests": `
+		(document
+			(block_node
+				(block_mapping
+					(block_mapping_pair
+						key: (flow_node) @key
+						value: (flow_node) @name
+					)
+				)
+			)
+			(#eq? @key "kind")
+		) @symbol
+	`,
+	"keys": `
+		(document
+			(block_node
+				(block_mapping
+					(block_mapping_pair
+						key: (flow_node) @name
+					) @field
+				)
+			)
+		)
+	`,
+}
+
+// CSS queries. SCSS and Less files are parsed with the CSS grammar, which reads
+// nested rules, @mixin and @function definitions as generic at-rules.
+var cssQueries = map[string]string{
+	"rule_sets": `
+		(rule_set
+			(selectors) @name
+		) @symbol
+	`,
+	"mixins": `
+		(at_rule
+			(at_keyword) @keyword
+			.
+			(keyword_query) @name
+			(#eq? @keyword "@mixin")
+		) @function
+	`,
+	"functions": `
+		(at_rule
+			(at_keyword) @keyword
+			.
+			(keyword_query) @name
+			(#eq? @keyword "@function")
+		) @function
+	`,
+	"custom_properties": `
+		(declaration
+			(property_name) @name
+			(#match? @name "^--")
+		) @field
+	`,
+	"media": `
+		(media_statement
+			.
+			(_) @name
+		) @symbol
+	`,
+}
+
+// HTML queries. Elements with an id, <template> definitions and custom elements
+// are listed; functions and classes of inline scripts are extracted separately.
+var htmlQueries = map[string]string{
+	"elements": `
+		(element
+			(start_tag
+				(tag_name) @tag
+				(attribute
+					(attribute_name) @attr
+					(quoted_attribute_value (attribute_value) @name)
+				)
+			)
+			(#eq? @attr "id")
+			(#not-eq? @tag "template")
+			(#not-match? @tag "-")
+		) @symbol
+	`,
+	"templates": `
+		(element
+			(start_tag
+				(tag_name) @tag
+				(attribute
+					(attribute_name) @attr
+					(quoted_attribute_value (attribute_value) @name)
+				)
+			)
+			(#eq? @tag "template")
+			(#eq? @attr "id")
+		) @symbol
+	`,
+	"custom_elements": `
+		(element
+			(start_tag
+				(tag_name) @name
+			)
+			(#match? @name "-")
+		) @symbol
+	`,
+}
+
+// String literal queries, used by the strings extraction mode
+var (
+	goStringQuery = `
+		[
+			(interpreted_string_literal)
+			(raw_string_literal)
+		] @string
+	`
+	javaStringQuery = `
+		(string_literal) @string
+	`
+	javascriptStringQuery = `
+		[
+			(string)
+			(template_string)
+			(regex)
+		] @string
+	`
+	pythonStringQuery = `
+		(string) @string
+	`
+	csharpStringQuery = `
+		[
+			(string_literal)
+			(verbatim_string_literal)
+			(interpolated_string_expression)
+		] @string
+	`
+	phpStringQuery = `
+		[
+			(string)
+			(encapsed_string)
+			(heredoc)
+			(nowdoc)
+		] @string
+	`
+	elixirStringQuery = `
+		[
+			(string)
+			(charlist)
+			(sigil)
+		] @string
+	`
+	bashStringQuery = `
+		[
+			(string)
+			(raw_string)
+			(heredoc_body)
+		] @string
+	`
+	htmlStringQuery = `
+		(attribute_value) @string
+	`
+	cssStringQuery = `
+		(string_value) @string
+	`
+	yamlStringQuery = `
+		[(double_quote_scalar) (single_quote_scalar)] @string
+	`
+	hclStringQuery = `
+		(string_lit) @string
+	`
+	rustStringQuery = `
+		[
+			(string_literal)
+			(raw_string_literal)
+		] @string
+	`
+)
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatQuickfix writes one "path:line:col: kind name: signature" line per symbol,
+// in the order given, which Vim's :cfile, Emacs' compilation mode and most
+// editors' problem matchers read as a list of locations to jump between. Paths
+// are left absolute, so the list can be read from any directory.
+func formatQuickfix(symbols []Symbol) string {
+	var sb strings.Builder
+	for _, sym := range symbols {
+		column := max(sym.StartColumn, 1)
+		fmt.Fprintf(&sb, "%s:%d:%d: ", sym.FilePath, sym.StartLine, column)
+		if sym.Cell != nil {
+			fmt.Fprintf(&sb, "[cell %d] ", sym.Cell.Index)
+		}
+		name := sym.Name
+		if sym.Receiver != "" {
+			name = sym.Receiver + "." + sym.Name
+		}
+		fmt.Fprintf(&sb, "%s %s", sym.Kind, name)
+		// Multi-line signatures are joined, as each location must be one line
+		if signature := strings.Join(strings.Fields(sym.Signature), " "); signature != "" {
+			fmt.Fprintf(&sb, ": %s", signature)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestFormatQuickfix(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Start", Kind: "method", Receiver: "Server", Signature: "func (s *Server) Start() error", StartLine: 7, StartColumn: 1, FilePath: "/proj/server.go"},
+		{Name: "render", Kind: "method", Signature: "render(\n    props: Props,\n): void", StartLine: 4, StartColumn: 3, FilePath: "/proj/src/widget.ts"},
+		{Name: "fit", Kind: "func", Signature: "def fit(model)", StartLine: 1, FilePath: "/proj/a.ipynb", Cell: &NotebookCell{Index: 2}},
+		{Name: "Intro", Kind: "heading", StartLine: 3, StartColumn: 1, FilePath: "/proj/README.md"},
+	}
+
+	want := "/proj/server.go:7:1: method Server.Start: func (s *Server) Start() error\n" +
+		"/proj/src/widget.ts:4:3: method render: render( props: Props, ): void\n" +
+		"/proj/a.ipynb:1:1: [cell 2] func fit: def fit(model)\n" +
+		"/proj/README.md:3:1: heading Intro\n"
+	if got := formatQuickfix(symbols); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
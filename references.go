@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// referenceGroup is the references found inside one enclosing symbol, or at the
+// top level of a file when symbol is nil
+type referenceGroup struct {
+	symbol *Symbol
+	refs   []Reference
+}
+
+// FindReferencesContext finds the identifier usages of a symbol name in the files
+// matching a pattern, grouped by file and by the innermost symbol enclosing each
+// usage. Declarations of the name itself and mentions in comments are not
+// usages. Notebooks are not searched, since their lines are relative to a cell.
+func FindReferencesContext(ctx context.Context, name, pattern string, opts ExtractOptions) (*ExtractionResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name must not be empty")
+	}
+
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files: %w", err)
+	}
+	if len(files) == 0 {
+		return noFilesResult(pattern), nil
+	}
+	if err := opts.Limits.check(len(files), 0); err != nil {
+		return nil, err
+	}
+
+	result := &ExtractionResult{Status: StatusOK, Files: len(files), log: opts.Log}
+	// The enclosing symbols are those of the built-in queries
+	opts.Query = ""
+	outcomes, stop := extractConcurrently(ctx, files, opts, func(extractor *SymbolExtractor, file string) fileOutcome {
+		return referencesOutcome(extractor, opts, file, name)
+	})
+	defer stop()
+
+	var searched []string
+	fileGroups := make(map[string][]referenceGroup)
+	var parsedBytes int64
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(i, len(files))
+		}
+
+		var outcome fileOutcome
+		select {
+		case outcome = <-outcomes[i]:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if opts.Log != nil {
+			for _, event := range outcome.events {
+				opts.Log(event)
+			}
+		}
+
+		parsedBytes += int64(len(outcome.content))
+		if err := opts.Limits.check(len(files), parsedBytes); err != nil {
+			return nil, err
+		}
+		if outcome.err != nil {
+			result.warnSkipped(file, outcome.err)
+			continue
+		}
+
+		groups := groupReferences(outcome.refs, outcome.symbols, name)
+		if len(groups) == 0 {
+			continue
+		}
+		searched = append(searched, file)
+		fileGroups[file] = groups
+		for _, group := range groups {
+			result.Symbols += len(group.refs)
+		}
+	}
+
+	if opts.Progress != nil {
+		opts.Progress(len(files), len(files))
+	}
+
+	if result.Symbols == 0 {
+		result.Status = StatusNoSymbols
+		result.Output = fmt.Sprintf("No references to %s found\n", name)
+		return result, nil
+	}
+	result.Output = formatReferences(name, searched, fileGroups, result.Symbols)
+	return result, nil
+}
+
+// referencesOutcome reads a file once and, when it mentions name, parses it once to
+// find both the references to name and the symbols enclosing them
+func referencesOutcome(extractor *SymbolExtractor, opts ExtractOptions, file, name string) fileOutcome {
+	var outcome fileOutcome
+	langQueries := GetLanguageQueriesForFile(file)
+	if langQueries == nil {
+		return outcome
+	}
+	if opts.Log != nil {
+		opts.Log = func(event LogEvent) { outcome.events = append(outcome.events, event) }
+	}
+
+	readAt := time.Now()
+	content, info, err := readStableFile(file, os.ReadFile)
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+	outcome.content = content
+	// Files that never mention the name are not parsed
+	if !bytes.Contains(content, []byte(name)) {
+		return outcome
+	}
+
+	tree, err := extractor.parse(content, langQueries)
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+	outcome.refs = referencesInTree(tree, content, file, name)
+	outcome.symbols, outcome.err = extractCached(extractor, opts, file, content, stampOf(info, readAt), opts.Detail, func() ([]Symbol, error) {
+		return extractor.extractFromTree(tree, file, content, langQueries, opts.Detail)
+	})
+	return outcome
+}
+
+// groupReferences groups the identifier references of a file by the innermost
+// symbol enclosing them, in the order the groups first appear. References on the
+// first line of a symbol named name, which declare it, are dropped.
+func groupReferences(refs []Reference, symbols []Symbol, name string) []referenceGroup {
+	var groups []referenceGroup
+	index := make(map[*Symbol]int)
+	for _, ref := range refs {
+		if ref.Category == ReferenceDocs {
+			continue
+		}
+
+		var enclosing *Symbol
+		declaration := false
+		for i := range symbols {
+			sym := &symbols[i]
+			if ref.Line < sym.StartLine || ref.Line > sym.EndLine {
+				continue
+			}
+			if sym.Name == name && sym.StartLine == ref.Line {
+				declaration = true
+				break
+			}
+			if enclosing == nil || sym.EndLine-sym.StartLine < enclosing.EndLine-enclosing.StartLine {
+				enclosing = sym
+			}
+		}
+		if declaration {
+			continue
+		}
+
+		i, ok := index[enclosing]
+		if !ok {
+			i = len(groups)
+			index[enclosing] = i
+			groups = append(groups, referenceGroup{symbol: enclosing})
+		}
+		// A line using the name twice is listed once
+		if n := len(groups[i].refs); n > 0 && groups[i].refs[n-1].Line == ref.Line {
+			continue
+		}
+		groups[i].refs = append(groups[i].refs, ref)
+	}
+
+	// Top-level references are listed after those inside symbols
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].symbol != nil && groups[j].symbol == nil
+	})
+	return groups
+}
+
+// formatReferences formats references grouped by file and enclosing symbol
+func formatReferences(name string, files []string, fileGroups map[string][]referenceGroup, count int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# References to %s\n\n", name))
+	sb.WriteString(fmt.Sprintf("%d references in %d files\n", count, len(files)))
+
+	for _, file := range files {
+		sb.WriteString(fmt.Sprintf("\n## %s\n", file))
+		for _, group := range fileGroups[file] {
+			if group.symbol == nil {
+				sb.WriteString("\n### (top level)\n\n")
+			} else {
+				sym := group.symbol
+				qualified := sym.Name
+				if sym.Receiver != "" {
+					qualified = sym.Receiver + "." + sym.Name
+				}
+				sb.WriteString(fmt.Sprintf("\n### %s %s (lines %d-%d)\n\n", sym.Kind, qualified, sym.StartLine, sym.EndLine))
+			}
+			for _, ref := range group.refs {
+				sb.WriteString(fmt.Sprintf("- line %d: %s\n", ref.Line, ref.Context))
+			}
+		}
+	}
+	return sb.String()
+}
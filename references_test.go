@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFindReferencesContext(t *testing.T) {
+	testDir := t.TempDir()
+
+	testFiles := map[string]string{
+		"server.go": `package app
+
+// NewServer creates a server
+func NewServer() *Server {
+	return &Server{}
+}
+
+var label = "NewServer"
+var fallback = NewServer
+`,
+		"main.go": `package app
+
+type App struct{}
+
+func (a *App) Run() {
+	s, t := NewServer(), NewServer()
+	s.Start()
+}
+
+func main() {
+	NewServer()
+}
+`,
+		"other.go": `package app
+
+func unrelated() {}
+`,
+	}
+	for name, code := range testFiles {
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := FindReferencesContext(context.Background(), "NewServer", filepath.Join(testDir, "*.go"), ExtractOptions{Detail: Standard})
+	if err != nil {
+		t.Fatalf("FindReferencesContext error = %v", err)
+	}
+	if result.Status != StatusOK || result.Files != 3 || result.Symbols != 3 {
+		t.Errorf("result = %+v, want 3 references in 3 files", result)
+	}
+
+	// The declaration, doc comment and string literal are not usages, and a line
+	// using the name twice is listed once
+	mainFile := filepath.Join(testDir, "main.go")
+	serverFile := filepath.Join(testDir, "server.go")
+	want := "# References to NewServer\n\n" +
+		"3 references in 2 files\n" +
+		"\n## " + mainFile + "\n" +
+		"\n### method App.Run (lines 5-8)\n\n" +
+		"- line 6: s, t := NewServer(), NewServer()\n" +
+		"\n### func main (lines 10-12)\n\n" +
+		"- line 11: NewServer()\n" +
+		"\n## " + serverFile + "\n" +
+		"\n### var fallback (lines 9-9)\n\n" +
+		"- line 9: var fallback = NewServer\n"
+	if result.Output != want {
+		t.Errorf("Output =\n%s\nwant\n%s", result.Output, want)
+	}
+
+	// Files are parsed by several workers, or their symbols taken from the cache,
+	// without changing the references
+	cache := NewSymbolCache(10)
+	for _, opts := range []ExtractOptions{
+		{Detail: Standard, Jobs: 1},
+		{Detail: Standard, Jobs: 4},
+		{Detail: Standard, Cache: cache},
+		{Detail: Standard, Cache: cache},
+	} {
+		result, err := FindReferencesContext(context.Background(), "NewServer", filepath.Join(testDir, "*.go"), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Output != want {
+			t.Errorf("Output with %+v =\n%s\nwant\n%s", opts, result.Output, want)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("cache.Len() = %d, want the 2 files mentioning NewServer", cache.Len())
+	}
+
+	result, err = FindReferencesContext(context.Background(), "Missing", filepath.Join(testDir, "*.go"), ExtractOptions{Detail: Standard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != StatusNoSymbols || !strings.Contains(result.Output, "No references to Missing") {
+		t.Errorf("result = %+v, want no references", result)
+	}
+}
+
+func TestGroupReferences(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Outer", Kind: "class", StartLine: 1, EndLine: 10},
+		{Name: "inner", Kind: "method", StartLine: 2, EndLine: 4},
+	}
+	refs := []Reference{
+		{Line: 12, Category: ReferenceProduction},
+		{Line: 3, Category: ReferenceProduction},
+		{Line: 6, Category: ReferenceTests},
+		{Line: 7, Category: ReferenceDocs},
+	}
+
+	groups := groupReferences(refs, symbols, "target")
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %d: %+v", len(groups), groups)
+	}
+	if groups[0].symbol.Name != "inner" || groups[0].refs[0].Line != 3 {
+		t.Errorf("Expected the innermost symbol to enclose line 3 first, got %+v", groups[0])
+	}
+	if groups[1].symbol.Name != "Outer" || groups[1].refs[0].Line != 6 {
+		t.Errorf("Expected Outer to enclose line 6, got %+v", groups[1])
+	}
+	if groups[2].symbol != nil || groups[2].refs[0].Line != 12 {
+		t.Errorf("Expected the top-level reference last, got %+v", groups[2])
+	}
+}
+
+func TestToolHandlers_FindReferences(t *testing.T) {
+	tempDir := t.TempDir()
+	code := "package lib\n\nfunc Helper() {}\n\nfunc Use() {\n\tHelper()\n}\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "lib.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"name": "Helper", "pattern": filepath.Join(tempDir, "*.go")}
+
+	result, err := handlers.findReferences(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || result.Meta["symbols"] != 1 || !strings.Contains(text, "### func Use (lines 5-7)\n\n- line 6: Helper()") {
+		t.Errorf("expected the usage in Use, got %q", text)
+	}
+
+	request.Params.Arguments = map[string]any{"name": "Helper"}
+	result, err = handlers.findReferences(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("expected an error without pattern")
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Bounds of the symbols a repository map lists for each file
+const (
+	repoMapSymbolsPerFile = 10
+	repoMapMembersPerType = 5
+)
+
+// Defaults of the repo_map tool: a map fits in about 1024 tokens, as aider's does,
+// with signatures kept shorter than in outlines
+const (
+	defaultRepoMapTokens          = 1024
+	defaultRepoMapSignatureLength = 120
+)
+
+// repoMapSkippedKinds are declarations shared by many files, which neither rank
+// a file nor take up room in the map
+var repoMapSkippedKinds = map[string]bool{"package": true, "namespace": true}
+
+// identifierPattern matches the identifiers of the languages glyph parses closely
+// enough to count which files mention a name
+var identifierPattern = regexp.MustCompile(`[\p{L}_$][\p{L}\p{N}_$]*`)
+
+// repoMapFile is a file of a repository map with the symbols it defines
+type repoMapFile struct {
+	path  string
+	nodes []*SymbolNode
+	// mentions counts the other files mentioning each name the file defines
+	mentions map[string]int
+	// referencedBy is the set of other files mentioning one of its top-level symbols
+	referencedBy map[int]bool
+}
+
+// RepoMapContext builds a condensed map of the files matching a pattern that fits in
+// budget bytes: the files whose top-level symbols are mentioned by the most other
+// files come first, each listing its most mentioned symbols. Mentions are counted
+// by identifier in the source text, so a name in a comment counts too. Sections
+// that no longer fit are left out and counted at the end.
+func RepoMapContext(ctx context.Context, pattern string, budget int, opts ExtractOptions) (*ExtractionResult, error) {
+	files, err := FindFilesWithOptions(pattern, opts.Discovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files: %w", err)
+	}
+	if len(files) == 0 {
+		return noFilesResult(pattern), nil
+	}
+	if err := opts.Limits.check(len(files), 0); err != nil {
+		return nil, err
+	}
+
+	result := &ExtractionResult{Status: StatusOK, Files: len(files), log: opts.Log}
+	root := ProjectRoot(PatternBaseDir(pattern))
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	var mapped []*repoMapFile
+	var mentions []map[string]bool
+	definedIn := make(map[string][]int)
+	var parsedBytes int64
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(i, len(files))
+		}
+
+		extractor.content = nil
+		symbols, err := extractFile(extractor, opts, file, opts.Detail)
+		parsedBytes += int64(len(extractor.content))
+		if err := opts.Limits.check(len(files), parsedBytes); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			result.warnSkipped(file, err)
+			continue
+		}
+
+		path := file
+		if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		kept := symbols[:0]
+		var shared []string
+		for _, sym := range symbols {
+			if repoMapSkippedKinds[sym.Kind] {
+				shared = append(shared, sym.Name)
+				continue
+			}
+			kept = append(kept, sym)
+		}
+		index := len(mapped)
+		entry := &repoMapFile{path: path, nodes: BuildHierarchy(kept), mentions: make(map[string]int), referencedBy: make(map[int]bool)}
+		mapped = append(mapped, entry)
+		define := func(name string) {
+			if defined := definedIn[name]; len(defined) == 0 || defined[len(defined)-1] != index {
+				definedIn[name] = append(defined, index)
+			}
+		}
+		for _, node := range entry.nodes {
+			define(node.Symbol.Name)
+			for _, child := range node.Children {
+				define(child.Symbol.Name)
+			}
+		}
+
+		fileMentions := make(map[string]bool)
+		for _, id := range identifierPattern.FindAllString(string(extractor.content), -1) {
+			fileMentions[id] = true
+		}
+		// Declaring package main does not mention func main
+		for _, name := range shared {
+			delete(fileMentions, name)
+		}
+		mentions = append(mentions, fileMentions)
+	}
+
+	if opts.Progress != nil {
+		opts.Progress(len(files), len(files))
+	}
+
+	for i, fileMentions := range mentions {
+		for name := range fileMentions {
+			for _, j := range definedIn[name] {
+				if j == i {
+					continue
+				}
+				mapped[j].mentions[name]++
+				if isTopLevel(mapped[j], name) {
+					mapped[j].referencedBy[i] = true
+				}
+			}
+		}
+	}
+
+	order := make([]int, len(mapped))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		fa, fb := mapped[order[a]], mapped[order[b]]
+		if len(fa.referencedBy) != len(fb.referencedBy) {
+			return len(fa.referencedBy) > len(fb.referencedBy)
+		}
+		return fa.path < fb.path
+	})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Repository map of %s\n\n%d files ranked by how many other files mention their symbols\n", root, len(mapped)))
+	shown := 0
+	for _, i := range order {
+		section, entries := formatRepoMapFile(mapped[i], opts.MaxSignatureLength)
+		if entries == 0 {
+			continue
+		}
+		if budget > 0 && sb.Len()+len(section) > budget {
+			break
+		}
+		sb.WriteString(section)
+		shown++
+		result.Symbols += entries
+	}
+
+	if countRepoMapFiles(mapped) == 0 {
+		result.Status = StatusNoSymbols
+		result.Output = "No symbols found"
+		return result, nil
+	}
+
+	if omitted := countRepoMapFiles(mapped) - shown; omitted > 0 {
+		sb.WriteString(fmt.Sprintf("\n… %d more files not shown\n", omitted))
+	}
+	result.Output = sb.String()
+	return result, nil
+}
+
+// isTopLevel reports whether a file defines name as a top-level symbol
+func isTopLevel(file *repoMapFile, name string) bool {
+	for _, node := range file.nodes {
+		if node.Symbol.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// countRepoMapFiles counts the files that define any symbols
+func countRepoMapFiles(files []*repoMapFile) int {
+	count := 0
+	for _, file := range files {
+		if len(file.nodes) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// formatRepoMapFile formats the section of a file: its most mentioned top-level
+// symbols, each followed by its most mentioned members, or its first top-level
+// symbols when no other file mentions any. It returns the section and the number
+// of symbols listed in it.
+func formatRepoMapFile(file *repoMapFile, maxSignatureLength int) (string, int) {
+	if len(file.nodes) == 0 {
+		return "", 0
+	}
+
+	entries := 0
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n## %s (mentioned by %d files)\n\n", file.path, len(file.referencedBy)))
+	mentioned := len(file.referencedBy) > 0
+	for _, node := range rankNodes(file.nodes, file.mentions, repoMapSymbolsPerFile) {
+		if mentioned && file.mentions[node.Symbol.Name] == 0 {
+			break
+		}
+		sb.WriteString("- " + repoMapEntry(node.Symbol, maxSignatureLength) + "\n")
+		entries++
+		for _, child := range rankNodes(node.Children, file.mentions, repoMapMembersPerType) {
+			if file.mentions[child.Symbol.Name] == 0 {
+				continue
+			}
+			sb.WriteString("  - " + repoMapEntry(child.Symbol, maxSignatureLength) + "\n")
+			entries++
+		}
+	}
+	return sb.String(), entries
+}
+
+// rankNodes returns the limit most mentioned nodes, keeping source order among
+// equally mentioned ones
+func rankNodes(nodes []*SymbolNode, mentions map[string]int, limit int) []*SymbolNode {
+	ranked := make([]*SymbolNode, len(nodes))
+	copy(ranked, nodes)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return mentions[ranked[i].Symbol.Name] > mentions[ranked[j].Symbol.Name]
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// repoMapEntry formats a symbol as in the standard outline, on a single line
+func repoMapEntry(sym Symbol, maxSignatureLength int) string {
+	sym.Signature = strings.Join(strings.Fields(sym.Signature), " ")
+	return standardEntry(sym, maxSignatureLength)
+}
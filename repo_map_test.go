@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// writeRepoMapFiles writes a small Go package where store.go is used by two files
+// and handler.go by one
+func writeRepoMapFiles(t *testing.T) string {
+	t.Helper()
+	testDir := t.TempDir()
+	testFiles := map[string]string{
+		"store.go": `package app
+
+type Store struct{}
+
+func (s *Store) Get(key string) string { return "" }
+
+func (s *Store) unused() {}
+
+func NewStore() *Store { return &Store{} }
+`,
+		"handler.go": `package app
+
+func Handle() string {
+	return NewStore().Get("a")
+}
+`,
+		"main.go": `package app
+
+func main() {
+	_ = NewStore()
+	Handle()
+}
+`,
+	}
+	for name, code := range testFiles {
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return testDir
+}
+
+func TestRepoMapContext(t *testing.T) {
+	testDir := writeRepoMapFiles(t)
+
+	result, err := RepoMapContext(context.Background(), filepath.Join(testDir, "*.go"), 0, ExtractOptions{Detail: Standard})
+	if err != nil {
+		t.Fatalf("RepoMapContext error = %v", err)
+	}
+	if result.Status != StatusOK || result.Files != 3 {
+		t.Errorf("result = %+v, want 3 files", result)
+	}
+
+	store := strings.Index(result.Output, "## store.go (mentioned by 2 files)")
+	handler := strings.Index(result.Output, "## handler.go (mentioned by 1 files)")
+	mainFile := strings.Index(result.Output, "## main.go (mentioned by 0 files)")
+	if store < 0 || handler < store || mainFile < handler {
+		t.Fatalf("Expected files ranked store.go, handler.go, main.go, got:\n%s", result.Output)
+	}
+
+	// The most mentioned symbols of a file come first, and unmentioned ones are left
+	// out unless no symbol of the file is mentioned
+	section := result.Output[store:handler]
+	if newStore, get := strings.Index(section, "func: func NewStore()"), strings.Index(section, "method: func (s *Store) Get"); newStore < 0 || get < newStore {
+		t.Errorf("Expected NewStore before Get in:\n%s", section)
+	}
+	if strings.Contains(section, "unused") || strings.Contains(section, "struct: Store") {
+		t.Errorf("Expected the unmentioned symbols to be left out of:\n%s", section)
+	}
+	if !strings.Contains(result.Output[mainFile:], "- func: func main()") {
+		t.Errorf("Expected main.go to list its symbols, got:\n%s", result.Output[mainFile:])
+	}
+}
+
+func TestRepoMapContext_Budget(t *testing.T) {
+	testDir := writeRepoMapFiles(t)
+
+	full, err := RepoMapContext(context.Background(), filepath.Join(testDir, "*.go"), 0, ExtractOptions{Detail: Standard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The budget holds the header and the first section
+	budget := strings.Index(full.Output, "\n## handler.go")
+
+	result, err := RepoMapContext(context.Background(), filepath.Join(testDir, "*.go"), budget, ExtractOptions{Detail: Standard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Output, "## store.go") || strings.Contains(result.Output, "## handler.go") {
+		t.Errorf("Expected only the top ranked file within the budget, got:\n%s", result.Output)
+	}
+	if !strings.HasSuffix(result.Output, "… 2 more files not shown\n") {
+		t.Errorf("Expected a note of the omitted files, got:\n%s", result.Output)
+	}
+}
+
+func TestToolHandlers_RepoMap(t *testing.T) {
+	testDir := writeRepoMapFiles(t)
+
+	handlers := &toolHandlers{}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(testDir, "*.go")}
+
+	result, err := handlers.repoMap(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "# Repository map of "+testDir) {
+		t.Errorf("expected a repository map, got %q", text)
+	}
+
+	request.Params.Arguments = map[string]any{"pattern": filepath.Join(testDir, "*.go"), "max_tokens": -1}
+	result, err = handlers.repoMap(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("expected an error for a negative budget")
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// outlineURIPrefix starts the URI of every file outline resource; the absolute
+// path of the file follows, as in glyph://outline/home/me/app/server.go
+const outlineURIPrefix = "glyph://outline"
+
+// outlineTemplate describes the file outline resources to clients
+var outlineTemplate = mcp.NewResourceTemplate(
+	outlineURIPrefix+"{+path}{?detail}",
+	"File outline",
+	mcp.WithTemplateDescription("Symbol outline of the source file at an absolute path, as extract_symbols reports it; detail is 'minimal', 'standard' (default) or 'full'"),
+	mcp.WithTemplateMIMEType("text/markdown"),
+)
+
+// parseOutlineURI returns the file path and detail level of an outline URI
+func parseOutlineURI(uri string) (string, DetailLevel, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "glyph" || u.Host != "outline" {
+		return "", Standard, fmt.Errorf("not a file outline URI: %s", uri)
+	}
+	path := filepath.FromSlash(u.Path)
+	if !filepath.IsAbs(path) {
+		return "", Standard, fmt.Errorf("outline URI must name an absolute path: %s", uri)
+	}
+	detail := Standard
+	if d := u.Query().Get("detail"); d != "" {
+		detail = ParseDetailLevel(d)
+	}
+	return path, detail, nil
+}
+
+// readOutline returns the outline of the file named by a glyph://outline URI
+func (h *toolHandlers) readOutline(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	text, err := h.outlineText(ctx, request.Params.URI, FormatMarkdown)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      request.Params.URI,
+		MIMEType: "text/markdown",
+		Text:     text,
+	}}, nil
+}
+
+// outlineText returns the outline of the file named by an outline URI in a format
+func (h *toolHandlers) outlineText(ctx context.Context, uri, format string) (string, error) {
+	path, detail, err := parseOutlineURI(uri)
+	if err != nil {
+		return "", err
+	}
+	if err := h.checkRoots(path); err != nil {
+		return "", err
+	}
+
+	// The path names one file, so glob characters in it are taken literally and
+	// it is outlined even where discovery would skip it
+	result, err := ExtractSymbolsContext(ctx, escapeGlob(path), ExtractOptions{
+		Limits:             h.limits,
+		Cache:              h.cache,
+		Detail:             detail,
+		Discovery:          DiscoveryOptions{Hidden: true, IncludeGenerated: true, Roots: h.roots},
+		MaxSignatureLength: defaultMaxSignatureLength,
+		Deprecated:         DeprecatedInclude,
+		NameMatch:          "regex",
+		Format:             format,
+		Log:                toolLog(ctx),
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.Status == StatusNoFiles {
+		return "", errors.New(result.Output)
+	}
+
+	text := result.Output
+	if len(result.Warnings) > 0 && result.Format == "" {
+		text += "\n## Warnings\n\n- " + strings.Join(result.Warnings, "\n- ") + "\n"
+	}
+	return text, nil
+}
+
+// escapeGlob escapes the glob metacharacters of a path
+func escapeGlob(path string) string {
+	var sb strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(`*?[]{}\`, r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
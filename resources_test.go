@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestParseOutlineURI(t *testing.T) {
+	tests := []struct {
+		uri    string
+		path   string
+		detail DetailLevel
+		fails  bool
+	}{
+		{uri: "glyph://outline/src/app/main.go", path: "/src/app/main.go", detail: Standard},
+		{uri: "glyph://outline/src/my%20app/main.go?detail=minimal", path: "/src/my app/main.go", detail: Minimal},
+		{uri: "glyph://outline", fails: true},
+		{uri: "glyph://symbols/src/main.go", fails: true},
+		{uri: "file:///src/main.go", fails: true},
+	}
+
+	for _, tt := range tests {
+		path, detail, err := parseOutlineURI(tt.uri)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("parseOutlineURI(%q): expected an error", tt.uri)
+			}
+			continue
+		}
+		if err != nil || path != tt.path || detail != tt.detail {
+			t.Errorf("parseOutlineURI(%q) = %q, %v, %v; want %q, %v", tt.uri, path, detail, err, tt.path, tt.detail)
+		}
+	}
+}
+
+func TestReadOutlineResource(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".hidden [dir]")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "service.py")
+	if err := os.WriteFile(file, []byte("class Service:\n    def start(self):\n        pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mcpServer := server.NewMCPServer("glyph", "1.0.0", server.WithResourceCapabilities(false, false))
+	handlers := &toolHandlers{}
+	mcpServer.AddResourceTemplate(outlineTemplate, handlers.readOutline)
+
+	read := func(uri string) mcp.JSONRPCMessage {
+		message, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0", "id": 1, "method": "resources/read",
+			"params": map[string]any{"uri": uri},
+		})
+		return mcpServer.HandleMessage(context.Background(), message)
+	}
+
+	uri := "glyph://outline" + strings.ReplaceAll(filepath.ToSlash(file), " ", "%20")
+	response, ok := read(uri).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("expected a response, got %+v", read(uri))
+	}
+	result := response.Result.(mcp.ReadResourceResult)
+	text := result.Contents[0].(mcp.TextResourceContents)
+	if text.URI != uri || text.MIMEType != "text/markdown" || !strings.Contains(text.Text, "def start(self)") {
+		t.Errorf("unexpected outline: %+v", text)
+	}
+
+	if _, ok := read("glyph://outline" + filepath.ToSlash(dir) + "/missing.py").(mcp.JSONRPCError); !ok {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// restAPI serves symbols as JSON over plain HTTP, for web dashboards and tools that
+// don't speak MCP. Every endpoint responds with the document of the json format;
+// the handlers' roots, limits and cache apply as they do to the MCP tools.
+type restAPI struct {
+	handlers *toolHandlers
+}
+
+// restError is the body of a failed request
+type restError struct {
+	SchemaVersion int    `json:"schema_version"`
+	Error         string `json:"error"`
+	// Status, Limit, Max and Actual describe an exceeded limit
+	Status string `json:"status,omitempty"`
+	Limit  string `json:"limit,omitempty"`
+	Max    int64  `json:"max,omitempty"`
+	Actual int64  `json:"actual,omitempty"`
+}
+
+// errFileNotFound is returned for a /file path that names no file
+var errFileNotFound = errors.New("file not found")
+
+// newRESTHandler routes the endpoints of the REST API:
+//
+//	GET /outline?pattern=…     symbols of the files matching one or more patterns
+//	GET /file?path=…           symbols of one file
+//	GET /search?q=…&pattern=…  symbols whose name fuzzily matches q, with their scores
+//
+// Browsers on the allowed origins may call it, as with the MCP HTTP transport.
+func newRESTHandler(handlers *toolHandlers, origins []string) http.Handler {
+	api := &restAPI{handlers: handlers}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /outline", api.outline)
+	mux.HandleFunc("GET /file", api.file)
+	mux.HandleFunc("GET /search", api.search)
+	return withCORS(mux, origins)
+}
+
+// serveREST serves the REST API on addr until SIGINT or SIGTERM, then lets
+// in-flight requests finish within grace before closing their connections
+func serveREST(handler http.Handler, addr string, grace time.Duration) error {
+	httpServer := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdown := make(chan error, 1)
+	go func() {
+		<-signals.Done()
+		// Restore the default behavior, so a second signal terminates the process
+		stop()
+		fmt.Fprintln(os.Stderr, "Shutting down: waiting for in-flight requests")
+		ctx, cancel := context.WithTimeout(context.Background(), grace)
+		defer cancel()
+		shutdown <- httpServer.Shutdown(ctx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving the REST API at %s\n", addr)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-shutdown
+}
+
+func (api *restAPI) outline(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pattern, err := api.pattern(query["pattern"])
+	if err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	opts, err := api.options(query)
+	if err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	api.extract(w, r, pattern, opts)
+}
+
+func (api *restAPI) file(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	path, err := ExpandPath(query.Get("path"))
+	if err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	if path == "" || !filepath.IsAbs(path) {
+		writeRESTError(w, fmt.Errorf("path must be an absolute file path, got: %q", query.Get("path")))
+		return
+	}
+	if err := api.handlers.checkRoots(path); err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		writeRESTError(w, fmt.Errorf("%w: %s", errFileNotFound, path))
+		return
+	}
+	opts, err := api.options(query)
+	if err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	// The file is listed rather than matched, so a name like "a[1].go" is not a glob
+	// and a requested file is outlined even where discovery would skip it
+	opts.Discovery = DiscoveryOptions{Files: []string{path}, Hidden: true, IncludeGenerated: true, IncludeNestedModules: true, Roots: api.handlers.roots}
+	opts.Page = Page{}
+	api.extract(w, r, path, opts)
+}
+
+func (api *restAPI) search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("q") == "" {
+		writeRESTError(w, errors.New("q is required"))
+		return
+	}
+	pattern, err := api.pattern(query["pattern"])
+	if err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	opts, err := api.options(query)
+	if err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	opts.Name = query.Get("q")
+	opts.NameMatch = query.Get("match")
+	if opts.NameMatch == "" {
+		opts.NameMatch = "fuzzy"
+	}
+	api.extract(w, r, pattern, opts)
+}
+
+// pattern resolves the pattern parameters of a request, several of which form a
+// pattern list
+func (api *restAPI) pattern(patterns []string) (string, error) {
+	if len(patterns) == 0 || slices.Contains(patterns, "") {
+		return "", errors.New("pattern is required")
+	}
+	return api.handlers.resolvePattern(strings.Join(patterns, ","))
+}
+
+// options parses the extraction options shared by the endpoints
+func (api *restAPI) options(query map[string][]string) (ExtractOptions, error) {
+	get := func(name string) string {
+		if values := query[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	flags := make(map[string]bool)
+	for _, name := range []string{"no_tests", "hidden", "include_generated", "include_nested_modules"} {
+		if value := get(name); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return ExtractOptions{}, fmt.Errorf("%s must be true or false, got: %s", name, value)
+			}
+			flags[name] = b
+		}
+	}
+	pageSize := 0
+	if value := get("page_size"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return ExtractOptions{}, fmt.Errorf("page_size must be a non-negative integer, got: %s", value)
+		}
+		pageSize = n
+	}
+	publicOnly, err := parseVisibility(get("visibility"))
+	if err != nil {
+		return ExtractOptions{}, err
+	}
+	excludes, err := expandExcludes(query["exclude"])
+	if err != nil {
+		return ExtractOptions{}, err
+	}
+
+	return ExtractOptions{
+		Detail:             ParseDetailLevel(get("detail")),
+		Limits:             api.handlers.limits,
+		Cache:              api.handlers.cache,
+		Structured:         true,
+		MaxSignatureLength: defaultMaxSignatureLength,
+		Discovery: DiscoveryOptions{
+			IncludeNestedModules: flags["include_nested_modules"],
+			ExcludeTests:         flags["no_tests"],
+			IncludeGenerated:     flags["include_generated"],
+			Hidden:               flags["hidden"],
+			Exclude:              excludes,
+			Roots:                api.handlers.roots,
+		},
+		PublicOnly: publicOnly,
+		Page:       Page{Cursor: get("cursor"), Size: pageSize},
+	}, nil
+}
+
+// extract writes the json format's document of the symbols of pattern
+func (api *restAPI) extract(w http.ResponseWriter, r *http.Request, pattern string, opts ExtractOptions) {
+	result, err := ExtractSymbolsContext(r.Context(), pattern, opts)
+	if err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	doc := result.Structured
+	if doc == nil {
+		doc = &jsonOutline{SchemaVersion: SchemaVersion, Status: result.Status, Outline: []jsonFile{}}
+	}
+	writeJSONResponse(w, http.StatusOK, doc)
+}
+
+// writeRESTError writes an error with the status code that fits it: 413 for an
+// exceeded limit, 404 for a missing file and 400 for anything else the request got wrong
+func writeRESTError(w http.ResponseWriter, err error) {
+	body := restError{SchemaVersion: SchemaVersion, Error: err.Error()}
+	status := http.StatusBadRequest
+	var limitErr *LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		status = http.StatusRequestEntityTooLarge
+		body.Status, body.Limit, body.Max, body.Actual = StatusLimitExceeded, limitErr.Limit, limitErr.Max, limitErr.Actual
+	case errors.Is(err, errFileNotFound):
+		status = http.StatusNotFound
+	}
+	writeJSONResponse(w, status, body)
+}
+
+// writeJSONResponse writes v as an indented JSON response
+func writeJSONResponse(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	// The status line is already written, so an encoding error can't be reported
+	_ = encoder.Encode(v)
+}
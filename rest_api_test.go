@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRESTHandler(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"service.go": "package app\n\nfunc UserServiceView() {}\n\nfunc UserService() {}\n\nfunc useless() {}\n",
+		"main.py":    "def run():\n    pass\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outside := t.TempDir()
+
+	handler := newRESTHandler(&toolHandlers{limits: ExtractLimits{MaxFiles: 5}, roots: []string{dir}, cache: NewSymbolCache(10)}, nil)
+	get := func(path string, query url.Values) (int, map[string]any) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path+"?"+query.Encode(), nil))
+		var body map[string]any
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET %s?%s: invalid JSON %q", path, query.Encode(), recorder.Body)
+		}
+		return recorder.Code, body
+	}
+	symbols := func(body map[string]any) map[string]float64 {
+		scores := make(map[string]float64)
+		for _, file := range body["outline"].([]any) {
+			for _, symbol := range file.(map[string]any)["symbols"].([]any) {
+				symbol := symbol.(map[string]any)
+				score, _ := symbol["score"].(float64)
+				scores[symbol["name"].(string)] = score
+			}
+		}
+		return scores
+	}
+
+	// Several patterns form a list
+	status, body := get("/outline", url.Values{"pattern": {filepath.Join(dir, "*.go"), filepath.Join(dir, "*.py")}})
+	if status != http.StatusOK || body["files"] != 2.0 {
+		t.Errorf("/outline: status %d, %v", status, body)
+	}
+
+	// Excludes work as for the CLI's -exclude, with or without the '!'
+	status, body = get("/outline", url.Values{"pattern": {filepath.Join(dir, "*")}, "exclude": {"*.py"}})
+	if status != http.StatusOK || body["files"] != 1.0 {
+		t.Errorf("/outline with an exclude: status %d, %v", status, body)
+	}
+
+	status, body = get("/file", url.Values{"path": {filepath.Join(dir, "main.py")}})
+	if got := symbols(body); status != http.StatusOK || len(got) != 2 {
+		t.Errorf("/file: status %d, symbols %v", status, got)
+	} else if _, ok := got["run"]; !ok {
+		t.Errorf("/file: symbols %v, want run", got)
+	}
+
+	// Fuzzy matches are scored, the closest highest, and others are left out
+	status, body = get("/search", url.Values{"q": {"usrSvc"}, "pattern": {filepath.Join(dir, "**/*")}})
+	if got := symbols(body); status != http.StatusOK || len(got) != 2 || got["UserService"] <= got["UserServiceView"] {
+		t.Errorf("/search: status %d, symbol scores %v", status, got)
+	}
+
+	errorTests := []struct {
+		name   string
+		path   string
+		query  url.Values
+		status int
+	}{
+		{"missing file", "/file", url.Values{"path": {filepath.Join(dir, "missing.go")}}, http.StatusNotFound},
+		{"relative file", "/file", url.Values{"path": {"main.py"}}, http.StatusBadRequest},
+		{"file outside the roots", "/file", url.Values{"path": {filepath.Join(outside, "x.go")}}, http.StatusBadRequest},
+		{"pattern outside the roots", "/outline", url.Values{"pattern": {filepath.Join(outside, "*.go")}}, http.StatusBadRequest},
+		{"no pattern", "/outline", nil, http.StatusBadRequest},
+		{"no query", "/search", url.Values{"pattern": {filepath.Join(dir, "*.go")}}, http.StatusBadRequest},
+		{"undefined variable in exclude", "/outline", url.Values{"pattern": {filepath.Join(dir, "*.go")}, "exclude": {"$GLYPH_UNDEFINED_EXCLUDE/*.go"}}, http.StatusBadRequest},
+		{"invalid flag", "/outline", url.Values{"pattern": {filepath.Join(dir, "*.go")}, "no_tests": {"maybe"}}, http.StatusBadRequest},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := get(tt.path, tt.query)
+			if status != tt.status || body["error"] == "" {
+				t.Errorf("status %d, %v; want status %d with an error", status, body, tt.status)
+			}
+		})
+	}
+
+	// An exceeded limit reports which one
+	for i := 0; i < 5; i++ {
+		if err := os.WriteFile(filepath.Join(dir, "extra"+string(rune('a'+i))+".go"), []byte("package app\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status, body = get("/outline", url.Values{"pattern": {filepath.Join(dir, "*.go")}})
+	if status != http.StatusRequestEntityTooLarge || body["status"] != StatusLimitExceeded || body["limit"] != "max_files" {
+		t.Errorf("exceeded limit: status %d, %v", status, body)
+	}
+}
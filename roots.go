@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolveRoots makes root directories absolute and resolves their symlinks, so
+// paths can be compared with them
+func resolveRoots(dirs []string) ([]string, error) {
+	roots := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		expanded, err := ExpandPath(dir)
+		if err != nil {
+			return nil, err
+		}
+		abs, err := filepath.Abs(expanded)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("invalid root %s: not a directory", dir)
+		}
+		roots = append(roots, resolveSymlinks(abs))
+	}
+	return roots, nil
+}
+
+// withinRoots reports whether a path, with its symlinks resolved, is one of roots
+// or below one. Any path is within an empty list of roots.
+func withinRoots(path string, roots []string) bool {
+	if len(roots) == 0 {
+		return true
+	}
+	resolved := resolveSymlinks(path)
+	for _, root := range roots {
+		if rel, err := filepath.Rel(root, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSymlinks cleans an absolute path and resolves the symlinks of its longest
+// existing prefix, so a path that does not exist yet is resolved too
+func resolveSymlinks(path string) string {
+	path = filepath.Clean(path)
+	var rest []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			for i := len(rest) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, rest[i])
+			}
+			return resolved
+		}
+		if filepath.Dir(dir) == dir {
+			return path
+		}
+		rest = append(rest, filepath.Base(dir))
+	}
+}
+
+// checkRoots returns an error if a path the server would read lies outside the
+// roots it was started with
+func (h *toolHandlers) checkRoots(path string) error {
+	if !withinRoots(path, h.roots) {
+		return fmt.Errorf("%s is outside the allowed roots: %s", path, strings.Join(h.roots, ", "))
+	}
+	return nil
+}
+
+// resolvePattern resolves a pattern like the package-level resolvePattern and
+// rejects it if the base directory of any of its patterns lies outside the roots.
+// Files the pattern reaches through symlinks are filtered during discovery.
+func (h *toolHandlers) resolvePattern(pattern string) (string, error) {
+	resolved, err := resolvePattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	for _, base := range SplitPatterns(resolved) {
+		if path, _, ok := SplitLineRange(base); ok {
+			base = path
+		}
+		if err := h.checkRoots(PatternBaseDir(base)); err != nil {
+			return "", fmt.Errorf("pattern %w", err)
+		}
+	}
+	return resolved, nil
+}
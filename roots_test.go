@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWithinRoots(t *testing.T) {
+	base := resolveSymlinks(t.TempDir())
+	root := filepath.Join(base, "repo")
+	outside := filepath.Join(base, "secrets")
+	for _, dir := range []string{root, outside} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		roots []string
+		want  bool
+	}{
+		{"no roots", "/etc/passwd", nil, true},
+		{"root itself", root, []string{root}, true},
+		{"below root", filepath.Join(root, "src", "main.go"), []string{root}, true},
+		{"sibling with root as prefix", root + "-other/main.go", []string{root}, false},
+		{"parent through dot-dot", filepath.Join(root, "..", "secrets", "key"), []string{root}, false},
+		{"symlink out of root", filepath.Join(root, "link", "key"), []string{root}, false},
+		{"second root", filepath.Join(outside, "key"), []string{root, outside}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withinRoots(tt.path, tt.roots); got != tt.want {
+				t.Errorf("withinRoots(%q, %v) = %v, want %v", tt.path, tt.roots, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRoots(t *testing.T) {
+	dir := t.TempDir()
+	roots, err := resolveRoots([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 || roots[0] != resolveSymlinks(dir) {
+		t.Errorf("resolveRoots = %v, want [%s]", roots, resolveSymlinks(dir))
+	}
+
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, invalid := range []string{file, filepath.Join(dir, "missing")} {
+		if _, err := resolveRoots([]string{invalid}); err == nil {
+			t.Errorf("resolveRoots(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestToolHandlers_Roots(t *testing.T) {
+	base := resolveSymlinks(t.TempDir())
+	root := filepath.Join(base, "repo")
+	outside := filepath.Join(base, "secrets")
+	for _, dir := range []string{root, outside} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "app.go"), []byte("package app\n\nconst home = \"https://example.com/app\"\n\nfunc Serve() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "key.go"), []byte("package secrets\n\nconst query = \"SELECT password FROM users\"\n\nfunc Leak() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "key.go"), filepath.Join(root, "key.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := &toolHandlers{roots: []string{root}}
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		var request mcp.CallToolRequest
+		request.Params.Arguments = args
+		result, err := handlers.extractSymbols(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// Files symlinked into a root from outside it are left out
+	result := call(map[string]any{"pattern": filepath.Join(root, "*.go")})
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "Serve") || strings.Contains(text, "Leak") {
+		t.Errorf("expected only the symbols of files in the root, got %q", text)
+	}
+
+	// Strings mode reads only the files in the root too
+	result = call(map[string]any{"pattern": filepath.Join(root, "*.go"), "mode": "strings"})
+	text = result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "example.com") || strings.Contains(text, "password") {
+		t.Errorf("expected only the strings of files in the root, got %q", text)
+	}
+
+	for _, args := range []map[string]any{
+		{"pattern": filepath.Join(outside, "*.go")},
+		{"pattern": filepath.Join(root, "..", "**", "*.go")},
+		{"pattern": []any{filepath.Join(root, "*.go"), filepath.Join(outside, "*.go")}},
+		{"pattern": filepath.Join(root, "*.go"), "coverage": filepath.Join(outside, "cover.out")},
+	} {
+		result := call(args)
+		if text := result.Content[0].(mcp.TextContent).Text; !result.IsError || !strings.Contains(text, "outside the allowed roots") {
+			t.Errorf("call with %v = %q, want it rejected", args, text)
+		}
+	}
+
+	if _, err := handlers.outlineText(context.Background(), outlineURIPrefix+filepath.ToSlash(filepath.Join(outside, "key.go")), FormatMarkdown); err == nil || !strings.Contains(err.Error(), "outside the allowed roots") {
+		t.Errorf("outlineText outside the roots error = %v, want it rejected", err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRustSymbolExtraction(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/rs_basic.rs.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	symbolsByKind := make(map[string][]string)
+	for _, symbol := range symbols {
+		symbolsByKind[symbol.Kind] = append(symbolsByKind[symbol.Kind], symbol.Name)
+	}
+
+	expected := map[string][]string{
+		"func":   {"distance", "helper"},
+		"method": {"new", "swap", "fmt", "area", "name"},
+		"struct": {"Point", "Pair"},
+		"union":  {"IntOrFloat"},
+		"enum":   {"Shape"},
+		"trait":  {"Area"},
+		"impl":   {"Point", "Pair"},
+		"type":   {"Result"},
+		"const":  {"MAX"},
+		"static": {"COUNTER"},
+		"macro":  {"square"},
+		"module": {"geometry", "inner"},
+	}
+	for kind, names := range expected {
+		for _, name := range names {
+			if !contains(symbolsByKind[kind], name) {
+				t.Errorf("Expected %s symbol '%s' not found. Found: %v", kind, name, symbolsByKind[kind])
+			}
+		}
+	}
+	if contains(symbolsByKind["func"], "new") {
+		t.Errorf("expected methods not to be reported as functions: %v", symbolsByKind["func"])
+	}
+}
+
+func TestRustSignaturesAndVisibility(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/rs_basic.rs.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	tests := []struct {
+		kind      string
+		name      string
+		signature string
+		receiver  string
+		public    bool
+	}{
+		{"func", "distance", "pub fn distance(a: f64, b: f64) -> f64", "", true},
+		{"func", "helper", "pub(crate) fn helper<'a>(s: &'a str) -> &'a str where 'a: 'a", "", false},
+		{"method", "new", "pub fn new(x: T, y: T) -> Self", "Point", true},
+		{"method", "swap", "fn swap(&mut self)", "Point", false},
+		{"method", "fmt", "fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result", "Pair", true},
+		{"impl", "Pair", "impl fmt::Display for Pair", "", true},
+		{"const", "MAX", "pub const MAX: usize", "", true},
+		{"static", "COUNTER", "static mut COUNTER: u32", "", false},
+		{"struct", "Pair", "struct Pair(i32, i32)", "", false},
+		{"macro", "square", "macro_rules! square", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind+" "+tt.name, func(t *testing.T) {
+			for _, sym := range symbols {
+				if sym.Kind != tt.kind || sym.Name != tt.name || sym.Signature != tt.signature {
+					continue
+				}
+				if sym.Receiver != tt.receiver {
+					t.Errorf("Receiver = %q, want %q", sym.Receiver, tt.receiver)
+				}
+				if sym.Public != tt.public {
+					t.Errorf("Public = %v, want %v", sym.Public, tt.public)
+				}
+				return
+			}
+			t.Errorf("no %s %s with signature %q", tt.kind, tt.name, tt.signature)
+		})
+	}
+}
+
+func TestRustHierarchy(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/rs_basic.rs.txt", Minimal)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	result := FormatSymbols(symbols, Minimal)
+	for _, expected := range []string{"- impl: Point (line 27)\n  - method: new", "- module: geometry (line 40)\n  - func: distance"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("expected %q in outline:\n%s", expected, result)
+		}
+	}
+}
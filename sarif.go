@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sarifSchema is the JSON Schema of the SARIF 2.1.0 logs glyph writes
+const sarifSchema = "https://docs.oasis-open.org/sarif/sarif/v2.1.0/errata01/os/schemas/sarif-schema-2.1.0.json"
+
+// Rule and notification IDs of glyph's SARIF logs
+const (
+	sarifSymbolRule  = "glyph/symbol"
+	sarifParseError  = "glyph/parse-error"
+	sarifSkippedFile = "glyph/skipped-file"
+)
+
+// sarifLogicalKinds maps symbol kinds to SARIF logical location kinds
+var sarifLogicalKinds = map[string]string{
+	"func":        "function",
+	"method":      "member",
+	"constructor": "member",
+	"field":       "member",
+	"property":    "member",
+	"class":       "type",
+	"struct":      "type",
+	"interface":   "type",
+	"enum":        "type",
+	"type":        "type",
+	"record":      "type",
+	"trait":       "type",
+	"union":       "type",
+	"var":         "variable",
+	"const":       "variable",
+	"static":      "variable",
+	"package":     "package",
+	"module":      "module",
+	"namespace":   "namespace",
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	Invocations        []sarifInvocation           `json:"invocations"`
+	OriginalURIBaseIDs map[string]sarifArtifactURI `json:"originalUriBaseIds,omitempty"`
+	ColumnKind         string                      `json:"columnKind"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string            `json:"name"`
+	InformationURI string            `json:"informationUri"`
+	Rules          []sarifDescriptor `json:"rules"`
+	Notifications  []sarifDescriptor `json:"notifications"`
+}
+
+type sarifDescriptor struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Descriptor sarifReference  `json:"descriptor"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifReference struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Kind                string            `json:"kind"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactURI `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifArtifactURI struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   uint32 `json:"startLine"`
+	StartColumn uint32 `json:"startColumn,omitempty"`
+	EndLine     uint32 `json:"endLine,omitempty"`
+	EndColumn   uint32 `json:"endColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// formatSARIF writes symbols as a SARIF 2.1.0 log with a single run: every symbol
+// is an informational result, and parse errors and skipped files are reported as
+// tool execution notifications. Files under root are given relative to the
+// SRCROOT base, so the log can be uploaded from a checkout. Notebook symbols are
+// left out, since their lines are relative to a cell.
+func formatSARIF(symbols []Symbol, metadata map[string]*FileMetadata, warnings []string, root string) (string, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "glyph",
+			InformationURI: "https://github.com/benmyles/glyph",
+			Rules: []sarifDescriptor{
+				{ID: sarifSymbolRule, ShortDescription: sarifMessage{Text: "Symbol declared in the source"}},
+			},
+			Notifications: []sarifDescriptor{
+				{ID: sarifParseError, ShortDescription: sarifMessage{Text: "Source that could not be parsed, where symbols may be missing"}},
+				{ID: sarifSkippedFile, ShortDescription: sarifMessage{Text: "File that was skipped or flagged during extraction"}},
+			},
+		}},
+		// Symbol columns count characters rather than UTF-16 code units
+		ColumnKind: "unicodeCodePoints",
+		Results:    []sarifResult{},
+	}
+	if root != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactURI{
+			"SRCROOT": {URI: "file://" + filepath.ToSlash(root) + "/"},
+		}
+	}
+
+	var files []string
+	fileSymbols := make(map[string][]Symbol)
+	for _, sym := range symbols {
+		if sym.Cell != nil {
+			continue
+		}
+		if _, ok := fileSymbols[sym.FilePath]; !ok {
+			files = append(files, sym.FilePath)
+		}
+		fileSymbols[sym.FilePath] = append(fileSymbols[sym.FilePath], sym)
+	}
+	for _, file := range files {
+		run.Results = appendSARIFResults(run.Results, BuildHierarchy(fileSymbols[file]), sarifArtifact(file, root), "")
+	}
+
+	invocation := sarifInvocation{ExecutionSuccessful: true}
+	parsed := make([]string, 0, len(metadata))
+	for file := range metadata {
+		parsed = append(parsed, file)
+	}
+	sort.Strings(parsed)
+	for _, file := range parsed {
+		for _, lines := range metadata[file].ParseErrors {
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+				Descriptor: sarifReference{ID: sarifParseError},
+				Level:      "warning",
+				Message:    sarifMessage{Text: "Syntax error at lines " + lines.String() + "; symbols there may be missing or incomplete"},
+				Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifact(file, root),
+					Region:           &sarifRegion{StartLine: lines.Start, EndLine: lines.End},
+				}}},
+			})
+		}
+	}
+	for _, warning := range warnings {
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+			Descriptor: sarifReference{ID: sarifSkippedFile},
+			Level:      "warning",
+			Message:    sarifMessage{Text: warning},
+		})
+	}
+	run.Invocations = []sarifInvocation{invocation}
+
+	output, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output) + "\n", nil
+}
+
+// appendSARIFResults appends a result for every symbol of a hierarchy, naming each
+// by the dotted names of the symbols enclosing it
+func appendSARIFResults(results []sarifResult, nodes []*SymbolNode, artifact sarifArtifactURI, scope string) []sarifResult {
+	for _, node := range nodes {
+		sym := node.Symbol
+		qualified := sym.Name
+		if scope != "" {
+			qualified = scope + "." + sym.Name
+		} else if sym.Receiver != "" {
+			qualified = sym.Receiver + "." + sym.Name
+		}
+
+		kind, ok := sarifLogicalKinds[sym.Kind]
+		if !ok {
+			kind = "declaration"
+		}
+
+		message := sym.Kind + " " + qualified
+		if sym.Signature != "" {
+			message += ": " + sym.Signature
+		}
+
+		result := sarifResult{
+			RuleID:  sarifSymbolRule,
+			Kind:    "informational",
+			Level:   "none",
+			Message: sarifMessage{Text: message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: artifact,
+					Region: &sarifRegion{
+						StartLine:   sym.StartLine,
+						StartColumn: sym.StartColumn,
+						EndLine:     sym.EndLine,
+						EndColumn:   sym.EndColumn,
+					},
+				},
+				LogicalLocations: []sarifLogicalLocation{{Name: sym.Name, FullyQualifiedName: qualified, Kind: kind}},
+			}},
+		}
+		if sym.ID != "" {
+			result.PartialFingerprints = map[string]string{"glyphSymbolId/v1": sym.ID}
+		}
+		results = append(results, result)
+
+		results = appendSARIFResults(results, node.Children, artifact, qualified)
+	}
+	return results
+}
+
+// sarifArtifact locates a file relative to SRCROOT when it is under root, and by
+// its file URI otherwise
+func sarifArtifact(file, root string) sarifArtifactURI {
+	if root != "" {
+		if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return sarifArtifactURI{URI: filepath.ToSlash(rel), URIBaseID: "SRCROOT"}
+		}
+	}
+	if filepath.IsAbs(file) {
+		return sarifArtifactURI{URI: "file://" + filepath.ToSlash(file)}
+	}
+	return sarifArtifactURI{URI: filepath.ToSlash(file)}
+}
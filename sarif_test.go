@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFormatSARIF(t *testing.T) {
+	symbols := []Symbol{
+		{ID: "w1", Name: "Widget", Kind: "class", StartLine: 3, EndLine: 9, StartColumn: 1, EndColumn: 2, FilePath: "/proj/src/widget.ts"},
+		{ID: "r1", Name: "render", Kind: "method", Signature: "render(): void", StartLine: 4, EndLine: 6, StartColumn: 3, EndColumn: 4, FilePath: "/proj/src/widget.ts"},
+		{Name: "main", Kind: "func", StartLine: 1, EndLine: 2, StartColumn: 1, EndColumn: 2, FilePath: "/elsewhere/main.go"},
+		{Name: "fit", Kind: "func", StartLine: 1, EndLine: 2, FilePath: "/proj/a.ipynb", Cell: &NotebookCell{Index: 2}},
+	}
+	metadata := map[string]*FileMetadata{
+		"/proj/src/widget.ts": {ParseErrors: []LineRange{{Start: 12, End: 14}}},
+	}
+
+	output, err := formatSARIF(symbols, metadata, []string{"big.go: file too large"}, "/proj")
+	if err != nil {
+		t.Fatalf("formatSARIF: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal([]byte(output), &log); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, output)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version = %q with %d runs, want 2.1.0 with 1", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if got := run.OriginalURIBaseIDs["SRCROOT"].URI; got != "file:///proj/" {
+		t.Errorf("SRCROOT = %q, want file:///proj/", got)
+	}
+
+	if len(run.Results) != 3 {
+		t.Fatalf("got %d results, want 3 (notebook symbols skipped)", len(run.Results))
+	}
+	render := run.Results[1]
+	if render.Message.Text != "method Widget.render: render(): void" {
+		t.Errorf("message = %q", render.Message.Text)
+	}
+	location := render.Locations[0]
+	if got := location.PhysicalLocation.ArtifactLocation; got.URI != "src/widget.ts" || got.URIBaseID != "SRCROOT" {
+		t.Errorf("artifact = %+v, want src/widget.ts under SRCROOT", got)
+	}
+	if got := location.PhysicalLocation.Region; *got != (sarifRegion{StartLine: 4, StartColumn: 3, EndLine: 6, EndColumn: 4}) {
+		t.Errorf("region = %+v", *got)
+	}
+	if got := location.LogicalLocations[0]; got.FullyQualifiedName != "Widget.render" || got.Kind != "member" {
+		t.Errorf("logical location = %+v", got)
+	}
+	if render.PartialFingerprints["glyphSymbolId/v1"] != "r1" {
+		t.Errorf("fingerprints = %v, want the symbol ID", render.PartialFingerprints)
+	}
+	if got := run.Results[2].Locations[0].PhysicalLocation.ArtifactLocation; got.URI != "file:///elsewhere/main.go" || got.URIBaseID != "" {
+		t.Errorf("artifact outside root = %+v, want an absolute file URI", got)
+	}
+
+	notifications := run.Invocations[0].ToolExecutionNotifications
+	if len(notifications) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(notifications))
+	}
+	if notifications[0].Descriptor.ID != sarifParseError || notifications[0].Locations[0].PhysicalLocation.Region.StartLine != 12 {
+		t.Errorf("parse error notification = %+v", notifications[0])
+	}
+	if notifications[1].Descriptor.ID != sarifSkippedFile || !strings.Contains(notifications[1].Message.Text, "big.go") {
+		t.Errorf("warning notification = %+v", notifications[1])
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+// SchemaVersion is the version of the JSON Schema that machine output conforms to.
+// It is bumped whenever properties are added; existing properties never change
+// meaning or type, and none are removed.
+const SchemaVersion = 3
+
+// outputSchema is the JSON Schema of glyph's machine output
+//
+//go:embed schema/glyph.schema.json
+var outputSchema string
+
+// toolOutputSchema returns the outputSchema of an MCP tool whose structured content
+// is described by one definition of the output schema
+func toolOutputSchema(definition string) json.RawMessage {
+	// The embedded schema is valid JSON, as TestOutputSchema checks
+	var schema map[string]any
+	_ = json.Unmarshal([]byte(outputSchema), &schema)
+	delete(schema, "$id")
+	schema["$ref"] = "#/$defs/" + definition
+	// MCP requires outputSchema to describe an object at its root
+	schema["type"] = "object"
+	raw, _ := json.Marshal(schema)
+	return raw
+}
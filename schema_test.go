@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOutputSchema(t *testing.T) {
+	var schema struct {
+		Defs map[string]struct {
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal([]byte(outputSchema), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	meta, ok := schema.Defs["meta"]
+	if !ok {
+		t.Fatal("schema does not define meta")
+	}
+
+	results := map[string]map[string]any{
+		"extraction": newExtractionToolResult(&ExtractionResult{Status: StatusOK, Files: 1, Symbols: 2, NextCursor: "c"}).Meta,
+		"limit":      newLimitToolResult(&LimitError{Limit: "max_files", Max: 1, Actual: 2}).Meta,
+	}
+	for name, got := range results {
+		if got["schema_version"] != SchemaVersion {
+			t.Errorf("%s: expected schema_version %d, got %v", name, SchemaVersion, got["schema_version"])
+		}
+		for key := range got {
+			if _, ok := meta.Properties[key]; !ok {
+				t.Errorf("%s: _meta key %q is not described by the schema", name, key)
+			}
+		}
+		for _, key := range meta.Required {
+			if _, ok := got[key]; !ok {
+				t.Errorf("%s: required key %q is missing", name, key)
+			}
+		}
+	}
+
+	// Every key of the json output format is described by the schema
+	symbols := []Symbol{
+		{Name: "Shape", Kind: "interface", StartLine: 1, EndLine: 3, StartColumn: 1, EndColumn: 2, FilePath: "/x/a.go", Cell: &NotebookCell{Index: 1}},
+		{Name: "Area", Kind: "method", StartLine: 2, EndLine: 2, StartColumn: 2, EndColumn: 10, FilePath: "/x/a.go",
+			Coverage: &Coverage{Covered: 1, Total: 1}, Cell: &NotebookCell{Index: 1}, Annotations: []string{"@x"}, Deprecated: true, Receiver: "T", ID: "x"},
+	}
+	result := &ExtractionResult{Status: StatusOK, Files: 1, Symbols: 2, Warnings: []string{"w"}, NextCursor: "c"}
+	metadata := map[string]*FileMetadata{"/x/a.go": {Language: "go", Package: "x", Lines: 3, Hash: "h", ParseErrors: []LineRange{{Start: 1, End: 1}}}}
+	if err := result.setJSONOutput(symbols, metadata, map[string]int{"x": 5}); err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(result.Output), &doc); err != nil {
+		t.Fatal(err)
+	}
+	checkDescribed(t, "outline", doc, schema.Defs["outline"].Properties)
+	file := doc["outline"].([]any)[0].(map[string]any)
+	checkDescribed(t, "file", file, schema.Defs["file"].Properties)
+	shape := file["symbols"].([]any)[0].(map[string]any)
+	checkDescribed(t, "symbol", shape, schema.Defs["symbol"].Properties)
+	checkDescribed(t, "symbol", shape["children"].([]any)[0].(map[string]any), schema.Defs["symbol"].Properties)
+}
+
+// checkDescribed reports keys of a JSON object missing from the schema properties
+func checkDescribed(t *testing.T, name string, object map[string]any, properties map[string]json.RawMessage) {
+	t.Helper()
+	for key := range object {
+		if _, ok := properties[key]; !ok {
+			t.Errorf("%s: key %q is not described by the schema", name, key)
+		}
+	}
+}
+
+func TestToolOutputSchema(t *testing.T) {
+	var schema struct {
+		Ref  string                     `json:"$ref"`
+		Type string                     `json:"type"`
+		ID   string                     `json:"$id"`
+		Defs map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(toolOutputSchema("outline"), &schema); err != nil {
+		t.Fatalf("tool output schema is not valid JSON: %v", err)
+	}
+	if schema.Ref != "#/$defs/outline" || schema.Type != "object" || schema.ID != "" {
+		t.Errorf("schema root = %+v, want an object referencing the outline definition", schema)
+	}
+	if _, ok := schema.Defs["outline"]; !ok || len(schema.Defs) < 2 {
+		t.Errorf("Expected the schema's definitions to be kept, got %v", schema.Defs)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// shutdownGracePeriod is how long in-flight tool calls may keep running after
+// SIGINT or SIGTERM before they are cancelled
+const shutdownGracePeriod = 10 * time.Second
+
+// errStoppedReading is the cause the stdio transport cancels its requests with once
+// it stops reading input, which doesn't abort the calls in flight
+var errStoppedReading = errors.New("stopped reading input")
+
+// requestTracker counts in-flight tool calls so a shutdown can wait for them,
+// and rejects new calls once the shutdown has begun. It also bounds how many
+// calls run at once.
+type requestTracker struct {
+	mu       sync.Mutex
+	active   int
+	draining bool
+	drained  chan struct{}
+	// slots holds a token for every call allowed to run at once
+	slots chan struct{}
+	// ctx is cancelled to abort in-flight calls when the grace period runs out
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newRequestTracker creates a tracker running at most maxConcurrent calls at once
+func newRequestTracker(maxConcurrent int) *requestTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &requestTracker{
+		drained: make(chan struct{}),
+		slots:   make(chan struct{}, max(maxConcurrent, 1)),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// begin registers a tool call, reporting false once the shutdown has begun
+func (t *requestTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.active++
+	return true
+}
+
+// end unregisters a tool call registered with begin
+func (t *requestTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	if t.draining && t.active == 0 {
+		close(t.drained)
+	}
+}
+
+// drain stops new tool calls and returns a channel closed once no call is in flight
+func (t *requestTracker) drain() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.draining {
+		t.draining = true
+		if t.active == 0 {
+			close(t.drained)
+		}
+	}
+	return t.drained
+}
+
+// wrap tracks the calls of a tool handler, making calls beyond the concurrency
+// limit wait for a free slot. Calls see a context that is cancelled with their
+// request, such as when an HTTP client disconnects, or when the tracker aborts
+// in-flight calls, but not when input stops being read, so a call can finish and
+// have its response written during a shutdown.
+func (t *requestTracker) wrap(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, release, err := t.acquire(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer release()
+
+		return handler(ctx, request)
+	}
+}
+
+// wrapResource tracks the reads of a resource handler like wrap tracks tool calls
+func (t *requestTracker) wrapResource(handler server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		ctx, release, err := t.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return handler(ctx, request)
+	}
+}
+
+// acquire registers a call and waits for a free slot, returning the context the
+// call runs with and a function releasing the slot once the call is done
+func (t *requestTracker) acquire(ctx context.Context) (context.Context, func(), error) {
+	if !t.begin() {
+		return nil, nil, errors.New("server is shutting down")
+	}
+
+	request := ctx
+	ctx, cancel := context.WithCancel(context.WithoutCancel(request))
+	stopAbort := context.AfterFunc(t.ctx, cancel)
+	stopRequest := context.AfterFunc(request, func() {
+		if !errors.Is(context.Cause(request), errStoppedReading) {
+			cancel()
+		}
+	})
+	stop := func() {
+		stopAbort()
+		stopRequest()
+	}
+
+	select {
+	case t.slots <- struct{}{}:
+		return ctx, func() {
+			<-t.slots
+			stop()
+			cancel()
+			t.end()
+		}, nil
+	case <-ctx.Done():
+		stop()
+		cancel()
+		t.end()
+		return nil, nil, errors.New("cancelled while waiting for a free extraction slot")
+	}
+}
+
+// serveStdio serves MCP over stdin and stdout until stdin is closed or the process
+// receives SIGINT or SIGTERM. On a signal it stops accepting tool calls, lets the
+// calls in flight finish for up to grace before cancelling them, and returns once
+// their responses have been written. A second signal exits immediately.
+func serveStdio(mcpServer *server.MCPServer, tracker *requestTracker, subs *outlineSubscriptions, grace time.Duration) error {
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listen, stopListening := context.WithCancelCause(context.Background())
+	defer stopListening(errStoppedReading)
+
+	go func() {
+		select {
+		case <-signals.Done():
+		case <-listen.Done():
+			return
+		}
+		// Restore the default behavior, so a second signal terminates the process
+		stop()
+		fmt.Fprintln(os.Stderr, "Shutting down: waiting for in-flight requests")
+
+		drained := tracker.drain()
+		select {
+		case <-drained:
+		case <-time.After(grace):
+			fmt.Fprintln(os.Stderr, "Shutting down: cancelling in-flight requests")
+			tracker.cancel()
+			<-drained
+		}
+		stopListening(errStoppedReading)
+	}()
+
+	stdio := server.NewStdioServer(mcpServer)
+	stdio.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+
+	// Subscription responses are written between the server's own messages
+	stdout := &lockedWriter{w: os.Stdout}
+	err := stdio.Listen(listen, subs.filterStdio(os.Stdin, stdout), stdout)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRequestTracker(t *testing.T) {
+	tracker := newRequestTracker(1)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := tracker.wrap(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		select {
+		case <-release:
+			return mcp.NewToolResultText("done"), nil
+		case <-ctx.Done():
+			return mcp.NewToolResultError(ctx.Err().Error()), nil
+		}
+	})
+
+	// Stopping to read input cancels the context the call arrived with, but doesn't abort it
+	listen, stopListening := context.WithCancelCause(context.Background())
+	results := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		result, _ := handler(listen, mcp.CallToolRequest{})
+		results <- result
+	}()
+	<-started
+	stopListening(errStoppedReading)
+
+	drained := tracker.drain()
+	select {
+	case <-drained:
+		t.Fatal("expected drain to wait for the in-flight call")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if result, _ := handler(context.Background(), mcp.CallToolRequest{}); !result.IsError {
+		t.Errorf("expected new calls to be rejected during shutdown")
+	}
+
+	close(release)
+	if result := <-results; result.IsError {
+		t.Errorf("expected the in-flight call to finish, got %+v", result)
+	}
+	<-drained
+}
+
+func TestRequestTracker_Cancel(t *testing.T) {
+	tracker := newRequestTracker(1)
+
+	handler := tracker.wrap(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tracker.cancel()
+		<-ctx.Done()
+		return mcp.NewToolResultError(ctx.Err().Error()), nil
+	})
+
+	result, _ := handler(context.Background(), mcp.CallToolRequest{})
+	if !result.IsError {
+		t.Errorf("expected the call to be cancelled, got %+v", result)
+	}
+}
+
+func TestRequestTracker_RequestCancelled(t *testing.T) {
+	tracker := newRequestTracker(1)
+
+	handler := tracker.wrap(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return mcp.NewToolResultError(ctx.Err().Error()), nil
+	})
+
+	// Cancelling the request for any other reason, such as a client disconnecting, aborts the call
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		result, _ := handler(ctx, mcp.CallToolRequest{})
+		results <- result
+	}()
+	cancel()
+	if result := <-results; !result.IsError {
+		t.Errorf("expected the call to be cancelled, got %+v", result)
+	}
+
+	// The call gave its slot back
+	_, release, err := tracker.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire error = %v", err)
+	}
+	release()
+}
+
+func TestExtractSymbolsContext_Cancelled(t *testing.T) {
+	testFile, err := filepath.Abs("testdata/java_basic_class.java.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ExtractSymbolsContext(ctx, testFile, ExtractOptions{Detail: Minimal}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRequestTracker_Concurrency(t *testing.T) {
+	tracker := newRequestTracker(2)
+
+	var running, peak int32
+	var mu sync.Mutex
+	handler := tracker.wrap(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler(context.Background(), mcp.CallToolRequest{})
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("expected at most 2 calls at once, got a peak of %d", peak)
+	}
+}
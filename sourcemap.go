@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// sourceMappingURLPattern matches the sourceMappingURL comment emitted by bundlers
+var sourceMappingURLPattern = regexp.MustCompile(`//[#@]\s*sourceMappingURL=(\S+)\s*$`)
+
+// SourceMap is a decoded revision 3 source map
+type SourceMap struct {
+	// Sources are the resolved paths of the original source files
+	Sources []string
+	// lines holds the mapping segments of each generated line, sorted by column
+	lines [][]sourceMapSegment
+	// generated holds the lines of the generated file, set by sourceMapFor
+	generated [][]byte
+}
+
+// sourceMapSegment maps a generated column to a position in an original source
+type sourceMapSegment struct {
+	genColumn  int
+	source     int
+	origLine   int
+	origColumn int
+}
+
+// sourceMapFile is the JSON representation of a source map
+type sourceMapFile struct {
+	Version    int      `json:"version"`
+	SourceRoot string   `json:"sourceRoot"`
+	Sources    []string `json:"sources"`
+	Mappings   string   `json:"mappings"`
+}
+
+// LoadSourceMap reads and decodes a source map file
+func LoadSourceMap(path string) (*SourceMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw sourceMapFile
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid source map %s: %w", path, err)
+	}
+	if raw.Version != 3 {
+		return nil, fmt.Errorf("unsupported source map version %d in %s", raw.Version, path)
+	}
+
+	m := &SourceMap{}
+	dir := filepath.Dir(path)
+	for _, source := range raw.Sources {
+		m.Sources = append(m.Sources, resolveSourcePath(dir, raw.SourceRoot, source))
+	}
+
+	m.lines, err = decodeMappings(raw.Mappings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mappings in %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// resolveSourcePath resolves a source entry relative to the source map location
+func resolveSourcePath(dir, sourceRoot, source string) string {
+	if sourceRoot != "" && !strings.Contains(source, "://") && !filepath.IsAbs(source) {
+		source = strings.TrimSuffix(sourceRoot, "/") + "/" + source
+	}
+
+	// Strip URL schemes such as file:// and webpack://
+	if i := strings.Index(source, "://"); i >= 0 {
+		if u, err := url.Parse(source); err == nil && u.Scheme == "file" {
+			return filepath.Clean(u.Path)
+		}
+		source = strings.TrimLeft(source[i+3:], "/")
+	}
+
+	if filepath.IsAbs(source) {
+		return filepath.Clean(source)
+	}
+	return filepath.Join(dir, filepath.FromSlash(source))
+}
+
+// decodeMappings decodes the base64 VLQ mappings string of a source map
+func decodeMappings(mappings string) ([][]sourceMapSegment, error) {
+	var lines [][]sourceMapSegment
+	var source, origLine, origColumn int
+
+	for _, line := range strings.Split(mappings, ";") {
+		var segments []sourceMapSegment
+		genColumn := 0
+
+		for _, group := range strings.Split(line, ",") {
+			if group == "" {
+				continue
+			}
+
+			fields, err := decodeVLQ(group)
+			if err != nil {
+				return nil, err
+			}
+
+			genColumn += fields[0]
+			// Segments with a single field have no original position
+			if len(fields) < 4 {
+				continue
+			}
+			source += fields[1]
+			origLine += fields[2]
+			origColumn += fields[3]
+
+			segments = append(segments, sourceMapSegment{
+				genColumn:  genColumn,
+				source:     source,
+				origLine:   origLine,
+				origColumn: origColumn,
+			})
+		}
+
+		sort.SliceStable(segments, func(i, j int) bool { return segments[i].genColumn < segments[j].genColumn })
+		lines = append(lines, segments)
+	}
+
+	return lines, nil
+}
+
+// decodeVLQ decodes a group of base64 VLQ values
+func decodeVLQ(group string) ([]int, error) {
+	const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+	var values []int
+	value, shift := 0, 0
+
+	for _, c := range group {
+		digit := strings.IndexRune(base64Chars, c)
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base64 VLQ character %q", c)
+		}
+
+		value += (digit & 31) << shift
+		if digit&32 != 0 {
+			shift += 5
+			continue
+		}
+
+		if value&1 != 0 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+		value, shift = 0, 0
+	}
+
+	if shift != 0 {
+		return nil, fmt.Errorf("truncated base64 VLQ group %q", group)
+	}
+	return values, nil
+}
+
+// OriginalPosition maps a 1-based generated line and column to the original source.
+// It uses the last segment at or before the column, or the first one on the line.
+func (m *SourceMap) OriginalPosition(line, column uint32) (source string, origLine, origColumn uint32, ok bool) {
+	if line == 0 || int(line) > len(m.lines) {
+		return "", 0, 0, false
+	}
+
+	segments := m.lines[line-1]
+	if len(segments) == 0 {
+		return "", 0, 0, false
+	}
+
+	seg := segments[0]
+	for _, s := range segments {
+		if s.genColumn > int(column)-1 {
+			break
+		}
+		seg = s
+	}
+
+	if seg.source < 0 || seg.source >= len(m.Sources) {
+		return "", 0, 0, false
+	}
+	return m.Sources[seg.source], uint32(seg.origLine) + 1, uint32(seg.origColumn) + 1, true
+}
+
+// findSourceMap returns the source map for a generated file, if one exists inside
+// roots. The sourceMappingURL comment takes precedence over an adjacent .map file.
+func findSourceMap(filePath string, content []byte, roots []string) string {
+	tail := content
+	if len(tail) > 4096 {
+		tail = tail[len(tail)-4096:]
+	}
+	lines := bytes.Split(bytes.TrimRight(tail, "\n\r\t "), []byte("\n"))
+	if match := sourceMappingURLPattern.FindSubmatch(lines[len(lines)-1]); match != nil {
+		ref := string(match[1])
+		if !strings.HasPrefix(ref, "data:") && !strings.Contains(ref, "://") {
+			candidate := filepath.Join(filepath.Dir(filePath), filepath.FromSlash(ref))
+			if _, err := os.Stat(candidate); err == nil && withinRoots(candidate, roots) {
+				return candidate
+			}
+		}
+	}
+
+	if _, err := os.Stat(filePath + ".map"); err == nil && withinRoots(filePath+".map", roots) {
+		return filePath + ".map"
+	}
+	return ""
+}
+
+// sourceMapFor loads the source map of a generated file with the given content, or
+// returns nil if the file has none inside roots or it can't be loaded
+func sourceMapFor(filePath string, content []byte, roots []string) *SourceMap {
+	mapPath := findSourceMap(filePath, content, roots)
+	if mapPath == "" {
+		return nil
+	}
+	m, err := LoadSourceMap(mapPath)
+	if err != nil {
+		return nil
+	}
+	m.generated = bytes.Split(content, []byte("\n"))
+	return m
+}
+
+// utf16Column converts a 1-based column counted in runes on a 1-based line into the
+// UTF-16 code units source maps count columns in
+func utf16Column(lines [][]byte, line, column uint32) uint32 {
+	if line == 0 || int(line) > len(lines) {
+		return column
+	}
+	units, runes := uint32(1), uint32(1)
+	for _, r := range string(lines[line-1]) {
+		if runes >= column {
+			break
+		}
+		units += uint32(utf16.RuneLen(r))
+		runes++
+	}
+	return units + column - runes
+}
+
+// runeColumnOf converts a 1-based column counted in UTF-16 code units on a 1-based
+// line back into runes
+func runeColumnOf(lines [][]byte, line, column uint32) uint32 {
+	if line == 0 || int(line) > len(lines) {
+		return column
+	}
+	units, runes := uint32(1), uint32(1)
+	for _, r := range string(lines[line-1]) {
+		if units >= column {
+			break
+		}
+		units += uint32(utf16.RuneLen(r))
+		runes++
+	}
+	if units > column {
+		return runes
+	}
+	return runes + column - units
+}
+
+// isGeneratedJavaScript reports whether a file may have been produced by a bundler or compiler
+func isGeneratedJavaScript(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".js", ".mjs", ".cjs":
+		return true
+	}
+	return false
+}
+
+// ApplySourceMaps rewrites the positions of symbols in generated JavaScript files
+// that have a source map in maps, keyed by the generated file, to the corresponding
+// locations in the original sources. Symbols that cannot be mapped, or whose
+// original source lies outside roots, keep their generated positions. A mapped
+// source without metadata of its own takes that of the file generated from it.
+func ApplySourceMaps(symbols []Symbol, maps map[string]*SourceMap, metadata map[string]*FileMetadata, roots []string) {
+	// The original sources are read to count their columns in runes
+	sources := make(map[string][][]byte)
+
+	for i := range symbols {
+		sym := &symbols[i]
+		m := maps[sym.FilePath]
+		if m == nil {
+			continue
+		}
+
+		source, startLine, startColumn, ok := m.OriginalPosition(sym.StartLine, utf16Column(m.generated, sym.StartLine, sym.StartColumn))
+		if !ok || !withinRoots(source, roots) {
+			continue
+		}
+
+		endLine, endColumn := startLine, startColumn
+		if endSource, line, column, ok := m.OriginalPosition(sym.EndLine, utf16Column(m.generated, sym.EndLine, sym.EndColumn)); ok && endSource == source && line >= startLine {
+			endLine, endColumn = line, column
+		}
+
+		lines, seen := sources[source]
+		if !seen {
+			if content, err := ReadFile(source); err == nil {
+				lines = bytes.Split(content, []byte("\n"))
+			}
+			sources[source] = lines
+		}
+
+		if meta, ok := metadata[sym.FilePath]; ok && metadata[source] == nil {
+			metadata[source] = meta
+		}
+		sym.FilePath = source
+		sym.StartLine, sym.StartColumn = startLine, runeColumnOf(lines, startLine, startColumn)
+		sym.EndLine, sym.EndColumn = endLine, runeColumnOf(lines, endLine, endColumn)
+	}
+}
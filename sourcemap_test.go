@@ -0,0 +1,197 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecodeVLQ(t *testing.T) {
+	tests := []struct {
+		group string
+		want  []int
+	}{
+		{"AAAA", []int{0, 0, 0, 0}},
+		{"AACA", []int{0, 0, 1, 0}},
+		{"SAAD", []int{9, 0, 0, -1}},
+		{"gBAAA", []int{16, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			got, err := decodeVLQ(tt.group)
+			if err != nil {
+				t.Fatalf("decodeVLQ(%q) error = %v", tt.group, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("decodeVLQ(%q) = %v, want %v", tt.group, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("decodeVLQ(%q) = %v, want %v", tt.group, got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := decodeVLQ("g"); err == nil {
+		t.Errorf("Expected error for truncated VLQ group")
+	}
+}
+
+func TestApplySourceMaps(t *testing.T) {
+	testDir := t.TempDir()
+	distDir := filepath.Join(testDir, "dist")
+	if err := os.MkdirAll(distDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	generated := "function add(a, b) {\n  return a + b;\n}\n//# sourceMappingURL=bundle.js.map\n"
+	// Generated lines 1-3 map to lines 10-12 of ../src/math.ts
+	sourceMap := `{"version":3,"sources":["../src/math.ts"],"mappings":"AASA;AACA;AACA"}`
+
+	jsFile := filepath.Join(distDir, "bundle.js")
+	if err := os.WriteFile(jsFile, []byte(generated), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(distDir, "bundle.js.map"), []byte(sourceMap), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plainFile := filepath.Join(distDir, "plain.js")
+	if err := os.WriteFile(plainFile, []byte("function sub(a, b) {\n  return a - b;\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	extractor := NewSymbolExtractor()
+	var symbols []Symbol
+	maps := make(map[string]*SourceMap)
+	for _, file := range []string{jsFile, plainFile} {
+		syms, err := extractor.ExtractFromFile(file, Standard)
+		if err != nil {
+			t.Fatalf("ExtractFromFile error = %v", err)
+		}
+		symbols = append(symbols, syms...)
+		if m := sourceMapFor(file, extractor.content, nil); m != nil {
+			maps[file] = m
+		}
+	}
+
+	metadata := map[string]*FileMetadata{jsFile: {Language: "javascript"}, plainFile: {Language: "javascript"}}
+	ApplySourceMaps(symbols, maps, metadata, nil)
+
+	original := filepath.Join(testDir, "src", "math.ts")
+	for _, sym := range symbols {
+		switch sym.Name {
+		case "add":
+			if sym.FilePath != original || sym.StartLine != 10 || sym.EndLine != 12 {
+				t.Errorf("Expected add to map to %s:10-12, got %s:%d-%d", original, sym.FilePath, sym.StartLine, sym.EndLine)
+			}
+		case "sub":
+			if sym.FilePath != plainFile || sym.StartLine != 1 {
+				t.Errorf("Expected sub to keep its generated position, got %s:%d", sym.FilePath, sym.StartLine)
+			}
+		}
+	}
+
+	result := FormatSymbols(symbols, Minimal)
+	if !strings.Contains(result, "## "+original) {
+		t.Errorf("Expected output to reference the original source.\nResult:\n%s", result)
+	}
+	if metadata[original] != metadata[jsFile] {
+		t.Errorf("Expected %s to take the metadata of bundle.js, got %+v", original, metadata[original])
+	}
+}
+
+func TestApplySourceMaps_UTF16Columns(t *testing.T) {
+	testDir := t.TempDir()
+
+	// Source map columns count UTF-16 code units, so each emoji counts twice
+	generated := "var s = \"\U0001F600\U0001F600\"; function add(a, b) {\n  return a + b;\n}\n//# sourceMappingURL=bundle.js.map\n"
+	original := "// math\n\n\n\n/*\U0001F600*/ function add(a: number, b: number): number {\n  return a + b;\n}\n"
+	// Generated column 16 of line 1 maps to line 5, column 7 of math.ts
+	sourceMap := `{"version":3,"sources":["math.ts"],"mappings":"AAAA,gBAIO;AACP;AACA"}`
+
+	jsFile := filepath.Join(testDir, "bundle.js")
+	for name, content := range map[string]string{"bundle.js": generated, "bundle.js.map": sourceMap, "math.ts": original} {
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	extractor := NewSymbolExtractor()
+	symbols, err := extractor.ExtractFromFile(jsFile, Standard)
+	if err != nil {
+		t.Fatalf("ExtractFromFile error = %v", err)
+	}
+	m := sourceMapFor(jsFile, extractor.content, nil)
+	if m == nil {
+		t.Fatal("Expected the source map of bundle.js")
+	}
+	ApplySourceMaps(symbols, map[string]*SourceMap{jsFile: m}, nil, nil)
+
+	for _, sym := range symbols {
+		if sym.Name != "add" {
+			continue
+		}
+		if sym.FilePath != filepath.Join(testDir, "math.ts") || sym.StartLine != 5 || sym.StartColumn != 7 || sym.EndLine != 7 {
+			t.Errorf("Expected add to map to math.ts:5:7-7, got %s:%d:%d-%d", sym.FilePath, sym.StartLine, sym.StartColumn, sym.EndLine)
+		}
+		return
+	}
+	t.Fatalf("Expected a symbol named add, got %+v", symbols)
+}
+
+func TestApplySourceMaps_Roots(t *testing.T) {
+	testDir := t.TempDir()
+	root := filepath.Join(testDir, "root")
+	outside := filepath.Join(testDir, "outside")
+	for _, dir := range []string{root, outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := map[string]string{
+		// The comment leads out of the root to a map that would be read otherwise
+		filepath.Join(root, "escape.js"):        "function escape() {}\n//# sourceMappingURL=../outside/escape.js.map\n",
+		filepath.Join(outside, "escape.js.map"): `{"version":3,"sources":["secret.ts"],"mappings":"AAAA"}`,
+		filepath.Join(root, "sources.js"):       "function sources() {}\n//# sourceMappingURL=sources.js.map\n",
+		filepath.Join(root, "sources.js.map"):   `{"version":3,"sources":["../outside/secret.ts"],"mappings":"AAAA"}`,
+		filepath.Join(outside, "secret.ts"):     "function secret() {}\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	roots := []string{resolveSymlinks(root)}
+	escape := filepath.Join(root, "escape.js")
+	content, err := os.ReadFile(escape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := sourceMapFor(escape, content, roots); m != nil {
+		t.Errorf("Expected no source map inside the roots for escape.js, got %+v", m)
+	}
+	if m := sourceMapFor(escape, content, nil); m == nil {
+		t.Error("Expected the source map of escape.js without roots")
+	}
+
+	// A map inside the roots doesn't lead to sources outside them
+	sources := filepath.Join(root, "sources.js")
+	content, err = os.ReadFile(sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := sourceMapFor(sources, content, roots)
+	if m == nil {
+		t.Fatal("Expected the source map of sources.js")
+	}
+	symbols := []Symbol{{Name: "sources", Kind: "function", FilePath: sources, StartLine: 1, EndLine: 1, StartColumn: 1, EndColumn: 22}}
+	ApplySourceMaps(symbols, map[string]*SourceMap{sources: m}, nil, roots)
+	if symbols[0].FilePath != sources || symbols[0].StartLine != 1 {
+		t.Errorf("Expected sources to keep its generated position, got %s:%d", symbols[0].FilePath, symbols[0].StartLine)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Patterns used to classify string literals
+var (
+	sqlPattern = regexp.MustCompile(`(?is)^\s*(select\s.+\sfrom\s|insert\s+into\s|update\s+\S+\s+set\s|delete\s+from\s|create\s+(table|index|unique\s+index|view)\s|alter\s+table\s|drop\s+(table|index|view)\s|with\s+\w+\s+as\s*\()`)
+	urlPattern = regexp.MustCompile(`(?i)^[a-z][a-z0-9+.\-]*://\S+$`)
+	// regexCallPattern matches calls that take a regular expression as an argument
+	regexCallPattern = regexp.MustCompile(`(?i)(regexp\.(must)?compile\w*|\bre\.(compile|match|fullmatch|search|sub|subn|split|findall|finditer)|pattern\.(compile|matches)|\bregexp?\b|\.(matches|replaceall|replacefirst)\s*$)`)
+)
+
+// maxStringLiteralLength caps the length of literal values shown in the output
+const maxStringLiteralLength = 200
+
+// ExtractStringsFromFile extracts notable string literals from a single file
+func (e *SymbolExtractor) ExtractStringsFromFile(filePath string) ([]StringLiteral, error) {
+	tree, content, langQueries, err := e.parseFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if langQueries.Strings == "" {
+		return nil, nil
+	}
+
+	// Symbols are used to find the enclosing symbol of each literal
+	symbols, err := e.extractSymbolsFromTree(tree, content, filePath, langQueries, Minimal)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := sitter.NewQuery([]byte(langQueries.Strings), langQueries.Language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create string query: %w", err)
+	}
+
+	cursor := sitter.NewQueryCursor()
+	cursor.Exec(query, tree.RootNode())
+
+	var literals []StringLiteral
+
+	for {
+		match, ok := cursor.NextMatch()
+		if !ok {
+			break
+		}
+
+		for _, capture := range match.Captures {
+			literal, ok := classifyStringLiteral(capture.Node, content)
+			if !ok {
+				continue
+			}
+			literal.FilePath = filePath
+			literal.Line = capture.Node.StartPoint().Row + 1
+			if enclosing := findEnclosingSymbol(symbols, literal.Line); enclosing != nil {
+				literal.Enclosing = fmt.Sprintf("%s: %s", enclosing.Kind, enclosing.Name)
+			}
+			literals = append(literals, literal)
+		}
+	}
+
+	return literals, nil
+}
+
+// classifyStringLiteral decides whether a literal node is notable and what kind it is
+func classifyStringLiteral(node *sitter.Node, content []byte) (StringLiteral, bool) {
+	text := string(content[node.StartByte():node.EndByte()])
+
+	if node.Type() == "regex" {
+		if pattern := node.ChildByFieldName("pattern"); pattern != nil {
+			text = string(content[pattern.StartByte():pattern.EndByte()])
+		}
+		return StringLiteral{Kind: "regex", Value: text}, true
+	}
+
+	value := unquoteLiteral(text)
+	if strings.TrimSpace(value) == "" {
+		return StringLiteral{}, false
+	}
+
+	var kind string
+	switch {
+	case sqlPattern.MatchString(value):
+		kind = "sql"
+	case urlPattern.MatchString(strings.TrimSpace(value)):
+		kind = "url"
+	case isRegexArgument(node, content):
+		kind = "regex"
+	case hasInterpolation(node) || strings.Contains(value, "{{") && strings.Contains(value, "}}"):
+		kind = "template"
+	default:
+		return StringLiteral{}, false
+	}
+
+	return StringLiteral{Kind: kind, Value: value}, true
+}
+
+// unquoteLiteral strips string prefixes and quote delimiters from a literal
+func unquoteLiteral(text string) string {
+	// Python string prefixes such as r, b, f or rb
+	text = strings.TrimLeft(text, "rRbBfFuU")
+
+	for _, quote := range []string{`"""`, `'''`, `"`, `'`, "`"} {
+		if len(text) >= 2*len(quote) && strings.HasPrefix(text, quote) && strings.HasSuffix(text, quote) {
+			return text[len(quote) : len(text)-len(quote)]
+		}
+	}
+	return text
+}
+
+// hasInterpolation reports whether a literal contains template substitutions
+func hasInterpolation(node *sitter.Node) bool {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		switch node.NamedChild(i).Type() {
+		case "template_substitution", "interpolation":
+			return true
+		}
+	}
+	return false
+}
+
+// isRegexArgument reports whether a literal is passed directly to a regex constructor or matcher
+func isRegexArgument(node *sitter.Node, content []byte) bool {
+	args := node.Parent()
+	if args == nil {
+		return false
+	}
+	switch args.Type() {
+	case "argument_list", "arguments":
+	default:
+		return false
+	}
+
+	call := args.Parent()
+	if call == nil || call.StartByte() >= args.StartByte() {
+		return false
+	}
+
+	callee := string(content[call.StartByte():args.StartByte()])
+	return regexCallPattern.MatchString(strings.TrimSpace(callee))
+}
+
+// findEnclosingSymbol returns the innermost symbol whose line range contains the line
+func findEnclosingSymbol(symbols []Symbol, line uint32) *Symbol {
+	var enclosing *Symbol
+	for i := range symbols {
+		sym := &symbols[i]
+		if line < sym.StartLine || line > sym.EndLine {
+			continue
+		}
+		if enclosing == nil || sym.EndLine-sym.StartLine < enclosing.EndLine-enclosing.StartLine {
+			enclosing = sym
+		}
+	}
+	return enclosing
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractStringsFromFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		code     string
+		expected []StringLiteral
+	}{
+		{
+			name: "Go",
+			file: "repo.go",
+			code: "package repo\n\nimport \"regexp\"\n\nvar idPattern = regexp.MustCompile(`^[a-z]+\\d*$`)\n\nconst endpoint = \"https://api.example.com/v1/users\"\n\nfunc GetUser(id int) string {\n\tquery := `SELECT id, name\n\t\tFROM users WHERE id = $1`\n\treturn query\n}\n\nfunc Greeting() string {\n\treturn \"hello\"\n}\n",
+			expected: []StringLiteral{
+				{Kind: "regex", Line: 5, Enclosing: "var: idPattern"},
+				{Kind: "url", Line: 7, Enclosing: "const: endpoint"},
+				{Kind: "sql", Line: 10, Enclosing: "func: GetUser"},
+			},
+		},
+		{
+			name: "Python",
+			file: "service.py",
+			code: "import re\n\ndef fetch(user):\n    url = f\"https://example.com/users/{user}\"\n    return re.match(r\"^\\d+$\", user)\n\nGREETING = f\"hello {name}\"\n",
+			expected: []StringLiteral{
+				{Kind: "url", Line: 4, Enclosing: "var: url"},
+				{Kind: "regex", Line: 5, Enclosing: "func: fetch"},
+				{Kind: "template", Line: 7, Enclosing: "var: GREETING"},
+			},
+		},
+		{
+			name: "JavaScript",
+			file: "client.js",
+			code: "function render(name) {\n    return `<b>${name}</b>`;\n}\n\nfunction valid(s) {\n    return /^[a-z]+$/.test(s);\n}\n",
+			expected: []StringLiteral{
+				{Kind: "template", Line: 2, Enclosing: "func: render"},
+				{Kind: "regex", Line: 6, Enclosing: "func: valid"},
+			},
+		},
+		{
+			name: "Java",
+			file: "Repo.java",
+			code: "class Repo {\n    void delete() {\n        db.execute(\"DELETE FROM users WHERE id = ?\");\n        Pattern.compile(\"[0-9]+\");\n    }\n}\n",
+			expected: []StringLiteral{
+				{Kind: "sql", Line: 3, Enclosing: "method: delete"},
+				{Kind: "regex", Line: 4, Enclosing: "method: delete"},
+			},
+		},
+	}
+
+	extractor := NewSymbolExtractor()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(testFile, []byte(tt.code), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			literals, err := extractor.ExtractStringsFromFile(testFile)
+			if err != nil {
+				t.Fatalf("ExtractStringsFromFile error = %v", err)
+			}
+
+			if len(literals) != len(tt.expected) {
+				t.Fatalf("Expected %d literals, got %d: %+v", len(tt.expected), len(literals), literals)
+			}
+
+			for _, expected := range tt.expected {
+				found := false
+				for _, lit := range literals {
+					if lit.Kind == expected.Kind && lit.Line == expected.Line && lit.Enclosing == expected.Enclosing {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected %s literal on line %d in %q not found in %+v",
+						expected.Kind, expected.Line, expected.Enclosing, literals)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatStringLiterals(t *testing.T) {
+	literals := []StringLiteral{
+		{Kind: "url", Value: "https://example.com", Line: 7, FilePath: "/src/b.go"},
+		{Kind: "sql", Value: "SELECT *\n\tFROM users", Line: 3, FilePath: "/src/a.go", Enclosing: "func: load"},
+	}
+
+	result := FormatStringLiterals(literals)
+
+	expected := []string{
+		"# String Literals",
+		"- sql: `SELECT * FROM users` (line 3, in func: load)",
+		"- url: `https://example.com` (line 7)",
+	}
+	for _, e := range expected {
+		if !strings.Contains(result, e) {
+			t.Errorf("Expected result to contain %q.\nResult:\n%s", e, result)
+		}
+	}
+
+	if strings.Index(result, "/src/a.go") > strings.Index(result, "/src/b.go") {
+		t.Errorf("Expected files to be sorted.\nResult:\n%s", result)
+	}
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// stdioSessionID is the ID mcp-go gives the single session of the stdio transport
+const stdioSessionID = "stdio"
+
+// Methods of resource subscriptions, which mcp-go neither routes nor names
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+// subscriptionDebounce is how long a subscribed file must stay unchanged before
+// its outline is extracted again, so an editor's burst of writes is one update
+const subscriptionDebounce = 200 * time.Millisecond
+
+// subscriptionExpiry is how long the subscriptions of a session outlive its last
+// notification stream, so a client reconnecting its stream keeps them
+const subscriptionExpiry = time.Minute
+
+// maxSessionSubscriptions is the most outline resources one session may subscribe to
+const maxSessionSubscriptions = 256
+
+// maxHTTPMessageBytes bounds the body of a message posted to the HTTP transport
+const maxHTTPMessageBytes = 32 << 20
+
+// outlineSubscriptions tracks the sessions subscribed to file outline resources and
+// watches the subscribed files, notifying their subscribers with
+// notifications/resources/updated when an outline changes. Writes that leave the
+// symbols unchanged are not reported. mcp-go does not route resources/subscribe
+// and resources/unsubscribe, so the transports pass them to handleMessage.
+type outlineSubscriptions struct {
+	watcher *fsnotify.Watcher
+	// tracker bounds and drains subscription requests along with tool calls
+	tracker *requestTracker
+	// fingerprint returns a digest of the symbols of a resource, which changes
+	// with them but not with the rest of the file
+	fingerprint func(ctx context.Context, uri string) (string, error)
+	// notify sends a resources/updated notification to a session
+	notify        func(sessionID, uri string)
+	debounce      time.Duration
+	expiry        time.Duration
+	maxPerSession int
+
+	mu   sync.Mutex
+	subs map[string]*outlineSubscription
+	// dirs counts the subscriptions in each watched directory. Directories are
+	// watched rather than files, so editors that save by renaming a new file over
+	// the old one keep being followed.
+	dirs map[string]int
+	// counts counts the subscriptions of each session
+	counts map[string]int
+	// streams counts the open notification streams of each session, as mcp-go
+	// registers and unregisters them
+	streams map[string]int
+	// expiries end the subscriptions of sessions left without a stream
+	expiries map[string]*time.Timer
+}
+
+// outlineSubscription is the subscribers of one outline resource
+type outlineSubscription struct {
+	path     string
+	sessions map[string]bool
+	// fingerprint is the fingerprint of the symbols last reported to the subscribers
+	fingerprint string
+	timer       *time.Timer
+}
+
+// newOutlineSubscriptions creates the subscriptions of a server and starts watching
+// for file changes until Close is called
+func newOutlineSubscriptions(tracker *requestTracker, fingerprint func(ctx context.Context, uri string) (string, error), notify func(sessionID, uri string)) (*outlineSubscriptions, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch files: %w", err)
+	}
+	s := &outlineSubscriptions{
+		watcher:       watcher,
+		tracker:       tracker,
+		fingerprint:   fingerprint,
+		notify:        notify,
+		debounce:      subscriptionDebounce,
+		expiry:        subscriptionExpiry,
+		maxPerSession: maxSessionSubscriptions,
+		subs:          make(map[string]*outlineSubscription),
+		dirs:          make(map[string]int),
+		counts:        make(map[string]int),
+		streams:       make(map[string]int),
+		expiries:      make(map[string]*time.Timer),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Close stops watching files
+func (s *outlineSubscriptions) Close() error {
+	s.mu.Lock()
+	for _, sub := range s.subs {
+		if sub.timer != nil {
+			sub.timer.Stop()
+		}
+	}
+	for _, timer := range s.expiries {
+		timer.Stop()
+	}
+	s.mu.Unlock()
+	return s.watcher.Close()
+}
+
+// subscribe subscribes a session to an outline resource
+func (s *outlineSubscriptions) subscribe(ctx context.Context, sessionID, uri string) error {
+	path, _, err := parseOutlineURI(uri)
+	if err != nil {
+		return err
+	}
+	// The file must be outlined now, both to reject URIs that name no file and to
+	// tell later writes that change the symbols from those that don't
+	fingerprint, err := s.fingerprint(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subs[uri]
+	if ok && sub.sessions[sessionID] {
+		return nil
+	}
+	if s.counts[sessionID] >= s.maxPerSession {
+		return fmt.Errorf("a session may subscribe to at most %d resources", s.maxPerSession)
+	}
+	if ok {
+		sub.sessions[sessionID] = true
+	} else {
+		dir := filepath.Dir(path)
+		if s.dirs[dir] == 0 {
+			if err := s.watcher.Add(dir); err != nil {
+				return fmt.Errorf("failed to watch %s: %w", path, err)
+			}
+		}
+		s.dirs[dir]++
+		s.subs[uri] = &outlineSubscription{path: path, sessions: map[string]bool{sessionID: true}, fingerprint: fingerprint}
+	}
+	s.counts[sessionID]++
+	if s.streams[sessionID] == 0 {
+		s.expire(sessionID)
+	}
+	return nil
+}
+
+// unsubscribe ends the subscription of a session to an outline resource, and stops
+// watching its file once no session is subscribed
+func (s *outlineSubscriptions) unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sessionID, uri)
+}
+
+// unsubscribeSession ends every subscription of a session, such as one that was terminated
+func (s *outlineSubscriptions) unsubscribeSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeSession(sessionID)
+}
+
+// sessionRegistered records that a session opened a notification stream, which
+// keeps its subscriptions
+func (s *outlineSubscriptions) sessionRegistered(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams[sessionID]++
+	if timer, ok := s.expiries[sessionID]; ok {
+		timer.Stop()
+		delete(s.expiries, sessionID)
+	}
+}
+
+// sessionUnregistered records that a notification stream of a session closed. A
+// session left without one, such as a client that disconnected or crashed, keeps
+// its subscriptions for s.expiry in case it reconnects.
+func (s *outlineSubscriptions) sessionUnregistered(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.streams[sessionID]--; s.streams[sessionID] > 0 {
+		return
+	}
+	delete(s.streams, sessionID)
+	s.expire(sessionID)
+}
+
+// expire ends the subscriptions of a session without a notification stream once
+// s.expiry passes, unless it opens one first; s.mu must be held
+func (s *outlineSubscriptions) expire(sessionID string) {
+	if _, ok := s.expiries[sessionID]; ok || s.counts[sessionID] == 0 {
+		return
+	}
+	s.expiries[sessionID] = time.AfterFunc(s.expiry, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.streams[sessionID] == 0 {
+			s.removeSession(sessionID)
+		}
+	})
+}
+
+// removeSession ends every subscription of a session; s.mu must be held
+func (s *outlineSubscriptions) removeSession(sessionID string) {
+	for uri := range s.subs {
+		s.remove(sessionID, uri)
+	}
+	if timer, ok := s.expiries[sessionID]; ok {
+		timer.Stop()
+		delete(s.expiries, sessionID)
+	}
+}
+
+// remove ends a subscription; s.mu must be held
+func (s *outlineSubscriptions) remove(sessionID, uri string) {
+	sub, ok := s.subs[uri]
+	if !ok || !sub.sessions[sessionID] {
+		return
+	}
+	delete(sub.sessions, sessionID)
+	if s.counts[sessionID]--; s.counts[sessionID] == 0 {
+		delete(s.counts, sessionID)
+	}
+	if len(sub.sessions) > 0 {
+		return
+	}
+	if sub.timer != nil {
+		sub.timer.Stop()
+	}
+	delete(s.subs, uri)
+
+	dir := filepath.Dir(sub.path)
+	s.dirs[dir]--
+	if s.dirs[dir] == 0 {
+		delete(s.dirs, dir)
+		// The directory may already be gone
+		_ = s.watcher.Remove(dir)
+	}
+}
+
+// run schedules an update of the resources of every file that changes
+func (s *outlineSubscriptions) run() {
+	for {
+		select {
+		case event, ok := <-s.watcher.Events:
+			if !ok {
+				return
+			}
+			s.changed(filepath.Clean(event.Name))
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Warning: watching subscribed files: %v\n", err)
+		}
+	}
+}
+
+// changed schedules an update of the resources of a file once it stops changing
+func (s *outlineSubscriptions) changed(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for uri, sub := range s.subs {
+		if sub.path != path {
+			continue
+		}
+		if sub.timer != nil {
+			sub.timer.Reset(s.debounce)
+			continue
+		}
+		sub.timer = time.AfterFunc(s.debounce, func() { s.refresh(uri) })
+	}
+}
+
+// refresh extracts the symbols of a resource again and notifies its subscribers
+// if they changed. A file that can no longer be outlined, such as a deleted one,
+// has changed too.
+func (s *outlineSubscriptions) refresh(uri string) {
+	fingerprint, err := s.fingerprint(context.Background(), uri)
+	if err != nil {
+		fingerprint = ""
+	}
+
+	s.mu.Lock()
+	sub, ok := s.subs[uri]
+	if !ok || fingerprint == sub.fingerprint {
+		s.mu.Unlock()
+		return
+	}
+	sub.fingerprint = fingerprint
+	sessions := make([]string, 0, len(sub.sessions))
+	for sessionID := range sub.sessions {
+		sessions = append(sessions, sessionID)
+	}
+	s.mu.Unlock()
+
+	for _, sessionID := range sessions {
+		s.notify(sessionID, uri)
+	}
+}
+
+// subscriptionRequest is a resources/subscribe or resources/unsubscribe request
+type subscriptionRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		URI string `json:"uri"`
+	} `json:"params"`
+}
+
+// subscriptionResponse is the JSON-RPC response to a subscriptionRequest
+type subscriptionResponse struct {
+	JSONRPC string             `json:"jsonrpc"`
+	ID      json.RawMessage    `json:"id"`
+	Result  *struct{}          `json:"result,omitempty"`
+	Error   *subscriptionError `json:"error,omitempty"`
+}
+
+// subscriptionError is the error of a failed subscription request
+type subscriptionError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// parseSubscriptionRequest parses a resources/subscribe or resources/unsubscribe
+// request, reporting false for other messages, which are left to the MCP server
+func parseSubscriptionRequest(message []byte) (*subscriptionRequest, bool) {
+	var request subscriptionRequest
+	if err := json.Unmarshal(message, &request); err != nil || request.ID == nil {
+		return nil, false
+	}
+	if request.Method != methodResourcesSubscribe && request.Method != methodResourcesUnsubscribe {
+		return nil, false
+	}
+	return &request, true
+}
+
+// handleMessage answers a resources/subscribe or resources/unsubscribe request of a
+// session, returning the response and true. Other messages are left to the MCP
+// server. A session ID of "" means the transport has no sessions, which can't be
+// notified.
+func (s *outlineSubscriptions) handleMessage(ctx context.Context, sessionID string, message []byte) ([]byte, bool) {
+	request, ok := parseSubscriptionRequest(message)
+	if !ok {
+		return nil, false
+	}
+	return s.answer(ctx, sessionID, request), true
+}
+
+// answer returns the response to a subscription request of a session. Subscribing
+// outlines the resource, so it waits for a free slot and is drained on shutdown
+// like a tool call.
+func (s *outlineSubscriptions) answer(ctx context.Context, sessionID string, request *subscriptionRequest) []byte {
+	var err error
+	switch request.Method {
+	case methodResourcesSubscribe:
+		if sessionID == "" {
+			err = fmt.Errorf("resources/subscribe needs a session; the server is stateless")
+			break
+		}
+		var release func()
+		ctx, release, err = s.tracker.acquire(ctx)
+		if err == nil {
+			err = s.subscribe(ctx, sessionID, request.Params.URI)
+			release()
+		}
+	case methodResourcesUnsubscribe:
+		s.unsubscribe(sessionID, request.Params.URI)
+	}
+
+	response := subscriptionResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: request.ID, Result: &struct{}{}}
+	if err != nil {
+		response.Result = nil
+		response.Error = &subscriptionError{Code: mcp.INVALID_PARAMS, Message: err.Error()}
+	}
+	output, _ := json.Marshal(response)
+	return output
+}
+
+// filterStdio returns the input of the stdio transport with the subscription
+// requests of its session taken out. They are answered in the order they arrive,
+// but off the goroutine reading the input, so the other requests are not held up,
+// and their responses are written to w. The input ends once all are answered.
+func (s *outlineSubscriptions) filterStdio(r io.Reader, w io.Writer) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		// answered is closed once the last subscription request read is answered
+		answered := make(chan struct{})
+		close(answered)
+
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if request, ok := parseSubscriptionRequest(line); ok {
+					previous, done := answered, make(chan struct{})
+					answered = done
+					go func() {
+						defer close(done)
+						<-previous
+						_, _ = w.Write(append(s.answer(context.Background(), stdioSessionID, request), '\n'))
+					}()
+				} else if _, err := pw.Write(line); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				<-answered
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+// withSubscriptions answers the subscription requests posted to the HTTP transport
+// and ends the subscriptions of sessions the client terminates. Requests of
+// invalid or terminated sessions are left to handler to reject; with no session
+// manager the transport is stateless.
+func (s *outlineSubscriptions) withSubscriptions(handler http.Handler, sessions *signedSessionManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := ""
+		if sessions != nil {
+			sessionID = r.Header.Get("Mcp-Session-Id")
+		}
+		if sessionID != "" {
+			if terminated, err := sessions.Validate(sessionID); err != nil || terminated {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		switch r.Method {
+		case http.MethodPost:
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPMessageBytes))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+			if response, ok := s.handleMessage(r.Context(), sessionID, body); ok {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(response)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		case http.MethodDelete:
+			defer s.unsubscribeSession(sessionID)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// lockedWriter serializes the writes of the stdio transport and of subscription
+// responses, each of which is a whole message
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestSubscriptions creates subscriptions of real outlines whose notifications
+// are sent to the returned channel
+func newTestSubscriptions(t *testing.T) (*outlineSubscriptions, chan string) {
+	t.Helper()
+	handlers := &toolHandlers{}
+	updates := make(chan string, 16)
+	subs, err := newOutlineSubscriptions(newRequestTracker(4),
+		func(ctx context.Context, uri string) (string, error) {
+			return handlers.outlineText(ctx, uri, FormatCompact)
+		},
+		func(sessionID, uri string) { updates <- sessionID + " " + uri },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subs.debounce = 10 * time.Millisecond
+	t.Cleanup(func() { subs.Close() })
+	return subs, updates
+}
+
+func TestOutlineSubscriptions(t *testing.T) {
+	subs, updates := newTestSubscriptions(t)
+	path := filepath.Join(t.TempDir(), "server.go")
+	if err := os.WriteFile(path, []byte("package app\n\nfunc Start() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := outlineURIPrefix + filepath.ToSlash(path)
+
+	if err := subs.subscribe(context.Background(), "a", uri); err != nil {
+		t.Fatalf("subscribe error = %v", err)
+	}
+
+	// A write that leaves the symbols unchanged is not reported
+	if err := os.WriteFile(path, []byte("package app\n\nfunc Start() { _ = 1 }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case update := <-updates:
+		t.Fatalf("Expected no update for an unchanged outline, got %q", update)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if err := os.WriteFile(path, []byte("package app\n\nfunc Start() {}\n\nfunc Stop() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case update := <-updates:
+		if update != "a "+uri {
+			t.Errorf("update = %q, want %q", update, "a "+uri)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an update after the symbols changed")
+	}
+
+	subs.unsubscribeSession("a")
+	if len(subs.subs) != 0 || len(subs.dirs) != 0 {
+		t.Errorf("Expected no subscriptions or watched directories left, got %v and %v", subs.subs, subs.dirs)
+	}
+}
+
+func TestOutlineSubscriptions_HandleMessage(t *testing.T) {
+	subs, _ := newTestSubscriptions(t)
+	path := filepath.Join(t.TempDir(), "lib.py")
+	if err := os.WriteFile(path, []byte("def run():\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := outlineURIPrefix + filepath.ToSlash(path)
+
+	tests := []struct {
+		name      string
+		sessionID string
+		message   string
+		handled   bool
+		response  string
+	}{
+		{"subscribe", "s1", `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"` + uri + `"}}`, true, `{"jsonrpc":"2.0","id":1,"result":{}}`},
+		{"unsubscribe", "s1", `{"jsonrpc":"2.0","id":"2","method":"resources/unsubscribe","params":{"uri":"` + uri + `"}}`, true, `{"jsonrpc":"2.0","id":"2","result":{}}`},
+		{"invalid uri", "s1", `{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"file:///etc/passwd"}}`, true, `"code":-32602`},
+		{"stateless", "", `{"jsonrpc":"2.0","id":4,"method":"resources/subscribe","params":{"uri":"` + uri + `"}}`, true, `needs a session`},
+		{"other method", "s1", `{"jsonrpc":"2.0","id":5,"method":"tools/list"}`, false, ""},
+		{"notification", "s1", `{"jsonrpc":"2.0","method":"resources/subscribe"}`, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, handled := subs.handleMessage(context.Background(), tt.sessionID, []byte(tt.message))
+			if handled != tt.handled {
+				t.Fatalf("handled = %v, want %v", handled, tt.handled)
+			}
+			if !strings.Contains(string(response), tt.response) {
+				t.Errorf("response = %s, want it to contain %s", response, tt.response)
+			}
+		})
+	}
+}
+
+func TestOutlineSubscriptions_FilterStdio(t *testing.T) {
+	subs, _ := newTestSubscriptions(t)
+	input := `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"glyph://outline/missing.go"}}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}` + "\n"
+
+	var stdout bytes.Buffer
+	filtered, err := io.ReadAll(subs.filterStdio(strings.NewReader(input), &lockedWriter{w: &stdout}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(filtered) != `{"jsonrpc":"2.0","id":2,"method":"ping"}`+"\n" {
+		t.Errorf("filtered input = %q, want only the ping", filtered)
+	}
+	if !strings.HasPrefix(stdout.String(), `{"jsonrpc":"2.0","id":1,"error":`) || !strings.HasSuffix(stdout.String(), "\n") {
+		t.Errorf("stdout = %q, want the subscription's error response", stdout.String())
+	}
+}
+
+func TestOutlineSubscriptions_FilterStdioAsync(t *testing.T) {
+	tracker := newRequestTracker(1)
+	started, release := make(chan struct{}), make(chan struct{})
+	subs, err := newOutlineSubscriptions(tracker,
+		func(ctx context.Context, uri string) (string, error) {
+			close(started)
+			<-release
+			return "", nil
+		},
+		func(sessionID, uri string) {},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer subs.Close()
+
+	path := filepath.Join(t.TempDir(), "lib.go")
+	uri := outlineURIPrefix + filepath.ToSlash(path)
+	input := `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"` + uri + `"}}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}` + "\n"
+
+	// The ping is read while the subscription is still being outlined
+	var stdout bytes.Buffer
+	reader := bufio.NewReader(subs.filterStdio(strings.NewReader(input), &lockedWriter{w: &stdout}))
+	line, err := reader.ReadString('\n')
+	if err != nil || line != `{"jsonrpc":"2.0","id":2,"method":"ping"}`+"\n" {
+		t.Fatalf("first line = %q, %v, want the ping", line, err)
+	}
+
+	// The subscription holds a slot of the tracker until it is answered
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, release, err := tracker.acquire(ctx); err == nil {
+		release()
+		t.Error("Expected the subscription to hold the only slot")
+	}
+
+	close(release)
+	if rest, err := io.ReadAll(reader); err != nil || len(rest) != 0 {
+		t.Fatalf("rest = %q, %v, want the end of the input", rest, err)
+	}
+	if stdout.String() != `{"jsonrpc":"2.0","id":1,"result":{}}`+"\n" {
+		t.Errorf("stdout = %q, want the subscription's response", stdout.String())
+	}
+
+	// Subscriptions are rejected like tool calls once the shutdown has begun
+	tracker.drain()
+	response, _ := subs.handleMessage(context.Background(), "s1", []byte(`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"`+uri+`"}}`))
+	if !strings.Contains(string(response), "shutting down") {
+		t.Errorf("response = %s, want a shutdown error", response)
+	}
+}
+
+func TestOutlineSubscriptions_Expiry(t *testing.T) {
+	subs, _ := newTestSubscriptions(t)
+	subs.expiry = 20 * time.Millisecond
+	dir := t.TempDir()
+	uri := func(name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("package app\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return outlineURIPrefix + filepath.ToSlash(path)
+	}
+	count := func(sessionID string) int {
+		subs.mu.Lock()
+		defer subs.mu.Unlock()
+		return subs.counts[sessionID]
+	}
+
+	// A session listening for notifications keeps its subscriptions until it stops
+	subs.sessionRegistered("listening")
+	if err := subs.subscribe(context.Background(), "listening", uri("a.go")); err != nil {
+		t.Fatal(err)
+	}
+	// One that never listens, or whose client went away, loses them
+	if err := subs.subscribe(context.Background(), "gone", uri("b.go")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if count("listening") != 1 || count("gone") != 0 {
+		t.Fatalf("counts = %v, want only the listening session's subscription", subs.counts)
+	}
+
+	subs.sessionUnregistered("listening")
+	time.Sleep(100 * time.Millisecond)
+	if count("listening") != 0 || len(subs.subs) != 0 || len(subs.dirs) != 0 {
+		t.Errorf("Expected no subscriptions or watched directories left, got %v and %v", subs.subs, subs.dirs)
+	}
+
+	// Sessions may only subscribe to so many resources
+	subs.maxPerSession = 1
+	subs.sessionRegistered("greedy")
+	if err := subs.subscribe(context.Background(), "greedy", uri("c.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := subs.subscribe(context.Background(), "greedy", uri("c.go")); err != nil {
+		t.Errorf("Expected subscribing again to succeed, got %v", err)
+	}
+	if err := subs.subscribe(context.Background(), "greedy", uri("d.go")); err == nil || !strings.Contains(err.Error(), "at most 1") {
+		t.Errorf("Expected the second resource to be rejected, got %v", err)
+	}
+}
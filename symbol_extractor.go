@@ -1,9 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	sitter "github.com/smacker/go-tree-sitter"
 )
@@ -11,44 +18,164 @@ import (
 // SymbolExtractor handles symbol extraction using Tree-sitter queries
 type SymbolExtractor struct {
 	parser *sitter.Parser
+	// query replaces the built-in queries of every language when set
+	query string
+	// parseTime and queryTime accumulate the time spent parsing and running queries
+	parseTime time.Duration
+	queryTime time.Duration
+	// content is the content of the file read last
+	content []byte
+	// parseErrors holds the lines of the file parsed last that had syntax errors
+	parseErrors []LineRange
 }
 
-// NewSymbolExtractor creates a new symbol extractor
+// parserPool holds idle parsers. Each extractor takes its own parser, so extractions
+// running concurrently, such as parallel MCP tool calls, never share one.
+var parserPool = sync.Pool{
+	New: func() any { return sitter.NewParser() },
+}
+
+// NewSymbolExtractor creates a new symbol extractor. Extractors are not safe for
+// concurrent use; Close returns the parser of an extractor that is no longer needed.
 func NewSymbolExtractor() *SymbolExtractor {
 	return &SymbolExtractor{
-		parser: sitter.NewParser(),
+		parser: parserPool.Get().(*sitter.Parser),
+	}
+}
+
+// Close returns the extractor's parser to the pool. The extractor must not be used afterwards.
+func (e *SymbolExtractor) Close() {
+	if e.parser == nil {
+		return
+	}
+	parserPool.Put(e.parser)
+	e.parser = nil
+}
+
+// NewQuerySymbolExtractor creates a symbol extractor that reports the matches of a
+// custom Tree-sitter query as symbols of kind "match". The query must have an @name
+// capture; an optional @symbol capture sets the range and signature of each match.
+func NewQuerySymbolExtractor(query string) *SymbolExtractor {
+	return &SymbolExtractor{
+		parser: parserPool.Get().(*sitter.Parser),
+		query:  query,
 	}
 }
 
 // ExtractFromFile extracts symbols from a single file
 func (e *SymbolExtractor) ExtractFromFile(filePath string, detailLevel DetailLevel) ([]Symbol, error) {
+	if IsNotebookFile(filePath) {
+		return e.ExtractFromNotebook(filePath, detailLevel)
+	}
+
 	content, err := ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
+	e.content = content
 
 	langQueries := GetLanguageQueriesForFile(filePath)
 	if langQueries == nil {
 		return nil, fmt.Errorf("unsupported file type: %s", filePath)
 	}
 
-	e.parser.SetLanguage(langQueries.Language)
-	tree, err := e.parser.ParseCtx(context.Background(), nil, content)
+	return e.ExtractFromContent(filePath, content, langQueries, detailLevel)
+}
+
+// ExtractFromContent extracts symbols from source code in the given language,
+// reporting them as declared in filePath
+func (e *SymbolExtractor) ExtractFromContent(filePath string, content []byte, langQueries *LanguageQueries, detailLevel DetailLevel) ([]Symbol, error) {
+	e.content = content
+
+	tree, err := e.parse(content, langQueries)
+	if err != nil {
+		return nil, err
+	}
+	return e.extractFromTree(tree, filePath, content, langQueries, detailLevel)
+}
+
+// extractFromTree extracts symbols from content already parsed into tree
+func (e *SymbolExtractor) extractFromTree(tree *sitter.Tree, filePath string, content []byte, langQueries *LanguageQueries, detailLevel DetailLevel) ([]Symbol, error) {
+	e.content = content
+	e.parseErrors = parseErrorRanges(tree.RootNode())
+
+	symbols, err := e.extractSymbolsFromTree(tree, content, filePath, langQueries, detailLevel)
 	if err != nil {
 		return nil, err
 	}
 
-	return e.extractSymbolsFromTree(tree, content, filePath, langQueries, detailLevel)
+	// Python has no module declaration, so the module is named after the file
+	if e.query == "" && langQueries.Name == "python" && filepath.Ext(filePath) == ".py" {
+		symbols = append([]Symbol{pythonModuleSymbol(filePath, detailLevel)}, symbols...)
+	}
+
+	if e.query == "" && langQueries.Name == "html" {
+		scripts, err := e.extractInlineScripts(tree, content, filePath, detailLevel)
+		if err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, scripts...)
+	}
+
+	return symbols, nil
+}
+
+// parseFile reads and parses a single file with the grammar for its language
+func (e *SymbolExtractor) parseFile(filePath string) (*sitter.Tree, []byte, *LanguageQueries, error) {
+	content, err := ReadFile(filePath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	e.content = content
+
+	langQueries := GetLanguageQueriesForFile(filePath)
+	if langQueries == nil {
+		return nil, nil, nil, fmt.Errorf("unsupported file type: %s", filePath)
+	}
+
+	tree, err := e.parse(content, langQueries)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	e.parseErrors = parseErrorRanges(tree.RootNode())
+
+	return tree, content, langQueries, nil
+}
+
+// parse parses source code with the grammar of the given language
+func (e *SymbolExtractor) parse(content []byte, langQueries *LanguageQueries) (*sitter.Tree, error) {
+	start := time.Now()
+	defer func() { e.parseTime += time.Since(start) }()
+
+	e.parser.SetLanguage(langQueries.Language)
+	return e.parser.ParseCtx(context.Background(), nil, content)
 }
 
 // extractSymbolsFromTree extracts symbols using Tree-sitter queries
 func (e *SymbolExtractor) extractSymbolsFromTree(tree *sitter.Tree, content []byte, filePath string, langQueries *LanguageQueries, detailLevel DetailLevel) ([]Symbol, error) {
+	start := time.Now()
+	defer func() { e.queryTime += time.Since(start) }()
+
 	var allSymbols []Symbol
 	root := tree.RootNode()
 
-	// Execute each query for this language
-	for symbolType, queryStr := range langQueries.Queries {
-		symbols, err := e.executeQuery(root, content, filePath, queryStr, symbolType, detailLevel, langQueries.Language)
+	if e.query != "" {
+		if err := validateCustomQuery(e.query, langQueries); err != nil {
+			return nil, err
+		}
+		return e.executeQuery(root, content, filePath, e.query, "match", detailLevel, langQueries)
+	}
+
+	// Execute each query for this language, in a fixed order so that symbols
+	// matched by several queries are always listed the same way
+	symbolTypes := make([]string, 0, len(langQueries.Queries))
+	for symbolType := range langQueries.Queries {
+		symbolTypes = append(symbolTypes, symbolType)
+	}
+	sort.Strings(symbolTypes)
+
+	for _, symbolType := range symbolTypes {
+		symbols, err := e.executeQuery(root, content, filePath, langQueries.Queries[symbolType], symbolType, detailLevel, langQueries)
 		if err != nil {
 			// Skip queries that fail to compile or execute
 			continue
@@ -60,8 +187,8 @@ func (e *SymbolExtractor) extractSymbolsFromTree(tree *sitter.Tree, content []by
 }
 
 // executeQuery runs a single Tree-sitter query and extracts symbols
-func (e *SymbolExtractor) executeQuery(root *sitter.Node, content []byte, filePath, queryStr, symbolType string, detailLevel DetailLevel, lang *sitter.Language) ([]Symbol, error) {
-	query, err := sitter.NewQuery([]byte(queryStr), lang)
+func (e *SymbolExtractor) executeQuery(root *sitter.Node, content []byte, filePath, queryStr, symbolType string, detailLevel DetailLevel, langQueries *LanguageQueries) ([]Symbol, error) {
+	query, err := sitter.NewQuery([]byte(queryStr), langQueries.Language)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create query for %s: %w", symbolType, err)
 	}
@@ -76,8 +203,10 @@ func (e *SymbolExtractor) executeQuery(root *sitter.Node, content []byte, filePa
 		if !ok {
 			break
 		}
+		// Drop captures of matches that fail predicates such as #eq? and #match?
+		match = cursor.FilterPredicates(match, content)
 
-		symbol := e.extractSymbolFromMatch(match, query, content, filePath, symbolType, detailLevel)
+		symbol := e.extractSymbolFromMatch(match, query, content, filePath, symbolType, detailLevel, langQueries)
 		if symbol.Name != "" {
 			symbols = append(symbols, symbol)
 		}
@@ -87,7 +216,7 @@ func (e *SymbolExtractor) executeQuery(root *sitter.Node, content []byte, filePa
 }
 
 // extractSymbolFromMatch creates a Symbol from a query match
-func (e *SymbolExtractor) extractSymbolFromMatch(match *sitter.QueryMatch, query *sitter.Query, content []byte, filePath, symbolType string, detailLevel DetailLevel) Symbol {
+func (e *SymbolExtractor) extractSymbolFromMatch(match *sitter.QueryMatch, query *sitter.Query, content []byte, filePath, symbolType string, detailLevel DetailLevel, langQueries *LanguageQueries) Symbol {
 	symbol := Symbol{
 		Kind:     mapSymbolKind(symbolType),
 		FilePath: filePath,
@@ -105,38 +234,132 @@ func (e *SymbolExtractor) extractSymbolFromMatch(match *sitter.QueryMatch, query
 		case "name":
 			nameNode = node
 			symbol.Name = string(content[node.StartByte():node.EndByte()])
-		case "function", "method", "class", "interface", "type", "const", "var", "struct", "enum", "record", "annotation", "constructor", "field":
+			if symbolType == "impls" {
+				// An impl block is named after its type; the signature names the trait
+				symbol.Name = rustTypeName(node, content)
+			}
+			if langQueries.Name == "hcl" {
+				// Resources and data sources are addressed by their type and name labels
+				symbol.Name = hclBlockName(node, content)
+			}
+			if langQueries.Name == "yaml" {
+				symbol.Name = yamlSymbolName(symbolType, node, content)
+			}
+			if langQueries.Name == "css" && symbolType == "media" {
+				symbol.Name = cssMediaQuery(node.Parent(), content)
+			}
+		case "receiver":
+			symbol.Receiver = receiverTypeName(node, content)
+		case "function", "method", "class", "interface", "type", "const", "var", "struct", "enum", "record", "annotation", "constructor", "field", "symbol", "package", "module", "namespace":
 			mainNode = node
 			symbol.StartLine = node.StartPoint().Row + 1
 			symbol.EndLine = node.EndPoint().Row + 1
+			symbol.StartColumn = runeColumn(content, node.StartByte(), node.StartPoint().Column)
+			symbol.EndColumn = runeColumn(content, node.EndByte(), node.EndPoint().Column)
 		}
 	}
 
 	// If we have a main node, extract signature based on detail level
 	if mainNode != nil && detailLevel >= Standard {
-		symbol.Signature = e.extractSignature(mainNode, content, detailLevel)
+		symbol.Signature = e.extractSignature(mainNode, content, detailLevel, langQueries)
 	}
 
 	// If we don't have a main node but have a name node, use that for position
 	if mainNode == nil && nameNode != nil {
 		symbol.StartLine = nameNode.StartPoint().Row + 1
 		symbol.EndLine = nameNode.EndPoint().Row + 1
+		symbol.StartColumn = runeColumn(content, nameNode.StartByte(), nameNode.StartPoint().Column)
+		symbol.EndColumn = runeColumn(content, nameNode.EndByte(), nameNode.EndPoint().Column)
+	}
+
+	if declNode := mainNode; declNode != nil || nameNode != nil {
+		if declNode == nil {
+			declNode = nameNode
+		}
+		if declNode == nameNode && declNode.Parent() != nil {
+			declNode = declNode.Parent()
+		}
+		symbol.Public = isPublicSymbol(langQueries.Name, declNode, content, symbol.Name)
+		symbol.Annotations = symbolAnnotations(declNode, content)
+		symbol.Deprecated = isDeprecated(declNode, content, symbol.Annotations)
 	}
 
 	return symbol
 }
 
+// validateCustomQuery checks that a custom query compiles for a language and has an @name capture
+func validateCustomQuery(queryStr string, langQueries *LanguageQueries) error {
+	query, err := sitter.NewQuery([]byte(queryStr), langQueries.Language)
+	if err != nil {
+		return fmt.Errorf("invalid query for %s: %w", langQueries.Name, err)
+	}
+	defer query.Close()
+
+	for i := uint32(0); i < query.CaptureCount(); i++ {
+		if query.CaptureNameForId(i) == "name" {
+			return nil
+		}
+	}
+	return fmt.Errorf("query must have an @name capture")
+}
+
 // extractSignature extracts the signature based on detail level
-func (e *SymbolExtractor) extractSignature(node *sitter.Node, content []byte, detailLevel DetailLevel) string {
+func (e *SymbolExtractor) extractSignature(node *sitter.Node, content []byte, detailLevel DetailLevel, langQueries *LanguageQueries) string {
 	if detailLevel == Full {
 		// For full detail, include the entire node content
 		return strings.TrimSpace(string(content[node.StartByte():node.EndByte()]))
 	}
 
+	// Rust declarations have type annotations and "::" paths before their body,
+	// so they end where the body node starts rather than at a body indicator
+	if langQueries.Name == "rust" {
+		return rustDeclarationSignature(node, content)
+	}
+	if langQueries.Name == "csharp" {
+		return csharpDeclarationSignature(node, content)
+	}
+	if langQueries.Name == "php" || langQueries.Name == "bash" {
+		return declarationBeforeBody(node, content)
+	}
+	if langQueries.Name == "elixir" {
+		return elixirDeclarationSignature(node, content)
+	}
+	if langQueries.Name == "markdown" {
+		return markdownHeadingSignature(node, content)
+	}
+	if langQueries.Name == "yaml" {
+		return yamlSignature(node, content)
+	}
+	if langQueries.Name == "css" {
+		return cssSignature(node, content)
+	}
+	if langQueries.Name == "html" {
+		return htmlStartTag(node, content)
+	}
+
 	// For standard detail, try to extract just the declaration part
 	return e.extractDeclarationSignature(node, content)
 }
 
+// rustDeclarationSignature returns a Rust item up to its body or value, such as
+// "pub fn new(x: T, y: T) -> Self" or "pub const MAX: usize"
+func rustDeclarationSignature(node *sitter.Node, content []byte) string {
+	end := node.EndByte()
+	if body := node.ChildByFieldName("body"); body != nil && body.Type() != "ordered_field_declaration_list" {
+		end = body.StartByte()
+	} else if value := node.ChildByFieldName("value"); value != nil {
+		end = value.StartByte()
+	} else if node.Type() == "macro_definition" {
+		if name := node.ChildByFieldName("name"); name != nil {
+			end = name.EndByte()
+		}
+	}
+
+	signature := strings.TrimSpace(string(content[node.StartByte():end]))
+	signature = strings.TrimSpace(strings.TrimSuffix(signature, "="))
+	return strings.TrimSuffix(signature, ";")
+}
+
 // extractDeclarationSignature extracts just the declaration part (before the body)
 func (e *SymbolExtractor) extractDeclarationSignature(node *sitter.Node, content []byte) string {
 	startByte := node.StartByte()
@@ -162,6 +385,213 @@ func (e *SymbolExtractor) extractDeclarationSignature(node *sitter.Node, content
 	return strings.TrimSpace(string(content[startByte:endByte]))
 }
 
+// runeColumn converts the byte column Tree-sitter reports for a byte offset into a
+// 1-based column counted in runes, so positions after non-ASCII identifiers line up
+// with what editors show
+func runeColumn(content []byte, offset, column uint32) uint32 {
+	if column > offset || offset > uint32(len(content)) {
+		return column + 1
+	}
+	return uint32(utf8.RuneCount(content[offset-column:offset])) + 1
+}
+
+// parseErrorRanges returns the line ranges of ERROR and MISSING nodes in a syntax
+// tree, merging ranges that overlap or touch
+func parseErrorRanges(root *sitter.Node) []LineRange {
+	if !root.HasError() {
+		return nil
+	}
+
+	var ranges []LineRange
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		if node.IsError() || node.IsMissing() {
+			r := LineRange{Start: node.StartPoint().Row + 1, End: node.EndPoint().Row + 1}
+			if n := len(ranges); n > 0 && r.Start <= ranges[n-1].End+1 {
+				ranges[n-1].End = max(ranges[n-1].End, r.End)
+			} else {
+				ranges = append(ranges, r)
+			}
+			return
+		}
+		for i := 0; i < int(node.ChildCount()); i++ {
+			if child := node.Child(i); child.HasError() {
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+
+	return ranges
+}
+
+// pythonModuleSymbol returns a file-level symbol for a Python module, named with its
+// dotted import path within the enclosing packages (directories with an __init__.py)
+func pythonModuleSymbol(filePath string, detailLevel DetailLevel) Symbol {
+	name := strings.TrimSuffix(filepath.Base(filePath), ".py")
+	dir := filepath.Dir(filePath)
+	if name == "__init__" {
+		name = filepath.Base(dir)
+		dir = filepath.Dir(dir)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "__init__.py")); err != nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		name = filepath.Base(dir) + "." + name
+		dir = parent
+	}
+
+	// Column 0 keeps the module ahead of, and outside of, a declaration on line 1
+	symbol := Symbol{
+		Name:      name,
+		Kind:      "module",
+		StartLine: 1,
+		EndLine:   1,
+		FilePath:  filePath,
+		Public:    true,
+	}
+	if detailLevel >= Standard {
+		symbol.Signature = "module " + name
+	}
+	return symbol
+}
+
+// csharpDeclarationSignature returns a C# declaration without its attributes, up to
+// its body or accessors, such as "public class OrderService : IRepository<Order>"
+// or "public string Name"
+func csharpDeclarationSignature(node *sitter.Node, content []byte) string {
+	start := node.StartByte()
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "attribute_list" {
+			start = child.EndByte()
+		}
+	}
+
+	end := node.EndByte()
+	if body := node.ChildByFieldName("body"); body != nil {
+		end = body.StartByte()
+	} else if accessors := node.ChildByFieldName("accessors"); accessors != nil {
+		end = accessors.StartByte()
+	} else if i := bytes.IndexAny(content[start:end], "=;"); i >= 0 {
+		end = start + uint32(i)
+	}
+
+	signature := strings.TrimSpace(string(content[start:end]))
+	return strings.TrimSpace(strings.TrimSuffix(signature, "=>"))
+}
+
+// declarationBeforeBody returns a declaration up to its body, such as the PHP
+// "public function find(int $id): ?array", or up to the value of a declaration
+// without a body, such as a constant
+func declarationBeforeBody(node *sitter.Node, content []byte) string {
+	end := node.EndByte()
+	if body := node.ChildByFieldName("body"); body != nil {
+		end = body.StartByte()
+	} else if i := bytes.IndexAny(content[node.StartByte():end], "=;"); i >= 0 {
+		end = node.StartByte() + uint32(i)
+	}
+	return strings.TrimSpace(string(content[node.StartByte():end]))
+}
+
+// elixirDeclarationSignature returns an Elixir definition without its body, such as
+// "def list(opts \\ []) when is_list(opts)" or "defmodule MyApp.Accounts".
+// Module attributes such as @callback are returned whole.
+func elixirDeclarationSignature(node *sitter.Node, content []byte) string {
+	if node.Type() != "call" {
+		return strings.TrimSpace(string(content[node.StartByte():node.EndByte()]))
+	}
+
+	target := node.ChildByFieldName("target")
+	var args *sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "arguments" {
+			args = child
+		}
+	}
+	if target == nil || args == nil || args.NamedChildCount() == 0 {
+		return strings.TrimSpace(string(content[node.StartByte():node.EndByte()]))
+	}
+
+	// The first argument is the head; a "do:" keyword list or do block follows it
+	head := args.NamedChild(0)
+	return string(content[target.StartByte():target.EndByte()]) + " " + string(content[head.StartByte():head.EndByte()])
+}
+
+// markdownHeadingSignature returns the first line of a heading, such as "## Usage",
+// rather than the whole section it starts
+func markdownHeadingSignature(node *sitter.Node, content []byte) string {
+	if node.Type() == "section" && node.NamedChildCount() > 0 {
+		node = node.NamedChild(0)
+	}
+	heading := string(content[node.StartByte():node.EndByte()])
+	heading, _, _ = strings.Cut(heading, "\n")
+	return strings.TrimSpace(heading)
+}
+
+// receiverTypeName returns the base type of a Go method receiver, so both
+// (s *Server) and (s Stack[T]) yield the bare type name. For a Rust impl or
+// trait it returns the type the method belongs to, e.g. Point for Point<T>.
+func receiverTypeName(params *sitter.Node, content []byte) string {
+	if params.Type() != "parameter_list" {
+		return rustTypeName(params, content)
+	}
+
+	for i := 0; i < int(params.NamedChildCount()); i++ {
+		param := params.NamedChild(i)
+		if param.Type() != "parameter_declaration" {
+			continue
+		}
+
+		typeNode := param.ChildByFieldName("type")
+		for typeNode != nil {
+			switch typeNode.Type() {
+			case "pointer_type", "parenthesized_type":
+				typeNode = typeNode.NamedChild(0)
+				continue
+			case "generic_type":
+				typeNode = typeNode.ChildByFieldName("type")
+				continue
+			}
+			return string(content[typeNode.StartByte():typeNode.EndByte()])
+		}
+	}
+	return ""
+}
+
+// rustTypeName returns the bare name of a Rust type, without references,
+// generic arguments or module paths
+func rustTypeName(typeNode *sitter.Node, content []byte) string {
+	for {
+		switch typeNode.Type() {
+		case "reference_type", "generic_type":
+			typeNode = typeNode.ChildByFieldName("type")
+		case "scoped_type_identifier":
+			typeNode = typeNode.ChildByFieldName("name")
+		default:
+			return string(content[typeNode.StartByte():typeNode.EndByte()])
+		}
+		if typeNode == nil {
+			return ""
+		}
+	}
+}
+
+// hclBlockName joins the labels of an HCL block with dots, starting from its first
+// label, so resource "aws_s3_bucket" "logs" is named aws_s3_bucket.logs
+func hclBlockName(label *sitter.Node, content []byte) string {
+	var parts []string
+	for ; label != nil && label.Type() == "string_lit"; label = label.NextNamedSibling() {
+		text := string(content[label.StartByte():label.EndByte()])
+		parts = append(parts, strings.Trim(text, `"`))
+	}
+	return strings.Join(parts, ".")
+}
+
 // mapSymbolKind maps query symbol types to display kinds
 func mapSymbolKind(symbolType string) string {
 	kindMap := map[string]string{
@@ -189,6 +619,35 @@ func mapSymbolKind(symbolType string) string {
 		"assignments":          "var",
 		"type_aliases":         "type",
 		"properties":           "property",
+		"packages":             "package",
+		"modules":              "module",
+		"namespaces":           "namespace",
+		"trait_methods":        "method",
+		"unions":               "union",
+		"traits":               "trait",
+		"impls":                "impl",
+		"statics":              "static",
+		"macros":               "macro",
+		"delegates":            "type",
+		"events":               "field",
+		"callbacks":            "callback",
+		"exports":              "var",
+		"declared_exports":     "var",
+		"resources":            "resource",
+		"data_sources":         "data",
+		"outputs":              "output",
+		"providers":            "provider",
+		"headings":             "heading",
+		"setext_headings":      "heading",
+		"manifests":            "resource",
+		"keys":                 "key",
+		"rule_sets":            "selector",
+		"mixins":               "mixin",
+		"custom_properties":    "property",
+		"media":                "media",
+		"elements":             "element",
+		"templates":            "template",
+		"custom_elements":      "element",
 	}
 
 	if mapped, ok := kindMap[symbolType]; ok {
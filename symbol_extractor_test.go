@@ -246,16 +246,197 @@ if __name__ == "__main__":
 	}
 }
 
-// Helper method for DetailLevel
-func (d DetailLevel) String() string {
-	switch d {
-	case Minimal:
-		return "minimal"
-	case Standard:
-		return "standard"
-	case Full:
-		return "full"
-	default:
-		return "unknown"
+func TestSymbolExtractor_CustomQuery(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "main.go")
+	testCode := `package main
+
+func main() {
+	if err := run(); err != nil {
+		panic(err)
+	}
+	panic("unreachable")
+}
+`
+	if err := os.WriteFile(testFile, []byte(testCode), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	query := `(call_expression function: (identifier) @name (#eq? @name "panic")) @symbol`
+	symbols, err := NewQuerySymbolExtractor(query).ExtractFromFile(testFile, Standard)
+	if err != nil {
+		t.Fatalf("ExtractFromFile error = %v", err)
+	}
+
+	if len(symbols) != 2 {
+		t.Fatalf("expected 2 matches, got %d: %+v", len(symbols), symbols)
+	}
+	for i, line := range []uint32{5, 7} {
+		sym := symbols[i]
+		if sym.Kind != "match" || sym.Name != "panic" || sym.StartLine != line {
+			t.Errorf("match %d = %s %s at line %d, want match panic at line %d", i, sym.Kind, sym.Name, sym.StartLine, line)
+		}
+	}
+	if symbols[1].Signature != `panic("unreachable")` {
+		t.Errorf("expected the @symbol capture as signature, got %q", symbols[1].Signature)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"syntax error", "(call_expression"},
+		{"missing name capture", "(call_expression) @symbol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ExtractSymbols(testFile, ExtractOptions{Detail: Minimal, Query: tt.query}); err == nil {
+				t.Errorf("expected an error for query %q", tt.query)
+			}
+		})
+	}
+}
+
+func TestSymbolExtractor_UnicodeIdentifiers(t *testing.T) {
+	tests := []struct {
+		file      string
+		code      string
+		name      string
+		signature string
+		// startColumn and endColumn are counted in runes, not bytes
+		startColumn uint32
+		endColumn   uint32
+	}{
+		{
+			file:        "main.go",
+			code:        "package main\n\ntype Größe struct{ Wert int }\n",
+			name:        "Größe",
+			signature:   "Größe struct",
+			startColumn: 6,
+			endColumn:   30,
+		},
+		{
+			file:        "greet.go",
+			code:        "package main\n\nfunc 挨拶(名前 string) string { return 名前 }\n",
+			name:        "挨拶",
+			signature:   "func 挨拶(名前 string) string",
+			startColumn: 1,
+			endColumn:   40,
+		},
+		{
+			file:        "greet.py",
+			code:        "class Café:\n    def grüße(self, 名前): return 名前\n",
+			name:        "grüße",
+			signature:   "def grüße(self, 名前)",
+			startColumn: 5,
+			endColumn:   35,
+		},
+		{
+			file:        "bird.js",
+			code:        "const ñandú = (x) => x;\n",
+			name:        "ñandú",
+			signature:   "ñandú",
+			startColumn: 7,
+			endColumn:   23,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(testFile, []byte(tt.code), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			extractor := NewSymbolExtractor()
+			symbols, err := extractor.ExtractFromFile(testFile, Standard)
+			if err != nil {
+				t.Fatalf("ExtractFromFile error = %v", err)
+			}
+
+			for _, sym := range symbols {
+				if sym.Name != tt.name {
+					continue
+				}
+				if sym.Signature != tt.signature || sym.StartColumn != tt.startColumn || sym.EndColumn != tt.endColumn {
+					t.Errorf("%s: signature %q, columns %d-%d; want %q, columns %d-%d",
+						sym.Name, sym.Signature, sym.StartColumn, sym.EndColumn, tt.signature, tt.startColumn, tt.endColumn)
+				}
+				return
+			}
+			t.Errorf("symbol %q not found in %+v", tt.name, symbols)
+		})
+	}
+}
+
+func TestSymbolExtractor_PackageSymbols(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":                 "package server\n\nfunc Run() {}\n",
+		"App.java":                "package com.example.app;\n\npublic class App {}\n",
+		"models.ts":               "namespace App.Models {\n  export class User {}\n}\ndeclare module \"legacy\" {\n}\n",
+		"shop/__init__.py":        "",
+		"shop/orders/__init__.py": "",
+		"shop/orders/checkout.py": "class Cart:\n    pass\n",
+		"scripts/standalone.py":   "def main():\n    pass\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file string
+		kind string
+		name string
+	}{
+		{"main.go", "package", "server"},
+		{"App.java", "package", "com.example.app"},
+		{"models.ts", "namespace", "App.Models"},
+		{"models.ts", "module", "legacy"},
+		{"shop/orders/checkout.py", "module", "shop.orders.checkout"},
+		{"shop/orders/__init__.py", "module", "shop.orders"},
+		{"scripts/standalone.py", "module", "standalone"},
+	}
+
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.file+" "+tt.name, func(t *testing.T) {
+			symbols, err := extractor.ExtractFromFile(filepath.Join(root, tt.file), Standard)
+			if err != nil {
+				t.Fatalf("ExtractFromFile error = %v", err)
+			}
+			for _, sym := range symbols {
+				if sym.Kind == tt.kind && sym.Name == tt.name {
+					return
+				}
+			}
+			t.Errorf("expected %s %s in %+v", tt.kind, tt.name, symbols)
+		})
+	}
+
+	// Module symbols stay siblings of a declaration starting on line 1
+	symbols, err := extractor.ExtractFromFile(filepath.Join(root, "shop/orders/checkout.py"), Minimal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roots := BuildHierarchy(symbols); len(roots) != 2 || len(roots[0].Children) != 0 {
+		t.Errorf("expected the module and class as top-level siblings, got %+v", roots)
+	}
+
+	symbols, err = extractor.ExtractFromFile(filepath.Join(root, "App.java"), Minimal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := FormatSymbolsWithOptions(symbols, FormatOptions{Detail: Minimal, GroupByPackage: true})
+	if !strings.Contains(output, "## com.example.app\n") {
+		t.Errorf("expected Java files grouped by their package declaration:\n%s", output)
 	}
 }
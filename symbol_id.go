@@ -0,0 +1,87 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// ProjectRoot returns the nearest directory at or above dir that contains a .git
+// entry, or dir itself outside of a repository. Symbol IDs are derived from paths
+// relative to it, so they don't depend on the pattern a symbol was found with.
+func ProjectRoot(dir string) string {
+	dir = filepath.Clean(dir)
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// AssignSymbolIDs sets a deterministic ID on every symbol, hashed from its file path
+// relative to root, its qualified name and its kind. The qualified name joins the
+// names of enclosing symbols (or a Go method's receiver) with dots, so IDs survive
+// edits that only move a symbol within its file. Symbols that would share an ID,
+// such as overloaded Java methods, are told apart by their order in the file.
+func AssignSymbolIDs(symbols []Symbol, root string) {
+	byFile := make(map[string][]int)
+	for i, sym := range symbols {
+		byFile[sym.FilePath] = append(byFile[sym.FilePath], i)
+	}
+
+	for file, indices := range byFile {
+		rel := file
+		if root != "" {
+			if r, err := filepath.Rel(root, file); err == nil {
+				rel = r
+			}
+		}
+		rel = filepath.ToSlash(rel)
+
+		sort.SliceStable(indices, func(i, j int) bool {
+			return symbolBefore(symbols[indices[i]], symbols[indices[j]])
+		})
+
+		qualified := make(map[int]string, len(indices))
+		seen := make(map[string]int)
+		var stack []int
+		for _, i := range indices {
+			sym := symbols[i]
+			for len(stack) > 0 && !containsSymbol(symbols[stack[len(stack)-1]], sym) {
+				stack = stack[:len(stack)-1]
+			}
+
+			name := sym.Name
+			switch {
+			case sym.Receiver != "":
+				name = sym.Receiver + "." + name
+			case len(stack) > 0:
+				name = qualified[stack[len(stack)-1]] + "." + name
+			}
+			qualified[i] = name
+
+			key := rel + "\x00" + name + "\x00" + sym.Kind
+			seen[key]++
+			if n := seen[key]; n > 1 {
+				key += "\x00" + strconv.Itoa(n)
+			}
+			symbols[i].ID = symbolID(key)
+
+			stack = append(stack, i)
+		}
+	}
+}
+
+// symbolID hashes a symbol key into a short hex ID
+func symbolID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
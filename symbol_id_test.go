@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssignSymbolIDs(t *testing.T) {
+	extract := func(root, code string) map[string]string {
+		t.Helper()
+		testFile := filepath.Join(root, "pkg", "server.go")
+		if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(testFile, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		extractor := NewSymbolExtractor()
+		defer extractor.Close()
+		symbols, err := extractor.ExtractFromFile(testFile, Minimal)
+		if err != nil {
+			t.Fatalf("ExtractFromFile error = %v", err)
+		}
+		AssignSymbolIDs(symbols, root)
+
+		ids := make(map[string]string)
+		for _, sym := range symbols {
+			if sym.ID == "" {
+				t.Errorf("symbol %s has no ID", sym.Name)
+			}
+			ids[sym.Kind+" "+sym.Name] = sym.ID
+		}
+		return ids
+	}
+
+	code := "package pkg\n\ntype Server struct{}\n\nfunc (s *Server) Start() {\n\tvar err error\n\t_ = err\n}\n\nfunc Start() {}\n"
+	original := extract(t.TempDir(), code)
+	if original["method Start"] == original["func Start"] {
+		t.Errorf("expected a method and a function of the same name to have different IDs")
+	}
+
+	// A checkout elsewhere, with a symbol inserted above, keeps the same IDs
+	edited := extract(t.TempDir(), "package pkg\n\nconst Version = 1\n\n"+code[len("package pkg\n\n"):])
+	for key, id := range original {
+		if edited[key] != id {
+			t.Errorf("ID of %s changed from %s to %s", key, id, edited[key])
+		}
+	}
+	if len(edited) != len(original)+1 {
+		t.Errorf("expected one more symbol after the edit, got %v", edited)
+	}
+}
+
+func TestAssignSymbolIDs_Duplicates(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "add", Kind: "method", FilePath: "/repo/A.java", StartLine: 2, EndLine: 2},
+		{Name: "add", Kind: "method", FilePath: "/repo/A.java", StartLine: 3, EndLine: 3},
+		{Name: "add", Kind: "method", FilePath: "/repo/B.java", StartLine: 2, EndLine: 2},
+	}
+	AssignSymbolIDs(symbols, "/repo")
+
+	seen := make(map[string]bool)
+	for _, sym := range symbols {
+		if seen[sym.ID] {
+			t.Errorf("duplicate ID %s for %s:%d", sym.ID, sym.FilePath, sym.StartLine)
+		}
+		seen[sym.ID] = true
+	}
+}
+
+func TestProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ProjectRoot(nested); got != nested {
+		t.Errorf("ProjectRoot outside a repository = %s, want %s", got, nested)
+	}
+
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := ProjectRoot(nested); got != root {
+		t.Errorf("ProjectRoot = %s, want %s", got, root)
+	}
+}
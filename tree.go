@@ -0,0 +1,55 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// formatTree writes symbols as a tree drawn with box-drawing characters, one
+// tree per file with the file path, relative to root, at its top. Entries read as
+// in the standard outline, with signatures kept to a single line.
+func formatTree(symbols []Symbol, root string) string {
+	if len(symbols) == 0 {
+		return "No symbols found\n"
+	}
+
+	var files []string
+	fileSymbols := make(map[string][]Symbol)
+	for _, sym := range symbols {
+		if _, ok := fileSymbols[sym.FilePath]; !ok {
+			files = append(files, sym.FilePath)
+		}
+		fileSymbols[sym.FilePath] = append(fileSymbols[sym.FilePath], sym)
+	}
+
+	var sb strings.Builder
+	for i, file := range files {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		path := file
+		if root != "" {
+			if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+		sb.WriteString(path + "\n")
+		writeTreeNodes(&sb, BuildHierarchy(fileSymbols[file]), "")
+	}
+	return sb.String()
+}
+
+// writeTreeNodes writes a level of the tree, prefixing each entry with the
+// branches of the levels above it
+func writeTreeNodes(sb *strings.Builder, nodes []*SymbolNode, prefix string) {
+	for i, node := range nodes {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+		sym := node.Symbol
+		sym.Signature = strings.Join(strings.Fields(sym.Signature), " ")
+		sb.WriteString(prefix + branch + standardEntry(sym, 0) + "\n")
+		writeTreeNodes(sb, node.Children, prefix+indent)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestFormatTree(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Widget", Kind: "class", Signature: "class Widget", StartLine: 3, EndLine: 20, StartColumn: 1, EndColumn: 2, FilePath: "/proj/src/widget.ts"},
+		{Name: "render", Kind: "method", Signature: "render(\n    props: Props,\n): void", StartLine: 4, EndLine: 10, StartColumn: 3, EndColumn: 4, FilePath: "/proj/src/widget.ts"},
+		{Name: "Inner", Kind: "class", Signature: "class Inner", StartLine: 12, EndLine: 18, StartColumn: 3, EndColumn: 4, FilePath: "/proj/src/widget.ts"},
+		{Name: "size", Kind: "field", StartLine: 13, EndLine: 13, StartColumn: 5, EndColumn: 10, FilePath: "/proj/src/widget.ts"},
+		{Name: "VERSION", Kind: "const", Signature: "VERSION", StartLine: 22, EndLine: 22, StartColumn: 1, EndColumn: 10, FilePath: "/proj/src/widget.ts"},
+		{Name: "main", Kind: "func", Signature: "func main()", StartLine: 1, EndLine: 2, StartColumn: 1, EndColumn: 2, FilePath: "/elsewhere/main.go"},
+	}
+
+	want := `src/widget.ts
+├── class: class Widget
+│   ├── method: render( props: Props, ): void
+│   └── class: class Inner
+│       └── field: size (lines 13-13)
+└── const: VERSION
+
+/elsewhere/main.go
+└── func: func main()
+`
+	if got := formatTree(symbols, "/proj"); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -1,15 +1,51 @@
 package main
 
-import "strings"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Symbol represents a code symbol with its metadata
 type Symbol struct {
-	Name      string
-	Kind      string
-	StartLine uint32
-	EndLine   uint32
-	Signature string
-	FilePath  string
+	ID          string        `json:"id,omitempty"`
+	Name        string        `json:"name"`
+	Kind        string        `json:"kind"`
+	StartLine   uint32        `json:"start_line"`
+	EndLine     uint32        `json:"end_line"`
+	StartColumn uint32        `json:"start_column"`
+	EndColumn   uint32        `json:"end_column"`
+	Signature   string        `json:"signature,omitempty"`
+	FilePath    string        `json:"file"`
+	Public      bool          `json:"public"`
+	Annotations []string      `json:"annotations,omitempty"`
+	Deprecated  bool          `json:"deprecated,omitempty"`
+	Receiver    string        `json:"receiver,omitempty"`
+	Coverage    *Coverage     `json:"coverage,omitempty"`
+	Cell        *NotebookCell `json:"cell,omitempty"`
+}
+
+// NotebookCell locates a symbol inside a Jupyter notebook; symbol lines are relative to the cell
+type NotebookCell struct {
+	// Index is the 1-based position of the cell in the notebook
+	Index int `json:"index"`
+	// ExecutionCount is the cell's execution count, or nil if it was never run
+	ExecutionCount *int `json:"execution_count"`
+}
+
+// Coverage holds the line coverage of a symbol
+type Coverage struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Percent returns the covered share of instrumented lines as a percentage
+func (c Coverage) Percent() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return float64(c.Covered) * 100 / float64(c.Total)
 }
 
 // DetailLevel controls how much information to include in symbol extraction
@@ -21,6 +57,116 @@ const (
 	Full
 )
 
+// ExtractOptions controls symbol extraction
+type ExtractOptions struct {
+	Detail DetailLevel
+	// Coverage is the path of a Go coverprofile or lcov file to merge into the outline
+	Coverage string
+	// SourceMaps maps symbols in generated JavaScript back to their original sources
+	SourceMaps bool
+	// Discovery controls which files matching the pattern are processed
+	Discovery DiscoveryOptions
+	// GroupByPackage groups the output by Go package import path instead of file
+	GroupByPackage bool
+	// PublicOnly keeps only exported/public symbols
+	PublicOnly bool
+	// Depth limits the output to this many levels of nested symbols; 0 means no limit
+	Depth int
+	// MaxSymbolsPerFile limits how many symbols are shown for each file; 0 means no limit
+	MaxSymbolsPerFile int
+	// MaxSignatureLength truncates longer signatures; 0 means no limit
+	MaxSignatureLength int
+	// Summarize collapses the largest files to top-level symbols while the outline
+	// is larger than this many bytes; 0 disables summarization
+	Summarize int
+	// Lines keeps only symbols intersecting this line range
+	Lines LineRange
+	// MinLines omits symbols spanning fewer lines than this
+	MinLines int
+	// AnnotatedWith keeps only symbols carrying this annotation or decorator
+	AnnotatedWith string
+	// Deprecated selects deprecated symbols: include (default), exclude or only
+	Deprecated string
+	// Receiver keeps only Go methods defined on this type
+	Receiver string
+	// Name keeps only symbols whose name matches it, as selected by NameMatch
+	Name string
+	// NameMatch is how Name is matched: regex (default), exact, glob or fuzzy.
+	// Fuzzy matches are ranked by score instead of grouped by file.
+	NameMatch string
+	// Limits bounds the number of files and bytes the extraction may process
+	Limits ExtractLimits
+	// Cache, when set, supplies the symbols of files unchanged since an earlier
+	// extraction stored them and stores those it extracts
+	Cache *SymbolCache
+	// DebugTimings records parse and query times for every file
+	DebugTimings bool
+	// Query is a custom Tree-sitter query whose matches are reported instead of
+	// the built-in symbols
+	Query string
+	// Log, when set, is told of files that are skipped or have syntax errors and of
+	// cache hits as the extraction goes
+	Log func(LogEvent)
+	// Progress, when set, is called before each matched file is processed and once
+	// all are, with the number of files done and the number to process
+	Progress func(done, total int)
+	// Jobs is the number of files extracted at once; 0 means one per CPU
+	Jobs int
+	// Page selects a page of the matched files to extract
+	Page Page
+	// Structured also sets the result's Structured document, whatever the format
+	Structured bool
+	// Format is the output format: FormatMarkdown (default), FormatJSON, FormatNDJSON,
+	// FormatCtags, FormatSARIF, FormatCSV, FormatTSV, FormatDOT, FormatTree,
+	// FormatCompact, FormatHTML or FormatQuickfix. The other formats list every selected
+	// symbol, so the outline display options do not apply to them.
+	Format string
+}
+
+// LineRange is an inclusive range of 1-based lines; a zero bound is open
+type LineRange struct {
+	Start uint32 `json:"start"`
+	End   uint32 `json:"end"`
+}
+
+// String formats the range as "12-15", or "40" for a single line
+func (r LineRange) String() string {
+	if r.Start == r.End {
+		return strconv.FormatUint(uint64(r.Start), 10)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// IsSet reports whether the range restricts any lines
+func (r LineRange) IsSet() bool {
+	return r.Start > 0 || r.End > 0
+}
+
+// Intersects reports whether a symbol overlaps the range
+func (r LineRange) Intersects(sym Symbol) bool {
+	if r.Start > 0 && sym.EndLine < r.Start {
+		return false
+	}
+	if r.End > 0 && sym.StartLine > r.End {
+		return false
+	}
+	return true
+}
+
+// String returns the name of the detail level, as accepted by ParseDetailLevel
+func (d DetailLevel) String() string {
+	switch d {
+	case Minimal:
+		return "minimal"
+	case Standard:
+		return "standard"
+	case Full:
+		return "full"
+	default:
+		return "unknown"
+	}
+}
+
 // ParseDetailLevel converts a string to DetailLevel
 func ParseDetailLevel(detail string) DetailLevel {
 	switch strings.ToLower(detail) {
@@ -32,3 +178,108 @@ func ParseDetailLevel(detail string) DetailLevel {
 		return Standard
 	}
 }
+
+// StringLiteral represents a notable string literal (SQL, URL, regex or template)
+type StringLiteral struct {
+	Kind      string
+	Value     string
+	Line      uint32
+	FilePath  string
+	Enclosing string
+}
+
+// Reference represents a usage of a symbol name found during impact analysis
+type Reference struct {
+	FilePath string
+	Line     uint32
+	Category string
+	Context  string
+}
+
+// Extraction statuses reported in an ExtractionResult
+const (
+	StatusOK        = "ok"
+	StatusNoFiles   = "no_files"
+	StatusNoSymbols = "no_symbols"
+	// StatusLimitExceeded is reported by the MCP server for extractions stopped by an ExtractLimits bound
+	StatusLimitExceeded = "limit_exceeded"
+)
+
+// ExtractLimits bounds the work of a single extraction; zero fields mean no limit
+type ExtractLimits struct {
+	// MaxFiles is the most files a pattern may match
+	MaxFiles int
+	// MaxBytes is the most file content that may be parsed in total
+	MaxBytes int64
+}
+
+// LimitError reports an extraction stopped by one of its ExtractLimits
+type LimitError struct {
+	// Limit names the exceeded limit, "max_files" or "max_bytes"
+	Limit  string
+	Max    int64
+	Actual int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("limit exceeded: %s is %d, got at least %d; narrow the pattern", e.Limit, e.Max, e.Actual)
+}
+
+// check returns a LimitError if files or bytes exceed the limits
+func (l ExtractLimits) check(files int, bytes int64) error {
+	if l.MaxFiles > 0 && files > l.MaxFiles {
+		return &LimitError{Limit: "max_files", Max: int64(l.MaxFiles), Actual: int64(files)}
+	}
+	if l.MaxBytes > 0 && bytes > l.MaxBytes {
+		return &LimitError{Limit: "max_bytes", Max: l.MaxBytes, Actual: bytes}
+	}
+	return nil
+}
+
+// ExtractionResult is the outcome of an extraction together with its formatted output
+type ExtractionResult struct {
+	// Status is StatusOK, StatusNoFiles or StatusNoSymbols
+	Status string `json:"status"`
+	// Files is the number of files matched by the pattern
+	Files int `json:"files"`
+	// Symbols is the number of symbols (or string literals, or references) in the output
+	Symbols int `json:"symbols"`
+	// Warnings describe files that were skipped and other partial failures
+	Warnings []string `json:"warnings,omitempty"`
+	// NextCursor selects the next page of files when the output is paginated and
+	// more files remain
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Timings holds per-file parse and query times when requested
+	Timings []FileTiming `json:"timings,omitempty"`
+	// Output is the formatted outline
+	Output string `json:"-"`
+	// Format is the format of Output when it is not a Markdown outline, in which
+	// case warnings are not appended to it
+	Format string `json:"-"`
+	// Structured is the json format's document of the selected symbols, set when
+	// ExtractOptions.Structured is
+	Structured *jsonOutline `json:"-"`
+	// log receives the files skipped while the result is built, from ExtractOptions.Log
+	log func(LogEvent)
+}
+
+// FileTiming is the time spent extracting symbols from a single file
+type FileTiming struct {
+	FilePath string        `json:"file"`
+	Parse    time.Duration `json:"parse_ns"`
+	Query    time.Duration `json:"query_ns"`
+	Symbols  int           `json:"symbols"`
+}
+
+// FileMetadata describes a source file whose symbols are listed, so consumers can
+// cache an outline and detect when it is stale
+type FileMetadata struct {
+	Language string `json:"language"`
+	// Package is the package or module the file declares, if any
+	Package string `json:"package,omitempty"`
+	Lines   int    `json:"lines"`
+	// Hash is the hex-encoded SHA-256 of the file content
+	Hash string `json:"hash"`
+	// ParseErrors are the lines Tree-sitter could not parse, where symbols may be missing
+	ParseErrors []LineRange `json:"parse_errors,omitempty"`
+}
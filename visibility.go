@@ -0,0 +1,213 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// isPublicSymbol reports whether a symbol is part of the public API of its file,
+// following the visibility conventions of its language. Declarations local to a
+// function body are never public.
+func isPublicSymbol(language string, node *sitter.Node, content []byte, name string) bool {
+	// Package and namespace declarations name the unit a file belongs to rather than an API member
+	switch node.Type() {
+	case "package_clause", "package_declaration", "namespace_declaration", "file_scoped_namespace_declaration", "namespace_definition":
+		return true
+	}
+
+	switch language {
+	case "go":
+		if hasAncestor(node, "function_declaration", "method_declaration", "func_literal") {
+			return false
+		}
+		r, _ := utf8.DecodeRuneInString(name)
+		return unicode.IsUpper(r)
+	case "java":
+		return isPublicJavaMember(node)
+	case "javascript", "typescript":
+		return isExportedJSMember(node, name)
+	case "rust":
+		return isPublicRustItem(node, content)
+	case "csharp":
+		return isPublicCSharpMember(node, content)
+	case "php":
+		return isPublicPHPMember(node, content)
+	case "elixir":
+		return isPublicElixirDefinition(node, content)
+	case "python":
+		if hasAncestor(node, "function_definition", "lambda") {
+			return false
+		}
+		// Dunder methods such as __init__ are public protocol methods
+		if strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__") {
+			return true
+		}
+		return !strings.HasPrefix(name, "_")
+	default:
+		return true
+	}
+}
+
+// hasAncestor reports whether any proper ancestor of node has one of the given types
+func hasAncestor(node *sitter.Node, types ...string) bool {
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		for _, t := range types {
+			if parent.Type() == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isPublicJavaMember reports whether a Java declaration is public. Members of
+// interfaces and annotation types are implicitly public.
+func isPublicJavaMember(node *sitter.Node) bool {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child.Type() != "modifiers" {
+			continue
+		}
+		for j := 0; j < int(child.ChildCount()); j++ {
+			if child.Child(j).Type() == "public" {
+				return true
+			}
+		}
+	}
+
+	if parent := node.Parent(); parent != nil {
+		switch parent.Type() {
+		case "interface_body", "annotation_type_body":
+			return true
+		}
+	}
+	return false
+}
+
+// isExportedJSMember reports whether a JavaScript/TypeScript declaration is reachable
+// from the module's exports: its top-level statement must be exported, and neither it
+// nor its enclosing members may be private.
+func isExportedJSMember(node *sitter.Node, name string) bool {
+	if strings.HasPrefix(name, "#") {
+		return false
+	}
+
+	for current := node; current != nil; current = current.Parent() {
+		switch current.Type() {
+		case "statement_block":
+			// Declarations inside function and method bodies are local
+			return false
+		case "method_definition", "public_field_definition", "method_signature", "property_signature":
+			if hasPrivateModifier(current) {
+				return false
+			}
+		}
+
+		parent := current.Parent()
+		if parent != nil && parent.Type() == "program" {
+			return current.Type() == "export_statement"
+		}
+	}
+	return false
+}
+
+// hasPrivateModifier reports whether a class member is marked private or protected
+func hasPrivateModifier(node *sitter.Node) bool {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child.Type() != "accessibility_modifier" {
+			continue
+		}
+		for j := 0; j < int(child.ChildCount()); j++ {
+			switch child.Child(j).Type() {
+			case "private", "protected":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isPublicRustItem reports whether a Rust item is exported from its crate: it must be
+// marked plain "pub" rather than pub(crate) or pub(super), or be a member of a trait
+// or trait implementation, whose visibility is that of the trait. Impl blocks carry
+// no visibility of their own, and macro_rules! macros are exported by #[macro_export].
+func isPublicRustItem(node *sitter.Node, content []byte) bool {
+	if hasAncestor(node, "function_item") {
+		return false
+	}
+
+	switch node.Type() {
+	case "impl_item":
+		return true
+	case "macro_definition":
+		for s := node.PrevSibling(); s != nil && s.Type() == "attribute_item"; s = s.PrevSibling() {
+			if strings.Contains(string(content[s.StartByte():s.EndByte()]), "macro_export") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "visibility_modifier" {
+			return string(content[child.StartByte():child.EndByte()]) == "pub"
+		}
+	}
+
+	if list := node.Parent(); list != nil && list.Type() == "declaration_list" {
+		if owner := list.Parent(); owner != nil {
+			return owner.Type() == "trait_item" || (owner.Type() == "impl_item" && owner.ChildByFieldName("trait") != nil)
+		}
+	}
+	return false
+}
+
+// isPublicCSharpMember reports whether a C# declaration is public. Interface members
+// are implicitly public.
+func isPublicCSharpMember(node *sitter.Node, content []byte) bool {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() == "modifier" && string(content[child.StartByte():child.EndByte()]) == "public" {
+			return true
+		}
+	}
+
+	if body := node.Parent(); body != nil {
+		if owner := body.Parent(); owner != nil && owner.Type() == "interface_declaration" {
+			return true
+		}
+	}
+	return false
+}
+
+// isPublicPHPMember reports whether a PHP declaration is public. Class members
+// without a visibility modifier are public, as are functions and classes.
+func isPublicPHPMember(node *sitter.Node, content []byte) bool {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "visibility_modifier" {
+			return string(content[child.StartByte():child.EndByte()]) == "public"
+		}
+	}
+	return true
+}
+
+// isPublicElixirDefinition reports whether an Elixir definition is public: private
+// definitions use the "p" forms defp, defmacrop and defguardp
+func isPublicElixirDefinition(node *sitter.Node, content []byte) bool {
+	if node.Type() != "call" {
+		return true
+	}
+	target := node.ChildByFieldName("target")
+	if target == nil {
+		return true
+	}
+	switch string(content[target.StartByte():target.EndByte()]) {
+	case "defp", "defmacrop", "defguardp":
+		return false
+	}
+	return true
+}
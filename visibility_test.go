@@ -0,0 +1,202 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsPublicSymbol(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		source   string
+		expected map[string]bool
+	}{
+		{
+			name:     "go",
+			fileName: "server.go",
+			source: `package server
+
+type Server struct {
+	Addr string
+}
+
+type handler func()
+
+func NewServer() *Server {
+	var local = 1
+	_ = local
+	return &Server{}
+}
+
+func (s *Server) Start() {}
+
+func (s *Server) listen() {}
+
+const MaxConns = 10
+
+var defaultAddr = ":8080"
+`,
+			expected: map[string]bool{
+				"Server":      true,
+				"handler":     false,
+				"NewServer":   true,
+				"local":       false,
+				"Start":       true,
+				"listen":      false,
+				"MaxConns":    true,
+				"defaultAddr": false,
+			},
+		},
+		{
+			name:     "typescript",
+			fileName: "client.ts",
+			source: `export class Client {
+    connect(): void {}
+    private retry(): void {}
+}
+
+class Helper {
+    run(): void {}
+}
+
+export function createClient(): Client {
+    const options = {};
+    return new Client();
+}
+
+function internal(): void {}
+
+export interface Options {
+    timeout: number;
+}
+`,
+			expected: map[string]bool{
+				"Client":       true,
+				"connect":      true,
+				"retry":        false,
+				"Helper":       false,
+				"run":          false,
+				"createClient": true,
+				"options":      false,
+				"internal":     false,
+				"Options":      true,
+			},
+		},
+		{
+			name:     "java",
+			fileName: "Service.java",
+			source: `public class Service {
+    public Service() {}
+
+    public void start() {}
+
+    private void stop() {}
+
+    void reset() {}
+}
+
+interface Callback {
+    void onEvent();
+}
+`,
+			expected: map[string]bool{
+				"Service":  true,
+				"start":    true,
+				"stop":     false,
+				"reset":    false,
+				"Callback": false,
+				"onEvent":  true,
+			},
+		},
+		{
+			name:     "python",
+			fileName: "models.py",
+			source: `class Model:
+    def __init__(self):
+        pass
+
+    def fit(self):
+        pass
+
+    def _prepare(self):
+        pass
+
+def load():
+    helper = 1
+    return helper
+
+def _cache():
+    pass
+
+_registry = {}
+`,
+			expected: map[string]bool{
+				"Model":     true,
+				"__init__":  true,
+				"fit":       true,
+				"_prepare":  false,
+				"load":      true,
+				"helper":    false,
+				"_cache":    false,
+				"_registry": false,
+			},
+		},
+	}
+
+	extractor := NewSymbolExtractor()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(testFile, []byte(tt.source), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			symbols, err := extractor.ExtractFromFile(testFile, Minimal)
+			if err != nil {
+				t.Fatalf("ExtractFromFile error = %v", err)
+			}
+
+			found := make(map[string]bool)
+			for _, sym := range symbols {
+				public, ok := tt.expected[sym.Name]
+				if !ok {
+					continue
+				}
+				found[sym.Name] = true
+				if sym.Public != public {
+					t.Errorf("%s %s: Public = %v, want %v", sym.Kind, sym.Name, sym.Public, public)
+				}
+			}
+
+			for name := range tt.expected {
+				if !found[name] {
+					t.Errorf("symbol %s not extracted", name)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractSymbols_PublicOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	source := "package lib\n\nfunc Exported() {}\n\nfunc unexported() {}\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "lib.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExtractSymbols(filepath.Join(tempDir, "*.go"), ExtractOptions{Detail: Minimal, PublicOnly: true})
+	if err != nil {
+		t.Fatalf("ExtractSymbols error = %v", err)
+	}
+
+	if !strings.Contains(result, "Exported") {
+		t.Errorf("expected exported function in output:\n%s", result)
+	}
+	if strings.Contains(result, "unexported") {
+		t.Errorf("expected unexported function to be filtered out:\n%s", result)
+	}
+}
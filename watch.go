@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the watched files must stay unchanged before the
+// outline is extracted again, so a checkout or an editor's burst of writes is one update
+const watchDebounce = 200 * time.Millisecond
+
+// watchPattern extracts the outline of a pattern, then extracts it again whenever
+// files below the pattern's base directories change, until ctx is done. emit
+// receives the first outline and every one that differs from the last; failed
+// extractions are reported to warn. The files' symbols are cached, so only the
+// changed files are parsed again.
+func watchPattern(ctx context.Context, pattern string, opts ExtractOptions, debounce time.Duration, emit func(output string), warn func(err error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch files: %w", err)
+	}
+	defer watcher.Close()
+
+	if opts.Cache == nil {
+		opts.Cache = NewSymbolCache(defaultCacheFiles)
+	}
+	dirs := watchedDirs(pattern)
+	for _, dir := range dirs {
+		addWatchedDirs(watcher, dir.path, dir.path, dir.recursive, opts.Discovery, warn)
+	}
+
+	var last string
+	extract := func() {
+		result, err := ExtractSymbolsContext(ctx, pattern, opts)
+		if err != nil {
+			if ctx.Err() == nil {
+				warn(err)
+			}
+			return
+		}
+		if result.Output != last {
+			last = result.Output
+			emit(result.Output)
+		}
+	}
+	extract()
+
+	// pending fires once the files have stayed unchanged for debounce after an event
+	pending := time.NewTimer(debounce)
+	pending.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			// New directories of a recursive pattern are watched as they appear
+			if event.Op.Has(fsnotify.Create) {
+				for _, dir := range dirs {
+					if dir.recursive && withinDir(event.Name, dir.path) {
+						if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+							addWatchedDirs(watcher, event.Name, dir.path, true, opts.Discovery, warn)
+						}
+					}
+				}
+			}
+			pending.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			warn(err)
+		case <-pending.C:
+			extract()
+		}
+	}
+}
+
+// watchedDir is a directory whose changes can change the files a pattern matches
+type watchedDir struct {
+	path string
+	// recursive is set for patterns with **, whose matches can be at any depth
+	recursive bool
+}
+
+// watchedDirs returns the base directory of each pattern of a list
+func watchedDirs(pattern string) []watchedDir {
+	var dirs []watchedDir
+	for _, p := range SplitPatterns(pattern) {
+		dirs = append(dirs, watchedDir{path: PatternBaseDir(p), recursive: strings.Contains(p, "**")})
+	}
+	return dirs
+}
+
+// addWatchedDirs watches a directory, and when recursive the directories below it
+// that discovery doesn't skip. Directories that can't be watched, such as ones
+// beyond the system's limit on watches, are reported to warn and left out.
+func addWatchedDirs(watcher *fsnotify.Watcher, dir, baseDir string, recursive bool, opts DiscoveryOptions, warn func(err error)) {
+	if !recursive {
+		if err := watcher.Add(dir); err != nil {
+			warn(fmt.Errorf("failed to watch %s: %w", dir, err))
+		}
+		return
+	}
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if skipDirectory(path, baseDir, opts) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			warn(fmt.Errorf("failed to watch %s: %w", path, err))
+		}
+		return nil
+	})
+}
+
+// withinDir reports whether path is dir or below it
+func withinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchPattern(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc Run() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	outputs := make(chan string, 10)
+	done := make(chan error)
+	go func() {
+		opts := ExtractOptions{Detail: Standard, Format: FormatCompact}
+		done <- watchPattern(ctx, filepath.Join(dir, "**/*.go"), opts, 20*time.Millisecond, func(output string) { outputs <- output }, func(err error) { t.Log(err) })
+	}()
+
+	next := func() string {
+		t.Helper()
+		select {
+		case output := <-outputs:
+			return output
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected another outline")
+			return ""
+		}
+	}
+
+	if output := next(); !strings.Contains(output, "func Run()") {
+		t.Fatalf("first outline = %q, want Run", output)
+	}
+
+	// Files in directories created after the watch started are followed too
+	if err := os.MkdirAll(filepath.Join(dir, "pkg", "util"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "pkg", "util", "util.go"), []byte("package util\n\nfunc Help() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if output := next(); !strings.Contains(output, "func Help()") || !strings.Contains(output, "func Run()") {
+		t.Fatalf("outline after adding a file = %q, want Run and Help", output)
+	}
+
+	// A write that leaves the outline as it was isn't emitted
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc Run() { println() }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case output := <-outputs:
+		t.Errorf("Expected no outline for an unchanged one, got %q", output)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("watchPattern = %v, want nil once cancelled", err)
+	}
+}
+
+func TestWatchedDirs(t *testing.T) {
+	dirs := watchedDirs("/repo/cmd/*.go,/repo/web/**/*.ts")
+	if len(dirs) != 2 || dirs[0] != (watchedDir{"/repo/cmd", false}) || dirs[1] != (watchedDir{"/repo/web", true}) {
+		t.Errorf("watchedDirs = %+v", dirs)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// yamlSymbolName names a YAML symbol from its @name capture: a Kubernetes manifest
+// is named "kind/name" after its metadata.name, and a top-level key by the key
+// itself. Keys of manifests are left unnamed, so only the manifest is listed.
+func yamlSymbolName(symbolType string, node *sitter.Node, content []byte) string {
+	pair := node.Parent()
+	if pair == nil || pair.Parent() == nil {
+		return ""
+	}
+	mapping := pair.Parent()
+	manifest := isKubernetesManifest(mapping, content)
+
+	switch symbolType {
+	case "manifests":
+		if !manifest {
+			return ""
+		}
+		return yamlManifestName(mapping, content)
+	case "keys":
+		if manifest {
+			return ""
+		}
+		return yamlScalar(node, content)
+	}
+	return yamlScalar(node, content)
+}
+
+// yamlSignature returns the API version and name of a manifest, such as
+// "apps/v1 Deployment/web", or the first line of a top-level key
+func yamlSignature(node *sitter.Node, content []byte) string {
+	if node.Type() == "document" {
+		mapping := yamlMapping(node)
+		if !isKubernetesManifest(mapping, content) {
+			return ""
+		}
+		return yamlScalar(yamlMappingValue(mapping, "apiVersion", content), content) + " " + yamlManifestName(mapping, content)
+	}
+
+	line, _, _ := strings.Cut(string(content[node.StartByte():node.EndByte()]), "\n")
+	return strings.TrimSpace(line)
+}
+
+// yamlManifestName returns the kind and metadata.name of a manifest, such as
+// "Deployment/web", or only its kind when it has no name
+func yamlManifestName(mapping *sitter.Node, content []byte) string {
+	kind := yamlScalar(yamlMappingValue(mapping, "kind", content), content)
+	if name := yamlMappingValue(yamlMappingValue(mapping, "metadata", content), "name", content); name != nil {
+		return kind + "/" + yamlScalar(name, content)
+	}
+	return kind
+}
+
+// isKubernetesManifest reports whether a mapping declares both apiVersion and kind
+func isKubernetesManifest(mapping *sitter.Node, content []byte) bool {
+	return yamlMappingValue(mapping, "apiVersion", content) != nil && yamlMappingValue(mapping, "kind", content) != nil
+}
+
+// yamlMapping returns the block mapping of a document or block node, or nil
+func yamlMapping(node *sitter.Node) *sitter.Node {
+	for node != nil && node.Type() != "block_mapping" {
+		switch node.Type() {
+		case "document", "block_node":
+			node = node.NamedChild(0)
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// yamlMappingValue returns the value of key in a mapping, or nil. The mapping may
+// also be given as the block node or document holding it.
+func yamlMappingValue(mapping *sitter.Node, key string, content []byte) *sitter.Node {
+	mapping = yamlMapping(mapping)
+	if mapping == nil {
+		return nil
+	}
+	for i := 0; i < int(mapping.NamedChildCount()); i++ {
+		pair := mapping.NamedChild(i)
+		if k := pair.ChildByFieldName("key"); k != nil && yamlScalar(k, content) == key {
+			return pair.ChildByFieldName("value")
+		}
+	}
+	return nil
+}
+
+// yamlScalar returns the text of a scalar without its quotes
+func yamlScalar(node *sitter.Node, content []byte) string {
+	text := strings.TrimSpace(string(content[node.StartByte():node.EndByte()]))
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0] {
+		text = text[1 : len(text)-1]
+	}
+	return text
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestYAMLSymbolExtraction(t *testing.T) {
+	extractor := NewSymbolExtractor()
+	defer extractor.Close()
+
+	symbols, err := extractor.ExtractFromFile("testdata/yaml_basic.yaml.txt", Standard)
+	if err != nil {
+		t.Fatalf("Failed to extract symbols: %v", err)
+	}
+
+	expected := map[string]string{
+		"resource Deployment/web":  "apps/v1 Deployment/web",
+		"resource Service/web-svc": "v1 Service/web-svc",
+		"key version":              "version: \"3\"",
+		"key services":             "services:",
+		"key volumes":              "volumes: {}",
+	}
+
+	found := make(map[string]string)
+	for _, sym := range symbols {
+		found[sym.Kind+" "+sym.Name] = sym.Signature
+	}
+	for key, signature := range expected {
+		if got, ok := found[key]; !ok {
+			t.Errorf("symbol %s not extracted", key)
+		} else if got != signature {
+			t.Errorf("%s: Signature = %q, want %q", key, got, signature)
+		}
+	}
+
+	// Keys of manifests and nested keys are not listed on their own
+	for _, key := range []string{"key kind", "key metadata", "key spec", "key app"} {
+		if _, ok := found[key]; ok {
+			t.Errorf("unexpected symbol %s", key)
+		}
+	}
+}